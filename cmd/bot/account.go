@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/accounts"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// sendLinkStatus 显示账户绑定状态，未绑定时等待用户发送 API Token
+func sendLinkStatus(req *bot_pkg.Request, accountService *services.AccountService) {
+	if link, ok := accountService.GetLink(req.UserID); ok {
+		menu := bot_pkg.CreateLinkMenu(true)
+		req.ReplyMarkdown(fmt.Sprintf("🔗 已绑定 Audiobookshelf 账户 *%s*\n\n"+
+			"发送 `/link <API Token>` 或 `/login` 可以绑定其他账户", escapeMarkdown(link.Username)), &menu)
+		return
+	}
+
+	conversations.Set(req.ChatID, bot_pkg.StateAwaitingToken, "")
+	menu := bot_pkg.CreateLinkMenu(false)
+	req.Reply("🔗 绑定 Audiobookshelf 账户后，机器人会以您自己的账户查询统计和播放进度。\n\n"+
+		"请发送您的 API Token（在 Audiobookshelf 的「设置 → 用户」中查看），"+
+		"或发送 /login 使用用户名和密码登录。发送 /cancel 取消。", &menu)
+}
+
+// linkWithToken 使用用户发送的 API Token 绑定账户，包含 Token 的消息会被删除
+func linkWithToken(req *bot_pkg.Request, token string, accountService *services.AccountService) {
+	req.DeleteIncomingMessage()
+	req.Reply("🔗 正在验证 Token，请稍候...", nil)
+
+	link, err := accountService.LinkWithToken(req.Context(), req.UserID, token)
+	if err != nil {
+		log.Printf("Telegram 用户 %d 绑定账户失败: %v", req.UserID, err)
+		text := "❌ 绑定失败: " + services.DescribeError(err)
+		if api.IsUnauthorized(err) {
+			text = "❌ 绑定失败: Audiobookshelf 不接受这个 Token，请检查后重新发送 /link"
+		}
+		req.Reply(text, req.MainMenu())
+		return
+	}
+
+	sendLinked(req, link)
+}
+
+// promptForLogin 开始用户名密码登录，命令参数中可以直接带上用户名和密码
+func promptForLogin(req *bot_pkg.Request, accountService *services.AccountService) {
+	username, password, _ := strings.Cut(req.Args, " ")
+	username = strings.TrimSpace(username)
+	password = strings.TrimSpace(password)
+
+	switch {
+	case username != "" && password != "":
+		loginAndLink(req, username, password, accountService)
+	case username != "":
+		promptForPassword(req, username)
+	default:
+		conversations.Set(req.ChatID, bot_pkg.StateAwaitingLoginUsername, "")
+		req.Reply("👤 请输入您的 Audiobookshelf 用户名，或发送 /cancel 取消：", nil)
+	}
+}
+
+// promptForPassword 提示用户输入密码
+func promptForPassword(req *bot_pkg.Request, username string) {
+	conversations.Set(req.ChatID, bot_pkg.StateAwaitingPassword, username)
+	req.Reply(fmt.Sprintf("🔑 请输入用户 %s 的密码，或发送 /cancel 取消。\n密码消息会在登录后立即删除，机器人不会保存密码。", username), nil)
+}
+
+// loginAndLink 使用用户名和密码登录并绑定账户，包含密码的消息会被删除
+func loginAndLink(req *bot_pkg.Request, username, password string, accountService *services.AccountService) {
+	req.DeleteIncomingMessage()
+	req.Reply("🔗 正在登录 Audiobookshelf，请稍候...", nil)
+
+	link, err := accountService.LinkWithPassword(req.Context(), req.UserID, username, password)
+	if err != nil {
+		log.Printf("Telegram 用户 %d 登录 Audiobookshelf 失败: %v", req.UserID, err)
+		text := "❌ 登录失败: " + services.DescribeError(err)
+		if api.IsUnauthorized(err) {
+			text = "❌ 登录失败: 用户名或密码错误，请重新发送 /login"
+		}
+		req.Reply(text, req.MainMenu())
+		return
+	}
+
+	sendLinked(req, link)
+}
+
+// sendLinked 提示绑定成功
+func sendLinked(req *bot_pkg.Request, link accounts.Link) {
+	menu := bot_pkg.CreateLinkMenu(true)
+	req.ReplyMarkdown(fmt.Sprintf("✅ 已绑定 Audiobookshelf 账户 *%s*\n\n之后的操作都将以该账户的身份执行", escapeMarkdown(link.Username)), &menu)
+}
+
+// unlinkAccount 解除账户绑定
+func unlinkAccount(req *bot_pkg.Request, accountService *services.AccountService) {
+	unlinked, err := accountService.Unlink(req.UserID)
+	if err != nil {
+		log.Printf("Telegram 用户 %d 解除绑定失败: %v", req.UserID, err)
+		req.Reply("❌ "+err.Error(), req.MainMenu())
+		return
+	}
+
+	text := "当前没有绑定 Audiobookshelf 账户"
+	if unlinked {
+		text = "✅ 已解除 Audiobookshelf 账户绑定"
+	}
+	req.Reply(text, req.MainMenu())
+}
+
+// hasOwnAccount 判断用户是否以自己的账户执行操作
+// 未绑定时默认 token 对应的是机器人所有者的账户，只有管理员可以使用
+func hasOwnAccount(req *bot_pkg.Request, accountService *services.AccountService) bool {
+	_, linked := accountService.GetLink(req.UserID)
+	return linked || req.Role >= bot_pkg.RoleAdmin
+}
+
+// sendLinkRequired 提示需要先绑定账户，purpose 说明需要绑定才能进行的操作
+func sendLinkRequired(req *bot_pkg.Request, purpose string) {
+	menu := bot_pkg.CreateLinkMenu(false)
+	req.Reply("🔗 请先发送 /link 或 /login 绑定您的 Audiobookshelf 账户，才能"+purpose, &menu)
+}
+
+// isSensitiveMessage 判断消息是否可能包含密码或 Token，这类消息的内容不写入日志
+func isSensitiveMessage(message *tgbotapi.Message) bool {
+	if message.IsCommand() {
+		command := strings.ToLower(message.Command())
+		return (command == "link" || command == "login") && message.CommandArguments() != ""
+	}
+
+	switch conversations.Get(message.Chat.ID).State {
+	case bot_pkg.StateAwaitingToken, bot_pkg.StateAwaitingPassword,
+		bot_pkg.StateAwaitingNewUserPassword, bot_pkg.StateAwaitingResetPassword:
+		return true
+	}
+	return false
+}
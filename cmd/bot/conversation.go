@@ -0,0 +1,104 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// conversationTimeout 等待用户输入的最长时间，超时后需要重新操作
+const conversationTimeout = 5 * time.Minute
+
+// conversations 按聊天保存多步操作的对话状态
+var conversations = bot_pkg.NewConversationStore(conversationTimeout)
+
+// handleTextMessage 处理非命令的文本消息，根据对话状态决定如何处理
+func handleTextMessage(req *bot_pkg.Request, accountService *services.AccountService) {
+	serverService := accountService.ServiceFor(req.UserID)
+	conversation := conversations.Get(req.ChatID)
+	log.Printf("收到文本消息，对话状态: %s", conversation.State)
+
+	switch conversation.State {
+	case bot_pkg.StateAwaitingSearchTerm:
+		if req.Args == "" {
+			req.Reply("请输入文字形式的搜索关键词，或发送 /cancel 取消", nil)
+			return
+		}
+		conversations.Clear(req.ChatID)
+		performBookSearch(req, req.Args, serverService)
+	case bot_pkg.StateAwaitingUsername:
+		if req.Args == "" {
+			req.Reply("请输入用户名，或发送 /cancel 取消", nil)
+			return
+		}
+		conversations.Clear(req.ChatID)
+		sendUserStats(req, req.Args, serverService)
+	case bot_pkg.StateAwaitingToken:
+		if req.Args == "" {
+			req.Reply("请发送文字形式的 API Token，或发送 /cancel 取消", nil)
+			return
+		}
+		conversations.Clear(req.ChatID)
+		linkWithToken(req, req.Args, accountService)
+	case bot_pkg.StateAwaitingLoginUsername:
+		if req.Args == "" {
+			req.Reply("请输入用户名，或发送 /cancel 取消", nil)
+			return
+		}
+		promptForPassword(req, req.Args)
+	case bot_pkg.StateAwaitingPassword:
+		if req.Args == "" {
+			req.Reply("请输入密码，或发送 /cancel 取消", nil)
+			return
+		}
+		conversations.Clear(req.ChatID)
+		loginAndLink(req, conversation.Data, req.Args, accountService)
+	case bot_pkg.StateAwaitingNewUsername:
+		username := strings.TrimSpace(req.Args)
+		if username == "" {
+			req.Reply("请输入新用户的用户名，或发送 /cancel 取消", nil)
+			return
+		}
+		promptForNewUserPassword(req, username)
+	case bot_pkg.StateAwaitingNewUserPassword:
+		if req.Args == "" {
+			req.Reply("请输入新用户的密码，或发送 /cancel 取消", nil)
+			return
+		}
+		conversations.Clear(req.ChatID)
+		createUser(req, conversation.Data, req.Args, serverService)
+	case bot_pkg.StateAwaitingResetPassword:
+		if req.Args == "" {
+			req.Reply("请输入新密码，或发送 /cancel 取消", nil)
+			return
+		}
+		conversations.Clear(req.ChatID)
+		resetUserPassword(req, conversation.Data, req.Args, serverService)
+	case bot_pkg.StateAwaitingProgressTime:
+		if req.Args == "" {
+			req.Reply("请输入播放位置，例如 1:02:03，或发送 /cancel 取消", nil)
+			return
+		}
+		seekToTimestamp(req, conversation.Data, req.Args, serverService)
+	default:
+		req.Reply("🤔 没有进行中的操作。请使用下方的菜单，或发送 /search 搜索图书", req.MainMenu())
+	}
+}
+
+// cancelConversation 取消聊天中进行中的多步操作
+func cancelConversation(req *bot_pkg.Request) {
+	text := "当前没有进行中的操作"
+	if conversations.Clear(req.ChatID) {
+		text = "✅ 已取消当前操作"
+	}
+	req.Reply(text, req.MainMenu())
+}
+
+// promptForUsername 提示用户输入要查看统计信息的用户名
+func promptForUsername(req *bot_pkg.Request) {
+	conversations.Set(req.ChatID, bot_pkg.StateAwaitingUsername, "")
+	req.Reply("👤 请输入要查看统计信息的用户名，或发送 /cancel 取消：", nil)
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+const (
+	// maxUploadSize Bot API 上传文件的大小上限为 50 MB，超过的 MP3 文件拆分为多个部分发送，并为 multipart 编码留出余量
+	maxUploadSize = 49 * 1000 * 1000
+	// frameSyncWindow 拆分点之后查找 MP3 帧头的范围，远大于最长的 MP3 帧
+	frameSyncWindow = 64 * 1024
+)
+
+// promptForDownload 显示图书音频文件的数量和大小，确认后发送到聊天中
+func promptForDownload(req *bot_pkg.Request, serverService *services.ServerService) {
+	itemID := req.Data.Arg(0)
+	req.Reply("📥 正在准备下载，请稍候...", nil)
+
+	download, err := serverService.PrepareDownload(req.Context(), itemID)
+	if err != nil {
+		replyDownloadError(req, itemID, err)
+		return
+	}
+
+	plans, err := planDownload(download)
+	if err != nil {
+		menu := bot_pkg.CreateItemResultMenu(itemID)
+		req.Reply("⛔ 无法发送到聊天中: "+err.Error(), &menu)
+		return
+	}
+
+	var duration float64
+	parts := 0
+	for i, file := range download.Files {
+		duration += file.Duration
+		parts += len(plans[i])
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📥 下载《%s》\n\n", download.Item.Title()))
+	sb.WriteString(fmt.Sprintf("🎵 音频文件: %d 个\n", len(download.Files)))
+	sb.WriteString(fmt.Sprintf("💾 总大小: %s\n", services.FormatBytes(download.Size())))
+	if duration > 0 {
+		sb.WriteString(fmt.Sprintf("⏱ 总时长: %s\n", formatSeconds(duration)))
+	}
+	if parts > len(download.Files) {
+		sb.WriteString(fmt.Sprintf("\n超过 50 MB 的 MP3 文件会被拆分，共需发送 %d 条消息。", parts))
+	}
+	sb.WriteString("\n确定要发送到聊天中吗？")
+
+	menu := bot_pkg.CreateConfirmMenu(
+		bot_pkg.EncodeCallback(bot_pkg.ActionDownloadConfirm, itemID),
+		bot_pkg.ItemDetailCallback(itemID),
+	)
+	req.Reply(sb.String(), &menu)
+}
+
+// sendItemAudio 逐个发送图书的音频文件，发送期间在状态消息中显示进度
+func sendItemAudio(req *bot_pkg.Request, serverService *services.ServerService) {
+	itemID := req.Data.Arg(0)
+	// 确认之后权限可能已经变化，重新检查
+	download, err := serverService.PrepareDownload(req.Context(), itemID)
+	if err != nil {
+		replyDownloadError(req, itemID, err)
+		return
+	}
+
+	menu := bot_pkg.CreateItemResultMenu(itemID)
+	plans, err := planDownload(download)
+	if err != nil {
+		req.Reply("⛔ 无法发送到聊天中: "+err.Error(), &menu)
+		return
+	}
+
+	title := download.Item.Title()
+	log.Printf("用户 %d 开始下载条目 %s 的 %d 个音频文件", req.UserID, itemID, len(download.Files))
+	for i, file := range download.Files {
+		req.Reply(fmt.Sprintf("📥 正在发送《%s》第 %d/%d 个文件: %s (%s)", title, i+1, len(download.Files), file.Metadata.Filename, services.FormatBytes(file.Metadata.Size)), nil)
+		if err := sendAudioFile(req, serverService, download, i, plans[i]); err != nil {
+			log.Printf("发送条目 %s 的音频文件 %s 失败: %v", itemID, file.Metadata.Filename, err)
+			req.Reply(fmt.Sprintf("❌ 发送第 %d/%d 个文件失败: %s", i+1, len(download.Files), services.DescribeError(err)), &menu)
+			return
+		}
+	}
+
+	// 状态消息在音频上方，删除后在最下方发送结果，方便继续操作
+	req.DeleteMessage()
+	req.Reply(fmt.Sprintf("✅ 《%s》的 %d 个音频文件已发送完毕", title, len(download.Files)), &menu)
+}
+
+// sendAudioFile 发送第 index 个音频文件，parts 为 planUpload 得到的各个部分
+// 只有 MP3 会被拆分，MP3 由独立的帧组成，拆分点向后对齐到帧头，拆分后的每个部分仍可单独播放
+func sendAudioFile(req *bot_pkg.Request, serverService *services.ServerService, download *services.ItemDownload, index int, parts []uploadPart) error {
+	file := download.Files[index]
+	reader, err := serverService.OpenAudioFile(req.Context(), download.Item.ID, file)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	name := file.Metadata.Filename
+	if name == "" {
+		name = fmt.Sprintf("%02d%s", index+1, file.Metadata.Ext)
+	}
+	title := download.Item.Title()
+	if len(download.Files) > 1 {
+		title = fmt.Sprintf("%s (%d/%d)", title, index+1, len(download.Files))
+	}
+	performer := download.Item.AuthorNames()
+
+	stream := bufio.NewReaderSize(reader, frameSyncWindow)
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	// shift 上一部分为对齐帧头多读取的字节数，从这一部分中扣除
+	var shift int64
+	for i, part := range parts {
+		// 文件比服务器报告的小时，剩下的部分没有数据，不能发送空文件
+		if _, err := stream.Peek(1); err != nil {
+			if err == io.EOF {
+				err = errFileTruncated
+			}
+			return fmt.Errorf("读取第 %d/%d 部分失败: %w", i+1, len(parts), err)
+		}
+		size := part.Size - shift
+		var source io.Reader = io.LimitReader(stream, size)
+		var aligned *frameAlignedReader
+		// 下一部分不足以容纳对齐的偏移时不再对齐，文件末尾剩下的少量数据单独发送
+		if i < len(parts)-1 && parts[i+1].Size > frameSyncWindow {
+			aligned = &frameAlignedReader{stream: stream, head: source}
+			source = aligned
+		}
+		partReader := &countingReader{reader: source}
+
+		partName, partTitle := name, title
+		if len(parts) > 1 {
+			partName = fmt.Sprintf("%s.part%d%s", base, i+1, ext)
+			partTitle = fmt.Sprintf("%s 第 %d/%d 部分", title, i+1, len(parts))
+		}
+		audio := tgbotapi.NewAudio(req.ChatID, tgbotapi.FileReader{Name: partName, Reader: partReader})
+		audio.Title = partTitle
+		audio.Performer = performer
+		audio.Duration = int(part.Duration)
+		if _, err := req.Bot.Send(audio); err != nil {
+			return fmt.Errorf("发送第 %d/%d 部分失败: %w", i+1, len(parts), err)
+		}
+		shift = 0
+		if aligned != nil {
+			shift = aligned.extra
+			size += shift
+		}
+		if partReader.n != size {
+			return fmt.Errorf("第 %d/%d 部分只读取到 %s: %w", i+1, len(parts), services.FormatBytes(partReader.n), errFileTruncated)
+		}
+	}
+
+	// 文件比服务器报告的大时，超出的部分没有被发送
+	if _, err := stream.Peek(1); err != io.EOF {
+		if err == nil {
+			err = errFileTooLong
+		}
+		return err
+	}
+	return nil
+}
+
+// 音频文件的实际大小与服务器报告的不一致
+var (
+	errFileTruncated = errors.New("文件比服务器报告的大小短，可能已被修改，请重新扫描后再试")
+	errFileTooLong   = errors.New("文件比服务器报告的大小长，可能已被修改，请重新扫描后再试")
+	// errUnknownSize 服务器没有报告文件大小，无法确定是否需要拆分
+	errUnknownSize = errors.New("服务器没有提供文件大小，请重新扫描后再试")
+	// errTooLargeToSplit 文件超过上传上限且不能拆分，拆分后的部分无法单独播放
+	errTooLargeToSplit = errors.New("文件超过 50 MB 且不是 MP3 格式，拆分后无法播放，请使用 Audiobookshelf 客户端或网页下载")
+)
+
+// uploadPart 上传时拆分出的一个部分
+type uploadPart struct {
+	// Size 该部分的字节数
+	Size int64
+	// Duration 按大小估算的时长（秒），固定码率的文件除对齐帧头产生的偏差外是准确的
+	Duration float64
+}
+
+// planUpload 将大小为 size 字节、时长为 duration 秒的文件按每部分最多 limit 字节拆分
+// 不超过 limit 的文件只有一个部分；大小未知时返回 errUnknownSize，
+// 超过 limit 但不能拆分（splittable 为 false）时返回 errTooLargeToSplit
+func planUpload(size int64, duration float64, limit int64, splittable bool) ([]uploadPart, error) {
+	if size <= 0 {
+		return nil, errUnknownSize
+	}
+	if size > limit && !splittable {
+		return nil, errTooLargeToSplit
+	}
+
+	count := (size + limit - 1) / limit
+	parts := make([]uploadPart, 0, count)
+	for offset := int64(0); offset < size; offset += limit {
+		partSize := limit
+		if remaining := size - offset; remaining < partSize {
+			partSize = remaining
+		}
+		parts = append(parts, uploadPart{Size: partSize, Duration: duration * float64(partSize) / float64(size)})
+	}
+	return parts, nil
+}
+
+// planDownload 为图书的每个音频文件拆分上传的部分，返回的切片与 download.Files 一一对应
+func planDownload(download *services.ItemDownload) ([][]uploadPart, error) {
+	plans := make([][]uploadPart, len(download.Files))
+	for i, file := range download.Files {
+		limit := int64(maxUploadSize)
+		if file.Metadata.Size > limit {
+			// 每个部分会向后延伸到下一个帧头，为此预留空间
+			limit -= frameSyncWindow
+		}
+		parts, err := planUpload(file.Metadata.Size, file.Duration, limit, isMP3(file))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Metadata.Filename, err)
+		}
+		plans[i] = parts
+	}
+	return plans, nil
+}
+
+// countingReader 记录已读取的字节数
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// frameAlignedReader 读取拆分出的一个部分：读完 head 后继续读取到下一个 MP3 帧头之前，
+// 使下一部分从完整的帧开始；在 frameSyncWindow 内找不到帧头时在原位置拆分
+type frameAlignedReader struct {
+	stream *bufio.Reader
+	head   io.Reader
+	tail   io.Reader
+	// extra 为对齐帧头在 head 之后多读取的字节数
+	extra int64
+}
+
+func (r *frameAlignedReader) Read(p []byte) (int, error) {
+	if r.tail == nil {
+		n, err := r.head.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		// 读取出错时 buf 不完整，错误会在之后读取 stream 时返回
+		buf, _ := r.stream.Peek(frameSyncWindow)
+		if offset := frameSyncOffset(buf); offset > 0 {
+			r.extra = int64(offset)
+		}
+		r.tail = io.LimitReader(r.stream, r.extra)
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return r.tail.Read(p)
+}
+
+// frameSyncOffset 返回 buf 中第一个有效 MP3 帧头的位置，没有时返回 -1
+// 帧头以 11 位全 1 的同步字开始，版本、层、码率和采样率不能是保留值
+func frameSyncOffset(buf []byte) int {
+	for i := 0; i+2 < len(buf); i++ {
+		if buf[i] != 0xFF || buf[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+		version, layer := buf[i+1]>>3&0x03, buf[i+1]>>1&0x03
+		bitrate, sampleRate := buf[i+2]>>4, buf[i+2]>>2&0x03
+		if version != 0x01 && layer != 0x00 && bitrate != 0x0F && sampleRate != 0x03 {
+			return i
+		}
+	}
+	return -1
+}
+
+// isMP3 判断音频文件是否为 MP3 格式
+func isMP3(file models.AudioFile) bool {
+	return strings.EqualFold(file.Metadata.Ext, ".mp3") || file.MimeType == "audio/mpeg"
+}
+
+// replyDownloadError 回复无法下载的原因
+func replyDownloadError(req *bot_pkg.Request, itemID string, err error) {
+	menu := bot_pkg.CreateItemResultMenu(itemID)
+	if errors.Is(err, services.ErrDownloadNotAllowed) || errors.Is(err, services.ErrNothingToDownload) {
+		req.Reply("⛔ "+err.Error(), &menu)
+		return
+	}
+	req.Reply("❌ 准备下载失败: "+services.DescribeError(err), &menu)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestPlanUpload(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int64
+		duration  float64
+		limit     int64
+		mp3       bool
+		wantSizes []int64
+		wantErr   error
+	}{
+		{"不需要拆分", 80, 60, 100, true, []int64{80}, nil},
+		{"恰好等于上限", 100, 60, 100, true, []int64{100}, nil},
+		{"最后一部分较小", 250, 100, 100, true, []int64{100, 100, 50}, nil},
+		{"恰好整除", 300, 90, 100, true, []int64{100, 100, 100}, nil},
+		{"比上限多一个字节", 101, 101, 100, true, []int64{100, 1}, nil},
+		{"大小未知", 0, 60, 100, true, nil, errUnknownSize},
+		{"大小错误", -1, 60, 100, true, nil, errUnknownSize},
+		{"不能拆分的小文件", 80, 60, 100, false, []int64{80}, nil},
+		{"不能拆分的大文件", 250, 100, 100, false, nil, errTooLargeToSplit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := planUpload(tt.size, tt.duration, tt.limit, tt.mp3)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("期望错误为 %v，实际为 %v", tt.wantErr, err)
+			}
+			if len(parts) != len(tt.wantSizes) {
+				t.Fatalf("期望 %d 个部分，实际为 %d 个", len(tt.wantSizes), len(parts))
+			}
+
+			var duration float64
+			for i, part := range parts {
+				if part.Size != tt.wantSizes[i] {
+					t.Errorf("第 %d 部分期望 %d 字节，实际为 %d 字节", i+1, tt.wantSizes[i], part.Size)
+				}
+				// 时长按大小比例估算
+				if want := tt.duration * float64(part.Size) / float64(tt.size); math.Abs(part.Duration-want) > 1e-9 {
+					t.Errorf("第 %d 部分期望时长 %v 秒，实际为 %v 秒", i+1, want, part.Duration)
+				}
+				duration += part.Duration
+			}
+			if len(parts) > 0 && math.Abs(duration-tt.duration) > 1e-9 {
+				t.Errorf("各部分时长之和期望为 %v 秒，实际为 %v 秒", tt.duration, duration)
+			}
+		})
+	}
+}
+
+func TestFrameSyncOffset(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+		want int
+	}{
+		{"MPEG-1 Layer III 帧头", []byte{0x00, 0x12, 0xFF, 0xFB, 0x90, 0x64}, 2},
+		{"从帧头开始", []byte{0xFF, 0xFB, 0x90, 0x64}, 0},
+		{"保留的版本", []byte{0xFF, 0xEB, 0x90, 0xFF, 0xFB, 0x90}, 3},
+		{"无效的码率", []byte{0xFF, 0xFB, 0xF0, 0xFF, 0xFB, 0x90}, 3},
+		{"保留的采样率", []byte{0xFF, 0xFB, 0x9C, 0xFF, 0xFB, 0x90}, 3},
+		{"同步字不完整", []byte{0xFF, 0x1B, 0x90, 0x00}, -1},
+		{"帧头被截断", []byte{0x00, 0xFF, 0xFB}, -1},
+		{"空数据", nil, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := frameSyncOffset(tt.buf); got != tt.want {
+				t.Errorf("期望帧头位置为 %d，实际为 %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFrameAlignedReader(t *testing.T) {
+	header := []byte{0xFF, 0xFB, 0x90, 0x64}
+	data := append(bytes.Repeat([]byte{0x11}, 10), header...)
+	data = append(data, bytes.Repeat([]byte{0x22}, 20)...)
+
+	tests := []struct {
+		name      string
+		head      int64
+		wantExtra int64
+	}{
+		{"延伸到下一个帧头", 6, 4},
+		{"恰好在帧头处拆分", 10, 0},
+		{"找不到帧头时在原位置拆分", 16, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := bufio.NewReaderSize(bytes.NewReader(data), frameSyncWindow)
+			reader := &frameAlignedReader{stream: stream, head: io.LimitReader(stream, tt.head)}
+			part, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("读取失败: %v", err)
+			}
+			if reader.extra != tt.wantExtra || int64(len(part)) != tt.head+tt.wantExtra {
+				t.Errorf("期望多读取 %d 字节，实际多读取 %d 字节，共读取 %d 字节", tt.wantExtra, reader.extra, len(part))
+			}
+			rest, _ := io.ReadAll(stream)
+			if !bytes.Equal(append(part, rest...), data) {
+				t.Error("拆分后的各部分拼接起来应与原文件相同")
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// Telegram 对消息长度的限制
+const (
+	maxCaptionLength = 1024
+	maxMessageLength = 4096
+)
+
+// sendItemDetail 发送条目详情卡片：有封面时以图片形式发送，否则发送文本
+// searchID 不为空表示从该次搜索的结果中打开，详情页可以返回搜索结果
+// showProgress 为 false 时不展示个人进度，未绑定账户的用户看到的进度属于共享 Token 的所有者
+func sendItemDetail(req *bot_pkg.Request, itemID, searchID string, showProgress bool, serverService *services.ServerService) {
+	req.Reply("📖 正在获取图书详情，请稍候...", nil)
+
+	detail, err := serverService.GetItemDetail(req.Context(), itemID, showProgress)
+	if err != nil {
+		req.Reply("❌ 获取图书详情失败: "+services.DescribeError(err), nil)
+		return
+	}
+
+	menu := bot_pkg.CreateItemDetailMenu(itemID, searchID, req.Role >= bot_pkg.RoleAdmin)
+	if len(detail.Cover) > 0 {
+		// 详情卡片是图片消息，替换掉原来的结果列表消息
+		photo := tgbotapi.NewPhoto(req.ChatID, tgbotapi.FileBytes{Name: "cover.jpg", Bytes: detail.Cover})
+		photo.Caption = formatItemDetail(detail, maxCaptionLength)
+		photo.ParseMode = "Markdown"
+		photo.ReplyMarkup = menu
+		if _, err := req.Bot.Send(photo); err == nil {
+			req.DeleteMessage()
+			return
+		}
+		// 封面格式不被 Telegram 接受时退回到文本消息
+	}
+
+	req.ReplyMarkdown(formatItemDetail(detail, maxMessageLength), &menu)
+}
+
+// formatItemDetail 格式化条目详情，简介会被截断以满足 limit 长度限制
+func formatItemDetail(detail *services.ItemDetail, limit int) string {
+	item := detail.Item
+	var sb strings.Builder
+
+	title := item.Title()
+	if title == "" {
+		title = item.RelPath
+	}
+	sb.WriteString(fmt.Sprintf("📖 *%s*\n", escapeMarkdown(title)))
+
+	description := ""
+	if book := item.Book; book != nil {
+		metadata := book.Metadata
+		if metadata.Subtitle != "" {
+			sb.WriteString(fmt.Sprintf("_%s_\n", escapeMarkdown(metadata.Subtitle)))
+		}
+		sb.WriteString("\n")
+		if authors := metadata.AuthorNames(); authors != "" {
+			sb.WriteString(fmt.Sprintf("✍️ 作者: %s\n", escapeMarkdown(authors)))
+		}
+		if narrators := metadata.NarratorNames(); narrators != "" {
+			sb.WriteString(fmt.Sprintf("🎙 朗读: %s\n", escapeMarkdown(narrators)))
+		}
+		if series := metadata.SeriesNames(); series != "" {
+			sb.WriteString(fmt.Sprintf("📚 系列: %s\n", escapeMarkdown(series)))
+		}
+		if book.Duration > 0 {
+			sb.WriteString(fmt.Sprintf("⏱ 时长: %s\n", formatSeconds(book.Duration)))
+		}
+		if chapters := book.ChapterCount(); chapters > 0 {
+			sb.WriteString(fmt.Sprintf("📑 章节: %d\n", chapters))
+		}
+		description = metadata.Description
+	} else if podcast := item.Podcast; podcast != nil {
+		sb.WriteString("\n")
+		if podcast.Metadata.Author != "" {
+			sb.WriteString(fmt.Sprintf("✍️ 作者: %s\n", escapeMarkdown(podcast.Metadata.Author)))
+		}
+		sb.WriteString(fmt.Sprintf("🎙 剧集: %d\n", len(podcast.Episodes)))
+		description = podcast.Metadata.Description
+	}
+
+	if detail.LibraryName != "" {
+		sb.WriteString(fmt.Sprintf("📁 媒体库: %s\n", escapeMarkdown(detail.LibraryName)))
+	}
+	if detail.ShowProgress {
+		sb.WriteString(fmt.Sprintf("🎧 我的进度: %s\n", formatProgress(detail)))
+	}
+
+	if description != "" {
+		// 预留简介前换行符的空间
+		remaining := limit - utf16Length(sb.String()) - 1
+		if remaining > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(fitDescription(description, remaining))
+		}
+	}
+
+	return sb.String()
+}
+
+// formatProgress 格式化当前用户的播放进度
+func formatProgress(detail *services.ItemDetail) string {
+	progress := detail.Progress
+	switch {
+	case progress == nil:
+		return "未开始"
+	case progress.IsFinished:
+		return "✅ 已听完"
+	}
+
+	duration := progress.Duration
+	if duration == 0 {
+		duration = detail.Item.Duration()
+	}
+	return fmt.Sprintf("%.0f%% (%s / %s)", progress.Progress*100, formatSeconds(progress.CurrentTime), formatSeconds(duration))
+}
+
+// fitDescription 去掉简介中的 HTML 标签并转义，截断到不超过 limit 个 UTF-16 代码单元
+// 转义会增加字符，因此先截断再逐步缩短，直到满足限制
+func fitDescription(description string, limit int) string {
+	text := stripHTML(description)
+	for budget := limit; budget > 0; {
+		escaped := escapeMarkdown(truncateText(text, budget))
+		over := utf16Length(escaped) - limit
+		if over <= 0 {
+			return escaped
+		}
+		// 每个字符转义后最多占两倍长度，按超出长度的一半缩短可以避免截断过多
+		budget -= (over + 1) / 2
+	}
+	return ""
+}
+
+// truncateText 将文本截断到 limit 个 UTF-16 代码单元以内，截断时再加上一个省略号
+func truncateText(text string, limit int) string {
+	text = strings.TrimSpace(text)
+	length := 0
+	for i, r := range text {
+		length += utf16RuneLength(r)
+		if length > limit {
+			return text[:i] + "…"
+		}
+	}
+	return text
+}
+
+// utf16Length 返回文本的 UTF-16 长度，Telegram 按 UTF-16 代码单元计算消息和标题的长度
+func utf16Length(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16RuneLength(r)
+	}
+	return length
+}
+
+// utf16RuneLength 返回字符的 UTF-16 长度，基本多文种平面之外的字符（如大部分 emoji）占两个代码单元
+func utf16RuneLength(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// stripHTML 去掉简介中的 HTML 标签，Audiobookshelf 的简介通常是富文本
+func stripHTML(text string) string {
+	var sb strings.Builder
+	inTag := false
+	for _, r := range text {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>' && inTag:
+			inTag = false
+		case !inTag:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+func TestFormatItemDetailCaptionLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+	}{
+		{"中文", strings.Repeat("三体", 1000)},
+		{"emoji", strings.Repeat("📚🎧", 1000)},
+		{"需要转义的字符", strings.Repeat("_*`[", 1000)},
+		{"混合", strings.Repeat("a😀_", 1000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &models.LibraryItem{
+				MediaType: models.MediaTypeBook,
+				Book: &models.BookMedia{
+					Metadata: models.BookMetadata{Title: "🚀 测试图书", Description: tt.description},
+				},
+			}
+			caption := formatItemDetail(&services.ItemDetail{Item: item}, maxCaptionLength)
+			if length := utf16Length(caption); length > maxCaptionLength {
+				t.Errorf("标题长度 %d 超过了 %d 个 UTF-16 代码单元", length, maxCaptionLength)
+			}
+			if !strings.Contains(caption, "…") {
+				t.Error("过长的简介应被截断")
+			}
+		})
+	}
+}
+
+func TestFormatItemDetailProgress(t *testing.T) {
+	item := &models.LibraryItem{
+		MediaType: models.MediaTypeBook,
+		Book:      &models.BookMedia{Metadata: models.BookMetadata{Title: "测试图书"}},
+	}
+
+	if text := formatItemDetail(&services.ItemDetail{Item: item, ShowProgress: true}, maxMessageLength); !strings.Contains(text, "我的进度") {
+		t.Errorf("已绑定账户的用户应看到收听进度: %q", text)
+	}
+	if text := formatItemDetail(&services.ItemDetail{Item: item}, maxMessageLength); strings.Contains(text, "我的进度") {
+		t.Errorf("未绑定账户的用户不应看到共享 Token 的收听进度: %q", text)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{"abc", 3, "abc"},
+		{"abcd", 3, "abc…"},
+		{"😀😀", 4, "😀😀"},
+		{"😀😀", 3, "😀…"},
+		{"  空白  ", 2, "空白"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateText(tt.text, tt.limit); got != tt.want {
+			t.Errorf("truncateText(%q, %d) 期望 %q，实际为 %q", tt.text, tt.limit, tt.want, got)
+		}
+	}
+}
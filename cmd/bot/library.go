@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// libraryTopCount 媒体库详情中列出的作者、流派和最大条目的数量
+const libraryTopCount = 5
+
+// sendLibraryDetail 发送媒体库详情：文件夹、上次扫描时间、容量和时长统计，以及条目最多的作者和流派
+func sendLibraryDetail(req *bot_pkg.Request, serverService *services.ServerService) {
+	libraryID := req.Data.Arg(0)
+	req.Reply("📚 正在获取媒体库详情，请稍候...", nil)
+
+	detail, err := serverService.GetLibraryDetail(req.Context(), libraryID)
+	if err != nil {
+		log.Printf("获取媒体库 %s 的详情失败: %v", libraryID, err)
+		req.Reply("❌ 获取媒体库详情失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	menu := bot_pkg.CreateLibraryDetailMenu(libraryID, req.Role >= bot_pkg.RoleAdmin)
+	req.ReplyMarkdown(formatLibraryDetail(detail), &menu)
+}
+
+// openLibraryItems 列出媒体库中的条目，之后的翻页和查看详情与搜索结果相同
+func openLibraryItems(req *bot_pkg.Request, serverService *services.ServerService) {
+	libraryID := req.Data.Arg(0)
+	recent := req.Data.Arg(1) == bot_pkg.LibraryItemsRecent
+	req.Reply("📚 正在获取图书列表，请稍候...", nil)
+
+	name, err := serverService.GetLibraryName(req.Context(), libraryID)
+	if err != nil {
+		req.Reply("❌ 获取媒体库信息失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	var items *services.FilteredItems
+	title := "📚 媒体库: " + name
+	if recent {
+		items, err = serverService.ListLibraryItems(req.Context(), libraryID, api.SortByAddedAt, true)
+		title += "（最近添加）"
+	} else {
+		items, err = serverService.ListLibraryItems(req.Context(), libraryID, api.SortByTitle, false)
+	}
+	if err != nil {
+		log.Printf("获取媒体库 %s 的条目失败: %v", libraryID, err)
+		menu := bot_pkg.CreateLibraryDetailMenu(libraryID, req.Role >= bot_pkg.RoleAdmin)
+		req.Reply("❌ 获取图书列表失败: "+services.DescribeError(err), &menu)
+		return
+	}
+
+	if items.Total > len(items.Items) {
+		title += fmt.Sprintf(" (共 %d 本，仅显示前 %d 本)", items.Total, len(items.Items))
+	}
+	result := searchCache.PutList(req.ChatID, title, items.Items)
+	showSearchPage(req, result, 0, serverService)
+}
+
+// formatLibraryDetail 格式化媒体库详情
+func formatLibraryDetail(detail *services.LibraryDetail) string {
+	library := detail.Library
+	stats := detail.Stats
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("📚 *%s*\n\n", escapeMarkdown(library.Name)))
+	sb.WriteString(fmt.Sprintf("🗂 类型: %s\n", libraryMediaTypeName(library.MediaType)))
+	if library.Provider != "" {
+		sb.WriteString(fmt.Sprintf("🌐 元数据来源: %s\n", escapeMarkdown(library.Provider)))
+	}
+	if library.LastScan > 0 {
+		sb.WriteString(fmt.Sprintf("🕒 上次扫描: %s\n", time.Unix(library.LastScan/1000, 0).Format("2006-01-02 15:04:05")))
+	} else {
+		sb.WriteString("🕒 上次扫描: 从未扫描\n")
+	}
+	if len(library.Folders) > 0 {
+		sb.WriteString("📁 文件夹:\n")
+		for _, folder := range library.Folders {
+			// 旧版 Markdown 的代码块中无法转义反引号，路径按普通文本转义后输出
+			sb.WriteString(fmt.Sprintf("  • %s\n", escapeMarkdown(folder.Path)))
+		}
+	}
+
+	sb.WriteString("\n*📊 统计:*\n")
+	sb.WriteString(fmt.Sprintf("📦 条目: %d\n", stats.TotalItems))
+	if stats.TotalAuthors > 0 {
+		sb.WriteString(fmt.Sprintf("✍️ 作者: %d\n", stats.TotalAuthors))
+	}
+	if stats.NumAudioTracks > 0 {
+		sb.WriteString(fmt.Sprintf("🎵 音轨: %d\n", stats.NumAudioTracks))
+	}
+	sb.WriteString(fmt.Sprintf("💾 总大小: %s\n", services.FormatBytes(stats.TotalSize)))
+	sb.WriteString(fmt.Sprintf("⏱ 总时长: %s\n", formatSeconds(stats.TotalDuration)))
+
+	if len(stats.AuthorsWithCount) > 0 {
+		sb.WriteString("\n*✍️ 作品最多的作者:*\n")
+		for i, author := range stats.AuthorsWithCount {
+			if i >= libraryTopCount {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("%d. %s (%d)\n", i+1, escapeMarkdown(author.Name), author.Count))
+		}
+	}
+
+	if len(stats.GenresWithCount) > 0 {
+		sb.WriteString("\n*🏷 热门流派:*\n")
+		for i, genre := range stats.GenresWithCount {
+			if i >= libraryTopCount {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("%d. %s (%d)\n", i+1, escapeMarkdown(genre.Genre), genre.Count))
+		}
+	}
+
+	if len(stats.LargestItems) > 0 {
+		sb.WriteString("\n*💽 占用空间最大的条目:*\n")
+		for i, item := range stats.LargestItems {
+			if i >= libraryTopCount {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("%d. %s — %s\n", i+1, escapeMarkdown(item.Title), services.FormatBytes(item.Size)))
+		}
+	}
+
+	return sb.String()
+}
+
+// libraryMediaTypeName 返回媒体库类型的中文名称
+func libraryMediaTypeName(mediaType string) string {
+	switch mediaType {
+	case "book":
+		return "有声书"
+	case "podcast":
+		return "播客"
+	default:
+		return mediaType
+	}
+}
@@ -1,38 +1,43 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
-	"os"
 	"os/signal"
-	"sort"
+	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/accounts"
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
 	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/store"
 )
 
-var allowedUserIDs map[int64]bool
+// credentialsKeyFile 未设置 CREDENTIALS_KEY 时保存随机加密密钥的文件，位于数据目录中
+const credentialsKeyFile = "credentials.key"
 
 func main() {
 	// 加载配置
 	cfg := config.LoadConfig()
 
-	// 初始化允许的用户ID映射
-	allowedUserIDs = make(map[int64]bool)
-	for _, id := range cfg.AllowedUserIDs {
-		allowedUserIDs[id] = true
+	// 根据 USER_ROLES 和 ALLOWED_USER_IDS 确定用户的角色
+	access := bot_pkg.NewAccessControl(cfg.UserRoles, cfg.AllowedUserIDs)
+	if access.Open() {
+		log.Println("警告：未配置 USER_ROLES 和 ALLOWED_USER_IDS，所有 Telegram 用户都可以以听众身份使用机器人")
+	} else {
+		log.Printf("允许访问的用户ID: %v，用户角色: %v", cfg.AllowedUserIDs, cfg.UserRoles)
 	}
-	log.Printf("允许访问的用户ID: %v", cfg.AllowedUserIDs)
 
 	// 检查必要配置
 	if cfg.TelegramBotToken == "" {
@@ -81,16 +86,61 @@ func main() {
 	// 初始化服务器信息服务
 	serverService := services.NewServerService(audiobookshelfClient)
 
+	// 处理中断信号以便优雅关闭，收到信号后 ctx 会被取消，进行中的 API 请求随之中止
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	// 测试连接
-	_, err = audiobookshelfClient.GetLibraries()
-	if err != nil {
+	_, err = audiobookshelfClient.GetLibraries(ctx)
+	switch {
+	case api.IsUnauthorized(err):
+		log.Printf("警告：Audiobookshelf 拒绝了 AUDIOBOOKSHELF_TOKEN，请检查 Token 是否有效: %v", err)
+	case err != nil:
 		log.Printf("警告：无法连接到 Audiobookshelf API: %v", err)
-	} else {
+	default:
 		log.Println("成功连接到 Audiobookshelf API")
 	}
 
+	// 打开本地存储，账户绑定和进行中的对话在重启后仍然保留
+	db, err := store.Open(cfg.DataDir)
+	if err != nil {
+		log.Fatal("无法打开数据目录:", err)
+	}
+	log.Printf("数据目录: %s (数据版本 %d)", db.Dir(), db.Version())
+	if err := conversations.Persist(store.NewConversationRepository(db)); err != nil {
+		log.Printf("恢复对话状态失败: %v", err)
+	}
+
+	// 加载账户绑定，已绑定的用户以自己的 Audiobookshelf 账户执行操作
+	credentialsKey := cfg.CredentialsKey
+	if credentialsKey == "" {
+		// 未配置密钥时使用数据目录中随机生成的密钥，不与机器人 Token 关联
+		keyPath := filepath.Join(db.Dir(), credentialsKeyFile)
+		credentialsKey, err = accounts.LoadOrCreateKey(keyPath)
+		if err != nil {
+			log.Fatal("无法读取凭据加密密钥:", err)
+		}
+		log.Printf("未设置 CREDENTIALS_KEY，使用密钥文件 %s 加密已保存的凭据", keyPath)
+	}
+	credentialsCipher, err := accounts.NewCipher(credentialsKey)
+	if err != nil {
+		log.Fatal("无法创建凭据加密器:", err)
+	}
+	links := accounts.NewStore(store.NewLinkRepository(db), credentialsCipher)
+	accountService := services.NewAccountService(audiobookshelfClient, serverService, links)
+
+	notifications := services.NewNotificationService(audiobookshelfClient, store.NewSubscriptionRepository(db), store.NewWatermarkRepository(db))
+
+	// 通过 socket.io 接收 Audiobookshelf 的实时事件，用于新书通知和扫描进度
+	var events *api.EventStream
+	if cfg.EventsEnabled {
+		events = api.NewEventStream(audiobookshelfClient)
+	}
+
+	router := newRouter(access.Resolve, accountService, notifications, events)
+
 	// 注册菜单命令
-	err = bot_pkg.RegisterCommands(telegramBot)
+	err = router.RegisterCommands(telegramBot)
 	if err != nil {
 		log.Printf("注册命令失败: %v", err)
 	} else {
@@ -103,195 +153,138 @@ func main() {
 
 	updates := telegramBot.GetUpdatesChan(u)
 
-	// 处理中断信号以便优雅关闭
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// 每个更新在独立的 goroutine 中处理，避免单个慢请求阻塞整个更新循环
+	var wg sync.WaitGroup
+
+	if events != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			events.Run(ctx)
+		}()
+	}
+
+	// 定期检查订阅的媒体库并推送新书通知，收到新增条目的事件时提前检查
+	if cfg.NotifyInterval > 0 {
+		var itemEvents <-chan api.Event
+		if events != nil {
+			itemEvents, _ = events.Subscribe(64, api.EventItemAdded, api.EventItemsAdded, api.EventConnected)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runNotifier(ctx, telegramBot, notifications, cfg.NotifyInterval, itemEvents)
+		}()
+	} else {
+		log.Println("NOTIFY_INTERVAL 为 0，不检查新书")
+	}
 
 	// 同时处理来自 Telegram 的更新和系统信号
 	for {
 		select {
 		case update := <-updates:
-			if update.Message != nil { // 如果我们收到一条消息
-				if !isUserAllowed(update.Message.From.ID) {
-					log.Printf("拒绝用户 %s (ID: %d) 的访问", update.Message.From.UserName, update.Message.From.ID)
-					sendAccessDeniedMessage(telegramBot, update.Message.Chat.ID)
-					continue
-				}
-				handleMessage(telegramBot, update.Message, serverService)
-			} else if update.CallbackQuery != nil { // 如果我们收到一个回调查询（按钮点击）
-				if !isUserAllowed(update.CallbackQuery.From.ID) {
-					log.Printf("拒绝用户 %s (ID: %d) 的访问", update.CallbackQuery.From.UserName, update.CallbackQuery.From.ID)
-					sendAccessDeniedMessage(telegramBot, update.CallbackQuery.Message.Chat.ID)
-					// 响应回调查询，避免按钮loading状态持续太久
-					callbackResp := tgbotapi.NewCallback(update.CallbackQuery.ID, "访问被拒绝")
-					telegramBot.Send(callbackResp)
-					continue
-				}
-				handleCallbackQuery(telegramBot, update.CallbackQuery, serverService)
-			}
+			wg.Add(1)
+			go func(update tgbotapi.Update) {
+				defer wg.Done()
+				handleUpdate(ctx, telegramBot, update, router)
+			}(update)
 
-		case <-sigChan:
+		case <-ctx.Done():
 			log.Println("接收到中断信号，正在关闭...")
+			telegramBot.StopReceivingUpdates()
+			waitForHandlers(&wg, cfg.ShutdownTimeout)
 			return
 		}
 	}
 }
 
-// isUserAllowed 检查用户是否有权限使用机器人
-func isUserAllowed(userID int64) bool {
-	// 如果没有设置允许的用户ID，则允许所有用户访问（向后兼容）
-	if len(allowedUserIDs) == 0 {
-		return true
+// handleUpdate 处理单个 Telegram 更新
+func handleUpdate(ctx context.Context, telegramBot *tgbotapi.BotAPI, update tgbotapi.Update, router *bot_pkg.Router) {
+	// 访问控制和权限检查由 router 负责
+	if update.Message != nil { // 如果我们收到一条消息
+		// 用户开始其他操作时停止正在播放页面的自动刷新
+		nowPlayingRefresh.Stop(update.Message.Chat.ID)
+		handleMessage(ctx, telegramBot, update.Message, router)
+	} else if update.CallbackQuery != nil { // 如果我们收到一个回调查询（按钮点击）
+		// 点击按钮时放弃等待中的文本输入，需要输入的操作会重新设置状态
+		// 内联模式或过旧消息上的按钮不附带原消息，由 router 直接应答
+		if message := update.CallbackQuery.Message; message != nil {
+			conversations.Clear(message.Chat.ID)
+			nowPlayingRefresh.Stop(message.Chat.ID)
+		}
+		router.HandleCallbackQuery(ctx, telegramBot, update.CallbackQuery)
 	}
-	
-	// 检查用户是否在允许列表中
-	return allowedUserIDs[userID]
 }
 
-// sendAccessDeniedMessage 发送访问拒绝消息
-func sendAccessDeniedMessage(bot *tgbotapi.BotAPI, chatID int64) {
-	msg := tgbotapi.NewMessage(chatID, "🚫 抱歉，您没有权限使用此机器人。")
-	bot.Send(msg)
+// waitForHandlers 等待进行中的处理函数结束，最多等待 timeout
+func waitForHandlers(wg *sync.WaitGroup, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("所有请求已处理完毕")
+	case <-time.After(timeout):
+		log.Printf("等待进行中的请求超时 (%s)，强制退出", timeout)
+	}
 }
 
 // handleMessage 处理消息
-func handleMessage(bot *tgbotapi.BotAPI, message *tgbotapi.Message, serverService *services.ServerService) {
-	log.Printf("[%s] %s", message.From.UserName, message.Text)
+func handleMessage(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, router *bot_pkg.Router) {
+	if isSensitiveMessage(message) {
+		log.Printf("[%s] <已隐藏可能包含密码或 Token 的消息>", message.From.UserName)
+	} else {
+		log.Printf("[%s] %s", message.From.UserName, message.Text)
+	}
 
 	// 只响应特定用户的私聊消息（可选安全措施）
 	if message.Chat.Type != "private" {
 		return
 	}
 
-	switch strings.ToLower(message.Text) {
-	case "/start", "/help":
-		sendMainMenu(bot, message.Chat.ID, 0)
-	case "/serverinfo":
-		sendServerInfo(bot, message.Chat.ID, 0, serverService)
-	case "/users":
-		sendUsersInfo(bot, message.Chat.ID, 0, serverService)
-	case "/search":
-		promptForSearchTerm(bot, message.Chat.ID, 0)
-	case "/libraries":
-		sendLibrariesList(bot, message.Chat.ID, 0, serverService)
-	case "/mystats":
-		sendMyStats(bot, message.Chat.ID, 0, serverService)
-	default:
-		// 检查是否是搜索查询
-		log.Printf("检查是否是搜索查询: ReplyToMessage=%v, Text=%s", message.ReplyToMessage, message.Text)
-		if message.ReplyToMessage != nil {
-			log.Printf("ReplyToMessage Text: %s", message.ReplyToMessage.Text)
-			if strings.Contains(message.ReplyToMessage.Text, "请输入您要搜索的图书名称") {
-				log.Printf("识别为搜索请求: %s", message.Text)
-				performBookSearch(bot, message.Chat.ID, message.Text, serverService)
-				return
-			}
-		}
-
-		// 检查是否是搜索关键词（不依赖ReplyToMessage）
-		// 如果用户刚刚点击了搜索按钮，我们就认为下一条消息是搜索词
-		performBookSearch(bot, message.Chat.ID, message.Text, serverService)
-		return
+	// 发送其他命令时放弃进行中的多步操作
+	if message.IsCommand() && !strings.EqualFold(message.Command(), "cancel") {
+		conversations.Clear(message.Chat.ID)
 	}
-}
 
-// handleCallbackQuery 处理回调查询（按钮点击）
-func handleCallbackQuery(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery, serverService *services.ServerService) {
-	// 响应回调查询，避免按钮loading状态持续太久
-	callbackResp := tgbotapi.NewCallback(callback.ID, "")
-	bot.Send(callbackResp)
-	
-	switch callback.Data {
-	case "main_menu":
-		editMainMenu(bot, callback.Message.Chat.ID, callback.Message.MessageID)
-	case "system_info":
-		// 显示加载状态
-		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, "📊 正在获取服务器信息，请稍候...")
-		bot.Send(edit)
-		// 执行实际操作
-		editServerInfo(bot, callback.Message.Chat.ID, callback.Message.MessageID, serverService)
-	case "search_books":
-		promptForSearchTerm(bot, callback.Message.Chat.ID, callback.Message.MessageID)
-	case "users_list":
-		// 显示加载状态
-		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, "👥 正在获取用户信息，请稍候...")
-		bot.Send(edit)
-		// 执行实际操作
-		sendUsersInfo(bot, callback.Message.Chat.ID, callback.Message.MessageID, serverService)
-	case "my_stats":
-		// 显示加载状态
-		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, "📈 正在获取个人统计信息，请稍候...")
-		bot.Send(edit)
-		// 执行实际操作
-		sendMyStats(bot, callback.Message.Chat.ID, callback.Message.MessageID, serverService)
-	case "libraries_list":
-		// 显示加载状态
-		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, "📚 正在获取媒体库信息，请稍候...")
-		bot.Send(edit)
-		// 执行实际操作
-		sendLibrariesList(bot, callback.Message.Chat.ID, callback.Message.MessageID, serverService)
-	case "help":
-		editHelpMessage(bot, callback.Message.Chat.ID, callback.Message.MessageID)
-	}
+	router.HandleMessage(ctx, bot, message)
 }
 
 // sendMainMenu 发送主菜单
-func sendMainMenu(bot *tgbotapi.BotAPI, chatID int64, messageID int) {
-	msg := tgbotapi.NewMessage(chatID, "🎧 *欢迎使用 Audiobookshelf 管理机器人*\n\n请选择您要执行的操作:")
-	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = bot_pkg.CreateMainMenu()
-	bot.Send(msg)
-}
-
-// editMainMenu 编辑主菜单
-func editMainMenu(bot *tgbotapi.BotAPI, chatID int64, messageID int) {
-	edit := tgbotapi.NewEditMessageText(chatID, messageID, "🎧 *欢迎使用 Audiobookshelf 管理机器人*\n\n请选择您要执行的操作:")
-	edit.ParseMode = "Markdown"
-	menu := bot_pkg.CreateMainMenu()
-	edit.ReplyMarkup = &menu
-	bot.Send(edit)
+func sendMainMenu(req *bot_pkg.Request) {
+	req.ReplyMarkdown("🎧 *欢迎使用 Audiobookshelf 管理机器人*\n\n请选择您要执行的操作:", req.MainMenu())
 }
 
 // sendServerInfo 发送服务器信息
-func sendServerInfo(bot *tgbotapi.BotAPI, chatID int64, messageID int, serverService *services.ServerService) {
-	info, err := serverService.GetFormattedServerInfo()
+func sendServerInfo(req *bot_pkg.Request, serverService *services.ServerService) {
+	// 显示加载状态
+	req.Reply("📊 正在获取服务器信息，请稍候...", nil)
+
+	info, err := serverService.GetFormattedServerInfo(req.Context())
 	if err != nil {
-		if messageID > 0 {
-			editMessage(bot, chatID, messageID, "❌ 获取服务器信息失败: "+err.Error())
-		} else {
-			sendMessage(bot, chatID, "❌ 获取服务器信息失败: "+err.Error())
+		text := "❌ 获取服务器信息失败: " + services.DescribeError(err)
+		if connection := serverService.ConnectionStatus(); connection != "" {
+			text += "\n\n🛡 连接状态: " + connection
 		}
+		req.Reply(text, nil)
 		return
 	}
 
-	if messageID > 0 {
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, info)
-		edit.ParseMode = "Markdown"
-		menu := bot_pkg.CreateServerInfoMenu()
-		edit.ReplyMarkup = &menu
-		bot.Send(edit)
-	} else {
-		msg := tgbotapi.NewMessage(chatID, info)
-		msg.ParseMode = "Markdown"
-		msg.ReplyMarkup = bot_pkg.CreateServerInfoMenu()
-		bot.Send(msg)
-	}
-}
-
-// editServerInfo 编辑服务器信息
-func editServerInfo(bot *tgbotapi.BotAPI, chatID int64, messageID int, serverService *services.ServerService) {
-	sendServerInfo(bot, chatID, messageID, serverService)
+	menu := bot_pkg.CreateServerInfoMenu()
+	req.ReplyMarkdown(info, &menu)
 }
 
 // sendLibrariesList 发送媒体库列表
-func sendLibrariesList(bot *tgbotapi.BotAPI, chatID int64, messageID int, serverService *services.ServerService) {
-	libraries, err := serverService.GetLibrariesWithStats()
+func sendLibrariesList(req *bot_pkg.Request, serverService *services.ServerService) {
+	// 显示加载状态
+	req.Reply("📚 正在获取媒体库信息，请稍候...", nil)
+
+	libraries, err := serverService.GetLibrariesWithStats(req.Context())
 	if err != nil {
-		if messageID > 0 {
-			editMessage(bot, chatID, messageID, "❌ 获取媒体库列表失败: "+err.Error())
-		} else {
-			sendMessage(bot, chatID, "❌ 获取媒体库列表失败: "+err.Error())
-		}
+		req.Reply("❌ 获取媒体库列表失败: "+services.DescribeError(err), nil)
 		return
 	}
 
@@ -301,412 +294,222 @@ func sendLibrariesList(bot *tgbotapi.BotAPI, chatID int64, messageID int, server
 	} else {
 		text = "📚 *媒体库列表*:\n\n"
 		for _, lib := range libraries {
-			text += fmt.Sprintf("📖 %s\n", lib.Name)
+			text += fmt.Sprintf("📖 %s — %d 个条目\n", escapeMarkdown(lib.Name), lib.ItemCount)
 		}
+		text += "\n点击媒体库查看详情"
 	}
 
-	if messageID > 0 {
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
-		edit.ParseMode = "Markdown"
-		menu := bot_pkg.CreateLibrariesMenu()
-		edit.ReplyMarkup = &menu
-		bot.Send(edit)
-	} else {
-		msg := tgbotapi.NewMessage(chatID, text)
-		msg.ParseMode = "Markdown"
-		msg.ReplyMarkup = bot_pkg.CreateLibrariesMenu()
-		bot.Send(msg)
-	}
-}
-
-// editLibrariesList 编辑媒体库列表
-func editLibrariesList(bot *tgbotapi.BotAPI, chatID int64, messageID int, serverService *services.ServerService) {
-	sendLibrariesList(bot, chatID, messageID, serverService)
-}
-
-// promptForSearchTerm 提示用户输入搜索词
-func promptForSearchTerm(bot *tgbotapi.BotAPI, chatID int64, messageID int) {
-	// 如果已经有消息ID，则编辑现有消息
-	if messageID > 0 {
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, "🔍 请输入您要搜索的图书名称、作者或其他关键词：")
-		menu := bot_pkg.CreateSearchMenu()
-		edit.ReplyMarkup = &menu
-		bot.Send(edit)
-	} else {
-		// 否则发送新消息
-		msg := tgbotapi.NewMessage(chatID, "🔍 请输入您要搜索的图书名称、作者或其他关键词：")
-		menu := bot_pkg.CreateSearchMenu()
-		msg.ReplyMarkup = &menu
-		bot.Send(msg)
+	infos := make([]models.LibraryInfo, len(libraries))
+	for i, lib := range libraries {
+		infos[i] = lib.LibraryInfo
 	}
+	menu := bot_pkg.CreateLibrariesMenu(infos, req.Role >= bot_pkg.RoleAdmin)
+	req.ReplyMarkdown(text, &menu)
 }
 
-// performBookSearch 执行图书搜索
-func performBookSearch(bot *tgbotapi.BotAPI, chatID int64, searchTerm string, serverService *services.ServerService) {
-	// 添加调试日志
-	log.Printf("执行图书搜索: %s", searchTerm)
-
-	// 调用搜索服务时不指定特定的媒体库，让服务自行处理所有媒体库的搜索
-	books, err := serverService.SearchBooks(searchTerm, "")
-	if err != nil {
-		log.Printf("搜索出错: %v", err)
-		response := fmt.Sprintf("❌ 搜索出错: %v", err)
-		msg := tgbotapi.NewMessage(chatID, response)
-		msg.ReplyMarkup = bot_pkg.CreateMainMenu()
-		bot.Send(msg)
-		return
-	}
-
-	// 格式化搜索结果
-	response := formatSearchResults(searchTerm, books, serverService)
-
-	// 发送或编辑消息
-	// 这里我们假设之前的提示消息是通过promptForSearchTerm函数发送的，
-	// 并且我们可以通过某种方式获取到该消息的ID
-	// 由于当前实现没有保存消息ID，我们需要重新设计
-	msg := tgbotapi.NewMessage(chatID, response)
-	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = bot_pkg.CreateMainMenu()
-	bot.Send(msg)
-}
-
-// formatSearchResults 格式化搜索结果
-func formatSearchResults(searchTerm string, books []models.Book, serverService *services.ServerService) string {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("🔎 搜索 \"%s\" 的结果:\n\n", searchTerm))
-
-	if len(books) == 0 {
-		sb.WriteString("未找到相关书籍。\n")
-		return sb.String()
-	}
-
-	sb.WriteString("*📚 找到的书籍:*\n")
-	for i, book := range books {
-		if i >= 10 { // 限制显示前10个结果
-			sb.WriteString(fmt.Sprintf("\n+ 还有 %d 本更多书籍...", len(books)-10))
-			break
-		}
-		// 获取媒体库名称
-		libraryName, err := serverService.GetLibraryName(book.LibraryID)
-		if err != nil {
-			libraryName = "未知媒体库"
-		}
-		var sizeUnit = services.FormatBytes(book.Size)
-		// 格式化添加时间 - 正确处理毫秒级时间戳
-		addedTime := time.Unix(book.AddedAt/1000, 0).Format("2006-01-02 15:04:05")
-		// 添加书籍信息
-		sb.WriteString(fmt.Sprintf("• **%s**\n  📁 媒体库: %s\n  💾 大小: %s\n  ⏳ 添加时间: %s\n\n",
-			book.RelPath,
-			libraryName,
-			sizeUnit,
-			addedTime))
-	}
-
-	return sb.String()
+// escapeMarkdown 转义 Markdown 特殊字符，避免书名等内容破坏消息格式
+func escapeMarkdown(text string) string {
+	return tgbotapi.EscapeText(tgbotapi.ModeMarkdown, text)
 }
 
 // sendUsersInfo 发送用户信息
-func sendUsersInfo(bot *tgbotapi.BotAPI, chatID int64, messageID int, serverService *services.ServerService) {
-	users, err := serverService.GetUsersWithProgress()
+func sendUsersInfo(req *bot_pkg.Request, serverService *services.ServerService) {
+	// 显示加载状态
+	req.Reply("👥 正在获取用户信息，请稍候...", nil)
+
+	views, err := serverService.GetUserViews(req.Context())
 	if err != nil {
-		if messageID > 0 {
-			editMessage(bot, chatID, messageID, "❌ 获取用户信息失败: "+err.Error())
-		} else {
-			sendMessage(bot, chatID, "❌ 获取用户信息失败: "+err.Error())
-		}
+		req.Reply("❌ 获取用户信息失败: "+services.DescribeError(err), nil)
 		return
 	}
 
 	var text string
-	if len(users) == 0 {
+	if len(views) == 0 {
 		text = "📭 没有找到用户"
 	} else {
 		text = "*👥 用户信息:*\n\n"
-		for _, user := range users {
-			// 格式化创建时间
-			createdAt := "未知"
-			if user.CreatedAt > 0 {
-				// createdAt 是毫秒时间戳
-				createdTime := time.Unix(user.CreatedAt/1000, 0).Format("2006-01-02 15:04:05")
-				createdAt = createdTime
-			}
-
-			// 格式化最后在线时间
-			lastSeen := "从未登录"
-			if user.LastSeen > 0 {
-				// lastSeen 是毫秒时间戳
-				lastSeenTime := time.Unix(user.LastSeen/1000, 0).Format("2006-01-02 15:04:05")
-				lastSeen = lastSeenTime
-			}
-
-			// 计算播放进度数量
-			progressCount := len(user.MediaProgress)
-
-			activeStatus := "❌ 非活跃"
-			if user.IsActive {
-				activeStatus = "✅ 活跃"
-			}
-
-			userType := "👤 普通用户"
-			// 根据Audiobookshelf的设计，root是管理员用户
-			if user.ID == "root" {
-				userType = "👑 管理员"
-			}
-
-			text += fmt.Sprintf("👤 *%s*\n", user.Username)
-			text += fmt.Sprintf("   %s | %s\n", userType, activeStatus)
-			text += fmt.Sprintf("   📅 创建于: %s\n", createdAt)
-			text += fmt.Sprintf("   👀 最后在线: %s\n", lastSeen)
-			text += fmt.Sprintf("   📊 播放进度: %d 个项目\n", progressCount)
+		for _, view := range views {
+			text += formatUserSummary(view)
 			text += "\n"
 		}
 	}
 
-	if messageID > 0 {
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
-		edit.ParseMode = "Markdown"
-		menu := bot_pkg.CreateUsersInfoMenu()
-		edit.ReplyMarkup = &menu
-		bot.Send(edit)
-	} else {
-		msg := tgbotapi.NewMessage(chatID, text)
-		msg.ParseMode = "Markdown"
-		msg.ReplyMarkup = bot_pkg.CreateUsersInfoMenu()
-		bot.Send(msg)
-	}
+	menu := bot_pkg.CreateUsersInfoMenu(views)
+	req.ReplyMarkdown(text, &menu)
 }
 
 // sendMyStats 发送个人统计信息
-func sendMyStats(bot *tgbotapi.BotAPI, chatID int64, messageID int, serverService *services.ServerService) {
-	user, err := serverService.GetCurrentUserWithProgress()
+func sendMyStats(req *bot_pkg.Request, serverService *services.ServerService) {
+	// 显示加载状态
+	req.Reply("📈 正在获取个人统计信息，请稍候...", nil)
+
+	view, err := serverService.GetCurrentUserView(req.Context())
 	if err != nil {
-		if messageID > 0 {
-			editMessage(bot, chatID, messageID, "❌ 获取个人信息失败: "+err.Error())
-		} else {
-			sendMessage(bot, chatID, "❌ 获取个人信息失败: "+err.Error())
-		}
+		req.Reply("❌ 获取个人信息失败: "+services.DescribeError(err), nil)
 		return
 	}
 
-	stats, err := serverService.GetListeningStats()
+	stats, err := serverService.GetListeningStats(req.Context())
 	if err != nil {
-		if messageID > 0 {
-			editMessage(bot, chatID, messageID, "❌ 获取收听统计失败: "+err.Error())
-		} else {
-			sendMessage(bot, chatID, "❌ 获取收听统计失败: "+err.Error())
-		}
+		req.Reply("❌ 获取收听统计失败: "+services.DescribeError(err), nil)
 		return
 	}
 
+	text := "*📈 我的统计信息:*\n\n"
+	text += formatUserSummary(view)
+	text += "\n"
+	text += formatListeningStats(stats)
+
+	menu := bot_pkg.CreateMyStatsMenu()
+	req.ReplyMarkdown(text, &menu)
+}
+
+// formatUserSummary 格式化用户的基本信息：角色、状态、可以访问的媒体库和权限
+func formatUserSummary(view *models.UserView) string {
+	user := view.User
+
 	// 格式化创建时间
 	createdAt := "未知"
 	if user.CreatedAt > 0 {
 		// createdAt 是毫秒时间戳
-		createdTime := time.Unix(user.CreatedAt/1000, 0).Format("2006-01-02 15:04:05")
-		createdAt = createdTime
+		createdAt = time.Unix(user.CreatedAt/1000, 0).Format("2006-01-02 15:04:05")
 	}
 
 	// 格式化最后在线时间
 	lastSeen := "从未登录"
 	if user.LastSeen > 0 {
 		// lastSeen 是毫秒时间戳
-		lastSeenTime := time.Unix(user.LastSeen/1000, 0).Format("2006-01-02 15:04:05")
-		lastSeen = lastSeenTime
+		lastSeen = time.Unix(user.LastSeen/1000, 0).Format("2006-01-02 15:04:05")
 	}
 
-	// 计算播放进度数量
-	progressCount := len(user.MediaProgress)
-
 	activeStatus := "❌ 非活跃"
 	if user.IsActive {
 		activeStatus = "✅ 活跃"
 	}
-
-	userType := "👤 普通用户"
-	// 根据Audiobookshelf的设计，root是管理员用户
-	if user.ID == "root" {
-		userType = "👑 管理员"
+	status := view.Role + " | " + activeStatus
+	if presence := view.Presence(); presence != "" {
+		status += " | " + presence
 	}
 
-	// 获取收听统计信息 - 根据实际API文档
-	var totalTimeListenStr = "0秒"
-	if val, ok := stats["totalTime"]; ok {
-		switch v := val.(type) {
-		case float64:
-			totalTimeListenStr = services.FormatDuration(time.Duration(v) * time.Second)
-		case int64:
-			totalTimeListenStr = services.FormatDuration(time.Duration(v) * time.Second)
-		case int:
-			totalTimeListenStr = services.FormatDuration(time.Duration(v) * time.Second)
-		}
+	libraries := "全部"
+	if !view.AllLibraries {
+		libraries = formatNameList(view.Libraries)
 	}
 
-	// items 是一个对象，而不是数组，包含以libraryItemId为键的对象
-	// 获取最近播放的书籍
-	recentlyPlayedText := ""
-	if items, ok := stats["items"].(map[string]interface{}); ok && len(items) > 0 {
-		recentlyPlayedText = "\n\n📚 *最近播放的书籍:*\n"
+	text := fmt.Sprintf("👤 *%s*\n", escapeMarkdown(user.Username))
+	text += fmt.Sprintf("   %s\n", status)
+	text += fmt.Sprintf("   📚 媒体库: %s\n", libraries)
+	text += fmt.Sprintf("   🛡 权限: %s\n", formatNameList(view.Permissions))
+	text += fmt.Sprintf("   📅 创建于: %s\n", createdAt)
+	text += fmt.Sprintf("   👀 最后在线: %s\n", lastSeen)
+	text += fmt.Sprintf("   📊 播放进度: %d 个项目\n", len(user.MediaProgress))
+	return text
+}
 
-		// 创建一个切片来存储书籍并按时间排序
-		type PlayedItem struct {
-			ID            string
-			TimeListening float64
-			Title         string
-			Author        string
-		}
+// formatNameList 以顿号连接转义后的名称，列表为空时返回 "无"
+func formatNameList(names []string) string {
+	if len(names) == 0 {
+		return "无"
+	}
+	escaped := make([]string, len(names))
+	for i, name := range names {
+		escaped[i] = escapeMarkdown(name)
+	}
+	return strings.Join(escaped, "、")
+}
 
-		var playedItems []PlayedItem
+// sendUserStats 发送指定用户的收听统计信息（需要管理员 token）
+func sendUserStats(req *bot_pkg.Request, username string, serverService *services.ServerService) {
+	if username == "" {
+		req.Reply("用法: /userstats <用户名>\n例如: /userstats alice", nil)
+		return
+	}
 
-		// 遍历items对象
-		for itemId, itemData := range items {
-			if item, ok := itemData.(map[string]interface{}); ok {
-				playedItem := PlayedItem{ID: itemId}
+	// 显示加载状态
+	req.Reply("📈 正在获取用户统计信息，请稍候...", nil)
 
-				// 获取收听时间
-				if timeListening, ok := item["timeListening"].(float64); ok {
-					playedItem.TimeListening = timeListening
-				}
+	result, err := serverService.GetUserStats(req.Context(), username)
+	if err != nil {
+		req.Reply("❌ 获取用户统计失败: "+services.DescribeError(err), nil)
+		return
+	}
 
-				// 获取媒体元数据
-				if mediaMetadata, ok := item["mediaMetadata"].(map[string]interface{}); ok {
-					if title, ok := mediaMetadata["title"].(string); ok {
-						playedItem.Title = title
-					} else {
-						playedItem.Title = "未知书籍"
-					}
+	// 使用会话接口返回的最近会话替换统计中附带的会话
+	stats := *result.Stats
+	stats.RecentSessions = result.RecentSessions
 
-					if author, ok := mediaMetadata["author"].(string); ok {
-						playedItem.Author = author
-					}
-				}
+	text := fmt.Sprintf("*📈 %s 的统计信息:*\n\n", escapeMarkdown(result.User.Username))
+	text += formatListeningStats(&stats)
 
-				playedItems = append(playedItems, playedItem)
-			}
-		}
+	menu := bot_pkg.CreateUserStatsMenu()
+	req.ReplyMarkdown(text, &menu)
+}
 
-		// 按收听时间排序，收听时间最长的在前面
-		sort.Slice(playedItems, func(i, j int) bool {
-			return playedItems[i].TimeListening > playedItems[j].TimeListening
-		})
+// formatListeningStats 格式化收听统计信息
+func formatListeningStats(stats *models.ListeningStats) string {
+	var sb strings.Builder
 
-		// 最多显示5本最近播放的书籍
-		maxItems := 5
-		if len(playedItems) < maxItems {
-			maxItems = len(playedItems)
-		}
+	sb.WriteString("🎧 *收听统计:*\n")
+	sb.WriteString(fmt.Sprintf("   ⏱ 总收听时间: %s\n", formatSeconds(stats.TotalTime)))
+	if stats.Today > 0 {
+		sb.WriteString(fmt.Sprintf("   📅 今日收听: %s\n", formatSeconds(stats.Today)))
+	}
 
-		for i := 0; i < maxItems; i++ {
-			item := playedItems[i]
-			timeListeningStr := services.FormatDuration(time.Duration(item.TimeListening) * time.Second)
+	// 按收听时间排序，最多显示5本收听时间最长的书籍
+	if items := stats.TopItems(5); len(items) > 0 {
+		sb.WriteString("\n📚 *最近播放的书籍:*\n")
+		for _, item := range items {
+			title := item.MediaMetadata.Title
+			if title == "" {
+				title = "未知书籍"
+			}
 
 			// 如果有作者信息则显示
-			if item.Author != "" {
-				recentlyPlayedText += fmt.Sprintf("• %s\n  %s | 作者: %s\n", item.Title, timeListeningStr, item.Author)
+			if author := item.MediaMetadata.AuthorNames(); author != "" {
+				sb.WriteString(fmt.Sprintf("• %s\n  %s | 作者: %s\n", escapeMarkdown(title), formatSeconds(item.TimeListening), escapeMarkdown(author)))
 			} else {
-				recentlyPlayedText += fmt.Sprintf("• %s\n  %s\n", item.Title, timeListeningStr)
+				sb.WriteString(fmt.Sprintf("• %s\n  %s\n", escapeMarkdown(title), formatSeconds(item.TimeListening)))
 			}
 		}
 	}
 
-	// 获取最近会话信息
-	recentSessionsText := ""
-	if recentSessions, ok := stats["recentSessions"].([]interface{}); ok && len(recentSessions) > 0 {
-		recentSessionsText = "\n\n🕒 *最近会话:*\n"
-		// 最多显示3个最近会话
-		maxSessions := 3
-		if len(recentSessions) < maxSessions {
-			maxSessions = len(recentSessions)
-		}
+	// 最多显示3个最近会话
+	if len(stats.RecentSessions) > 0 {
+		sb.WriteString("\n🕒 *最近会话:*\n")
+		for i, session := range stats.RecentSessions {
+			if i >= 3 {
+				break
+			}
+
+			bookTitle := session.Title()
+			if bookTitle == "" {
+				bookTitle = "未知书籍"
+			}
+
+			// 显示标题可能是章节标题
+			displayTitle := ""
+			if session.DisplayTitle != "" && session.DisplayTitle != bookTitle {
+				displayTitle = fmt.Sprintf(" (%s)", escapeMarkdown(session.DisplayTitle))
+			}
 
-		for i := 0; i < maxSessions; i++ {
-			if session, ok := recentSessions[i].(map[string]interface{}); ok {
-				// 获取书籍标题
-				bookTitle := "未知书籍"
-				if mediaMetadata, ok := session["mediaMetadata"].(map[string]interface{}); ok {
-					if title, ok := mediaMetadata["title"].(string); ok {
-						bookTitle = title
-					}
-				}
-
-				// 获取播放时间
-				timeListeningStr := "0秒"
-				if timeListening, ok := session["timeListening"].(float64); ok {
-					timeListeningStr = services.FormatDuration(time.Duration(timeListening) * time.Second)
-				}
-
-				// 获取会话时间
-				sessionTimeStr := ""
-				if updatedAt, ok := session["updatedAt"].(float64); ok {
-					sessionTime := time.Unix(int64(updatedAt/1000), 0).Format("01-02 15:04")
-					sessionTimeStr = sessionTime
-				}
-
-				// 获取显示标题（可能是章节标题）
-				displayTitle := ""
-				if dTitle, ok := session["displayTitle"].(string); ok && dTitle != "" {
-					displayTitle = fmt.Sprintf(" (%s)", dTitle)
-				}
-
-				recentSessionsText += fmt.Sprintf("• %s%s\n  %s | %s\n", bookTitle, displayTitle, timeListeningStr, sessionTimeStr)
+			sessionTime := ""
+			if session.UpdatedAt > 0 {
+				sessionTime = time.Unix(session.UpdatedAt/1000, 0).Format("01-02 15:04")
 			}
+
+			sb.WriteString(fmt.Sprintf("• %s%s\n  %s | %s\n", escapeMarkdown(bookTitle), displayTitle, formatSeconds(session.TimeListening), sessionTime))
 		}
 	}
 
-	text := fmt.Sprintf("*📈 我的统计信息:*\n\n")
-	text += fmt.Sprintf("👤 *%s*\n", user.Username)
-	text += fmt.Sprintf("   %s | %s\n", userType, activeStatus)
-	text += fmt.Sprintf("   📅 创建于: %s\n", createdAt)
-	text += fmt.Sprintf("   👀 最后在线: %s\n", lastSeen)
-	text += fmt.Sprintf("   📊 播放进度: %d 个项目\n\n", progressCount)
-	text += fmt.Sprintf("🎧 *收听统计:*\n")
-	text += fmt.Sprintf("   ⏱ 总收听时间: %s\n", totalTimeListenStr)
-	text += recentlyPlayedText
-	text += recentSessionsText
-
-	if messageID > 0 {
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
-		edit.ParseMode = "Markdown"
-		menu := bot_pkg.CreateMyStatsMenu()
-		edit.ReplyMarkup = &menu
-		bot.Send(edit)
-	} else {
-		msg := tgbotapi.NewMessage(chatID, text)
-		msg.ParseMode = "Markdown"
-		msg.ReplyMarkup = bot_pkg.CreateMyStatsMenu()
-		bot.Send(msg)
-	}
+	return sb.String()
 }
 
-// editHelpMessage 编辑帮助信息
-func editHelpMessage(bot *tgbotapi.BotAPI, chatID int64, messageID int) {
-	helpText := `🎧 *Audiobookshelf 管理机器人帮助*
-
-可用命令:
-• /start - 显示主菜单
-• /serverinfo - 获取服务器信息
-• /users - 获取用户信息
-• /libraries - 获取媒体库列表
-• /search - 搜索图书
-• /mystats - 获取个人统计信息
-• /help - 显示此帮助信息
-
-或者使用下方的菜单按钮进行操作。
-`
-	edit := tgbotapi.NewEditMessageText(chatID, messageID, helpText)
-	edit.ParseMode = "Markdown"
-	menu := bot_pkg.CreateMainMenu()
-	edit.ReplyMarkup = &menu
-	bot.Send(edit)
+// formatSeconds 将秒数格式化为可读的时长
+func formatSeconds(seconds float64) string {
+	return services.FormatDuration(time.Duration(seconds * float64(time.Second)))
 }
 
-// sendMessage 发送简单文本消息
-func sendMessage(bot *tgbotapi.BotAPI, chatID int64, text string) {
-	msg := tgbotapi.NewMessage(chatID, text)
-	bot.Send(msg)
+// sendHelpMessage 发送帮助信息，只列出当前用户可以使用的命令
+func sendHelpMessage(req *bot_pkg.Request) {
+	req.ReplyMarkdown(req.HelpText(), req.MainMenu())
 }
-
-// editMessage 编辑简单文本消息
-func editMessage(bot *tgbotapi.BotAPI, chatID int64, messageID int, text string) {
-	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
-	bot.Send(edit)
-}
\ No newline at end of file
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// 新书通知设置
+const (
+	// notifyBatchThreshold 一次新增的条目不超过该数量时逐本发送带封面的通知，否则合并为一条消息
+	notifyBatchThreshold = 3
+	// notifySummaryLimit 合并通知中最多列出的条目数量
+	notifySummaryLimit = 20
+	// notifyDebounce 最后一个新增条目事件之后等待多久再检查新书
+	notifyDebounce = 15 * time.Second
+)
+
+// sendSubscriptions 显示媒体库列表及订阅状态，点击按钮切换订阅
+func sendSubscriptions(req *bot_pkg.Request, serverService *services.ServerService, notifications *services.NotificationService) {
+	libraries, err := serverService.ListLibraries(req.Context())
+	if err != nil {
+		req.Reply("❌ 获取媒体库列表失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	subscribed, err := subscribedLibraries(req.ChatID, notifications)
+	if err != nil {
+		req.Reply("❌ 读取订阅失败: "+err.Error(), req.MainMenu())
+		return
+	}
+
+	menu := bot_pkg.CreateSubscriptionsMenu(libraries, subscribed)
+	req.Reply("🔔 新书通知\n\n订阅的媒体库加入新书时，机器人会发送书名、作者和封面。点击媒体库订阅（➕）或取消订阅（✅）：", &menu)
+}
+
+// subscribeByName 按名称订阅或取消订阅媒体库，用于 /subscribe 和 /unsubscribe 命令带参数的情况
+func subscribeByName(req *bot_pkg.Request, name string, subscribe bool, serverService *services.ServerService, notifications *services.NotificationService) {
+	libraries, err := serverService.ListLibraries(req.Context())
+	if err != nil {
+		req.Reply("❌ 获取媒体库列表失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	library, ok := findLibrary(libraries, name)
+	if !ok {
+		names := make([]string, len(libraries))
+		for i, lib := range libraries {
+			names[i] = lib.Name
+		}
+		req.Reply(fmt.Sprintf("🔍 未找到媒体库 \"%s\"\n\n可用的媒体库: %s", name, strings.Join(names, ", ")), req.MainMenu())
+		return
+	}
+
+	setSubscription(req, library, subscribe, notifications)
+}
+
+// toggleSubscription 切换按钮对应媒体库的订阅状态，并刷新订阅菜单
+func toggleSubscription(req *bot_pkg.Request, serverService *services.ServerService, notifications *services.NotificationService) {
+	libraryID := req.Data.Arg(0)
+	subscribed, err := subscribedLibraries(req.ChatID, notifications)
+	if err != nil {
+		req.Reply("❌ 读取订阅失败: "+err.Error(), req.MainMenu())
+		return
+	}
+
+	if subscribed[libraryID] {
+		_, err = notifications.Unsubscribe(req.ChatID, libraryID)
+	} else {
+		_, err = notifications.Subscribe(req.ChatID, libraryID)
+	}
+	if err != nil {
+		log.Printf("修改聊天 %d 的订阅失败: %v", req.ChatID, err)
+		req.Reply("❌ 修改订阅失败: "+err.Error(), req.MainMenu())
+		return
+	}
+
+	sendSubscriptions(req, serverService, notifications)
+}
+
+// setSubscription 订阅或取消订阅媒体库并回复结果
+func setSubscription(req *bot_pkg.Request, library models.LibraryInfo, subscribe bool, notifications *services.NotificationService) {
+	var changed bool
+	var err error
+	if subscribe {
+		changed, err = notifications.Subscribe(req.ChatID, library.ID)
+	} else {
+		changed, err = notifications.Unsubscribe(req.ChatID, library.ID)
+	}
+	if err != nil {
+		log.Printf("修改聊天 %d 的订阅失败: %v", req.ChatID, err)
+		req.Reply("❌ 修改订阅失败: "+err.Error(), req.MainMenu())
+		return
+	}
+
+	var text string
+	switch {
+	case subscribe && changed:
+		text = fmt.Sprintf("🔔 已订阅媒体库「%s」，加入新书时会通知您", library.Name)
+	case subscribe:
+		text = fmt.Sprintf("您已经订阅了媒体库「%s」", library.Name)
+	case changed:
+		text = fmt.Sprintf("🔕 已取消订阅媒体库「%s」", library.Name)
+	default:
+		text = fmt.Sprintf("您没有订阅媒体库「%s」", library.Name)
+	}
+	req.Reply(text, req.MainMenu())
+}
+
+// subscribedLibraries 返回聊天已订阅的媒体库集合
+func subscribedLibraries(chatID int64, notifications *services.NotificationService) (map[string]bool, error) {
+	ids, err := notifications.Subscriptions(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		subscribed[id] = true
+	}
+	return subscribed, nil
+}
+
+// findLibrary 按名称查找媒体库，忽略大小写
+func findLibrary(libraries []models.LibraryInfo, name string) (models.LibraryInfo, bool) {
+	for _, library := range libraries {
+		if strings.EqualFold(library.Name, name) {
+			return library, true
+		}
+	}
+	return models.LibraryInfo{}, false
+}
+
+// runNotifier 定期检查订阅的媒体库并发送新书通知，直到 ctx 被取消
+// events 不为 nil 时，收到新增条目的实时事件后会提前检查，不必等到下一个检查周期
+func runNotifier(ctx context.Context, telegramBot *tgbotapi.BotAPI, notifications *services.NotificationService, interval time.Duration, events <-chan api.Event) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// 扫描时会连续收到很多新增事件，等事件停止一段时间后再检查，合并为一次通知
+	debounce := time.NewTimer(notifyDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	checkNewItems(ctx, telegramBot, notifications)
+	for {
+		select {
+		case <-ticker.C:
+			checkNewItems(ctx, telegramBot, notifications)
+		case <-debounce.C:
+			checkNewItems(ctx, telegramBot, notifications)
+		case _, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			debounce.Reset(notifyDebounce)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkNewItems 检查一次新条目并发送通知
+func checkNewItems(ctx context.Context, telegramBot *tgbotapi.BotAPI, notifications *services.NotificationService) {
+	batches, err := notifications.CheckNewItems(ctx)
+	if err != nil {
+		log.Printf("检查新书失败: %v", err)
+		return
+	}
+
+	for _, batch := range batches {
+		log.Printf("媒体库 %s 新增 %d 个条目，通知 %d 个聊天", batch.Library.Name, len(batch.Items), len(batch.ChatIDs))
+		if len(batch.Items) <= notifyBatchThreshold {
+			sendNewItemCards(ctx, telegramBot, notifications, batch)
+		} else {
+			sendNewItemsSummary(telegramBot, batch)
+		}
+	}
+}
+
+// sendNewItemCards 逐本发送带封面的新书通知，没有封面时发送文本
+func sendNewItemCards(ctx context.Context, telegramBot *tgbotapi.BotAPI, notifications *services.NotificationService, batch services.NewItemsBatch) {
+	// 按添加顺序发送，最早加入的在前
+	for i := len(batch.Items) - 1; i >= 0; i-- {
+		item := batch.Items[i]
+		text := formatNewItem(batch.Library, item)
+		cover := notifications.GetCover(ctx, item.ID)
+
+		for _, chatID := range batch.ChatIDs {
+			if len(cover) > 0 {
+				photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "cover.jpg", Bytes: cover})
+				photo.Caption = text
+				photo.ParseMode = tgbotapi.ModeMarkdown
+				if _, err := telegramBot.Send(photo); err == nil {
+					continue
+				}
+				// 封面格式不被 Telegram 接受时退回到文本消息
+			}
+
+			msg := tgbotapi.NewMessage(chatID, text)
+			msg.ParseMode = tgbotapi.ModeMarkdown
+			if _, err := telegramBot.Send(msg); err != nil {
+				log.Printf("向聊天 %d 发送新书通知失败: %v", chatID, err)
+			}
+		}
+	}
+}
+
+// sendNewItemsSummary 一次新增大量条目（例如扫描导入整个目录）时合并为一条通知
+func sendNewItemsSummary(telegramBot *tgbotapi.BotAPI, batch services.NewItemsBatch) {
+	text := formatNewItemsSummary(batch)
+	for _, chatID := range batch.ChatIDs {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		if _, err := telegramBot.Send(msg); err != nil {
+			log.Printf("向聊天 %d 发送新书通知失败: %v", chatID, err)
+		}
+	}
+}
+
+// formatNewItem 格式化单本新书的通知
+func formatNewItem(library models.LibraryInfo, item models.LibraryItem) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🆕 *%s* 新增:\n\n", escapeMarkdown(library.Name)))
+	sb.WriteString(fmt.Sprintf("📖 *%s*\n", escapeMarkdown(itemTitle(item))))
+	if author := item.AuthorNames(); author != "" {
+		sb.WriteString(fmt.Sprintf("✍️ 作者: %s\n", escapeMarkdown(author)))
+	}
+	if duration := item.Duration(); duration > 0 {
+		sb.WriteString(fmt.Sprintf("⏱ 时长: %s\n", formatSeconds(duration)))
+	}
+	return sb.String()
+}
+
+// formatNewItemsSummary 格式化合并的新书通知
+func formatNewItemsSummary(batch services.NewItemsBatch) string {
+	var sb strings.Builder
+	count := fmt.Sprintf("%d", len(batch.Items))
+	if batch.Truncated {
+		count = "超过 " + count
+	}
+	sb.WriteString(fmt.Sprintf("🆕 *%s* 新增了 %s 个条目:\n\n", escapeMarkdown(batch.Library.Name), count))
+
+	for i, item := range batch.Items {
+		if i >= notifySummaryLimit {
+			sb.WriteString(fmt.Sprintf("\n… 等共 %s 个", count))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("• %s", escapeMarkdown(itemTitle(item))))
+		if author := item.AuthorNames(); author != "" {
+			sb.WriteString(fmt.Sprintf(" — %s", escapeMarkdown(author)))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n发送 /search 搜索书名查看详情")
+	return sb.String()
+}
+
+// itemTitle 返回条目标题，没有元数据时退回使用相对路径
+func itemTitle(item models.LibraryItem) string {
+	if title := item.Title(); title != "" {
+		return title
+	}
+	return item.RelPath
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// 正在播放页面的自动刷新设置
+const (
+	// nowPlayingRefreshInterval 自动刷新的间隔
+	nowPlayingRefreshInterval = 15 * time.Second
+	// nowPlayingRefreshDuration 自动刷新持续的时间，之后需要重新开启
+	nowPlayingRefreshDuration = 5 * time.Minute
+)
+
+// nowPlayingRefresh 正在自动刷新的正在播放页面，收到同一聊天的任何消息或按钮时停止
+var nowPlayingRefresh = newRefreshRegistry()
+
+// refreshRegistry 记录每个聊天中正在进行的自动刷新，同一聊天同时只保留一个
+type refreshRegistry struct {
+	mu      sync.Mutex
+	nextID  int
+	running map[int64]refreshEntry
+}
+
+// refreshEntry 一个进行中的自动刷新
+type refreshEntry struct {
+	id     int
+	cancel context.CancelFunc
+}
+
+func newRefreshRegistry() *refreshRegistry {
+	return &refreshRegistry{running: make(map[int64]refreshEntry)}
+}
+
+// Start 开始聊天的自动刷新并停止之前的，返回的 ctx 在 Stop 被调用时取消
+// 自动刷新结束后必须调用返回的函数释放记录
+func (r *refreshRegistry) Start(parent context.Context, chatID int64) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	r.mu.Lock()
+	if previous, ok := r.running[chatID]; ok {
+		previous.cancel()
+	}
+	r.nextID++
+	id := r.nextID
+	r.running[chatID] = refreshEntry{id: id, cancel: cancel}
+	r.mu.Unlock()
+
+	return ctx, func() {
+		cancel()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// 之后开始的自动刷新已经替换了记录时不能删除
+		if entry, ok := r.running[chatID]; ok && entry.id == id {
+			delete(r.running, chatID)
+		}
+	}
+}
+
+// Stop 停止聊天中进行中的自动刷新
+func (r *refreshRegistry) Stop(chatID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.running[chatID]; ok {
+		entry.cancel()
+		delete(r.running, chatID)
+	}
+}
+
+// sendNowPlaying 显示正在播放页面
+func sendNowPlaying(req *bot_pkg.Request, serverService *services.ServerService) {
+	if err := showNowPlaying(req.Context(), req, serverService, false); err != nil {
+		req.Reply("❌ 获取播放会话失败: "+services.DescribeError(err), req.MainMenu())
+	}
+}
+
+// autoRefreshNowPlaying 每隔 nowPlayingRefreshInterval 刷新一次正在播放页面，持续 nowPlayingRefreshDuration
+// 用户发送任何消息或点击任何按钮时停止
+func autoRefreshNowPlaying(req *bot_pkg.Request, serverService *services.ServerService) {
+	ctx, stop := nowPlayingRefresh.Start(req.Context(), req.ChatID)
+	defer stop()
+
+	if err := showNowPlaying(ctx, req, serverService, true); err != nil {
+		req.Reply("❌ 获取播放会话失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	ticker := time.NewTicker(nowPlayingRefreshInterval)
+	defer ticker.Stop()
+	timeout := time.NewTimer(nowPlayingRefreshDuration)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := showNowPlaying(ctx, req, serverService, true); err != nil {
+				if ctx.Err() == nil {
+					log.Printf("自动刷新正在播放页面失败: %v", err)
+				}
+				return
+			}
+		case <-timeout.C:
+			showNowPlaying(ctx, req, serverService, false)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// showNowPlaying 获取播放会话并更新页面
+func showNowPlaying(ctx context.Context, req *bot_pkg.Request, serverService *services.ServerService, autoRefresh bool) error {
+	report, err := serverService.GetNowPlaying(ctx)
+	if err != nil {
+		return err
+	}
+	// 获取期间用户已经停止了自动刷新，不再覆盖新的页面
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	menu := bot_pkg.CreateNowPlayingMenu(report, req.Role >= bot_pkg.RoleAdmin, autoRefresh)
+	req.Reply(formatNowPlaying(report, autoRefresh, time.Now()), &menu)
+	return nil
+}
+
+// promptForSessionClose 要求确认关闭按钮对应的播放会话
+func promptForSessionClose(req *bot_pkg.Request, serverService *services.ServerService) {
+	sessionID := req.Data.Arg(0)
+	report, err := serverService.GetNowPlaying(req.Context())
+	if err != nil {
+		req.Reply("❌ 获取播放会话失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	for _, playing := range report.Active {
+		if playing.Session.ID != sessionID {
+			continue
+		}
+		text := fmt.Sprintf("确定要关闭 %s 正在播放的《%s》吗？\n\n%s\n\n关闭后该会话会从服务器删除，如果客户端仍在播放，可能需要重新开始播放才能继续同步进度",
+			playing.Username, playing.Session.Title(), formatSessionSync(playing, time.Now()))
+		menu := bot_pkg.CreateConfirmMenu(
+			bot_pkg.EncodeCallback(bot_pkg.ActionCloseSessionConfirm, sessionID),
+			bot_pkg.EncodeCallback(bot_pkg.ActionNowPlaying),
+		)
+		req.Reply(text, &menu)
+		return
+	}
+
+	// 会话在打开页面之后已经结束
+	sendNowPlaying(req, serverService)
+}
+
+// closeSession 关闭确认的播放会话并刷新正在播放页面
+func closeSession(req *bot_pkg.Request, serverService *services.ServerService) {
+	sessionID := req.Data.Arg(0)
+	if err := serverService.CloseSession(req.Context(), sessionID); err != nil {
+		req.Reply("❌ 关闭播放会话失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+	log.Printf("用户 %d 关闭了播放会话 %s", req.UserID, sessionID)
+
+	sendNowPlaying(req, serverService)
+}
+
+// formatNowPlaying 格式化正在播放页面
+func formatNowPlaying(report *models.NowPlayingReport, autoRefresh bool, now time.Time) string {
+	var sb strings.Builder
+	if len(report.Active) == 0 {
+		sb.WriteString("⏸ 当前没有进行中的播放会话\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("▶️ 正在播放（%d）\n", len(report.Active)))
+	}
+
+	for _, playing := range report.Active {
+		session := playing.Session
+		sb.WriteString(fmt.Sprintf("\n👤 %s\n", playing.Username))
+		sb.WriteString(fmt.Sprintf("📖 %s", session.Title()))
+		if session.DisplayAuthor != "" {
+			sb.WriteString(" — " + session.DisplayAuthor)
+		}
+		sb.WriteString("\n")
+		if playing.Chapter != nil && playing.Chapter.Title != "" {
+			sb.WriteString(fmt.Sprintf("📑 %s\n", playing.Chapter.Title))
+		}
+		sb.WriteString(fmt.Sprintf("⏱ %s\n", formatSessionPosition(session.CurrentTime, session.Duration)))
+		if session.DeviceInfo != nil {
+			if device := session.DeviceInfo.Device(); device != "" {
+				sb.WriteString(fmt.Sprintf("📱 %s\n", device))
+			}
+			if client := session.DeviceInfo.Client(); client != "" {
+				sb.WriteString(fmt.Sprintf("🧩 %s\n", client))
+			}
+		}
+		sb.WriteString(formatSessionSync(playing, now) + "\n")
+	}
+
+	if len(report.Recent) > 0 {
+		sb.WriteString("\n🕘 最近结束:\n")
+		for _, playing := range report.Recent {
+			updated := time.UnixMilli(playing.Session.UpdatedAt).Format("01-02 15:04")
+			sb.WriteString(fmt.Sprintf("• %s — %s（%s）\n", playing.Username, playing.Session.Title(), updated))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\n🔄 更新于 %s", now.Format("15:04:05")))
+	if autoRefresh {
+		sb.WriteString(fmt.Sprintf("，每 %d 秒自动刷新，点击任意按钮停止", int(nowPlayingRefreshInterval.Seconds())))
+	}
+	return sb.String()
+}
+
+// formatSessionPosition 格式化播放位置和总时长，例如 "1小时0分钟0秒 / 10小时0分钟0秒 (10%)"
+func formatSessionPosition(current, duration float64) string {
+	if duration <= 0 {
+		return formatSeconds(current)
+	}
+	return fmt.Sprintf("%s / %s (%.0f%%)", formatSeconds(current), formatSeconds(duration), current/duration*100)
+}
+
+// formatSessionSync 格式化会话最后一次同步进度的时间，长时间没有同步的会话会给出提示
+func formatSessionSync(playing models.NowPlaying, now time.Time) string {
+	if playing.Session.UpdatedAt == 0 {
+		return "🕒 尚未同步进度"
+	}
+	updated := time.UnixMilli(playing.Session.UpdatedAt)
+	if playing.Stale {
+		return fmt.Sprintf("⚠️ 已 %s 没有同步进度，可能是遗留的会话", services.FormatDuration(now.Sub(updated).Truncate(time.Minute)))
+	}
+	return fmt.Sprintf("🕒 同步于 %s", updated.Format("15:04:05"))
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// sendItemsInProgress 列出当前用户正在收听的图书，点击图书管理收听进度
+func sendItemsInProgress(req *bot_pkg.Request, serverService *services.ServerService) {
+	req.Reply("🎧 正在获取收听进度，请稍候...", nil)
+
+	items, err := serverService.ListItemsInProgress(req.Context())
+	if err != nil {
+		req.Reply("❌ 获取收听进度失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	text := "🎧 正在收听的图书\n\n点击图书查看或修改收听进度，也可以在图书详情中点击「🎧 收听进度」"
+	if len(items) == 0 {
+		text = "🎧 目前没有正在收听的图书\n\n发送 /search 搜索图书，在图书详情中点击「🎧 收听进度」可以设置播放位置"
+	}
+	menu := bot_pkg.CreateItemsInProgressMenu(items)
+	req.Reply(text, &menu)
+}
+
+// sendItemProgress 显示按钮对应图书的收听进度
+func sendItemProgress(req *bot_pkg.Request, serverService *services.ServerService) {
+	showItemProgress(req, req.Data.Arg(0), "", serverService)
+}
+
+// showItemProgress 显示图书的收听进度，notice 不为空时显示在最前面，用于报告刚完成的修改
+func showItemProgress(req *bot_pkg.Request, itemID, notice string, serverService *services.ServerService) {
+	progress, ok := getItemProgress(req, itemID, serverService)
+	if !ok {
+		return
+	}
+
+	text := formatItemProgress(progress)
+	if notice != "" {
+		text = notice + "\n\n" + text
+	}
+	menu := bot_pkg.CreateProgressMenu(progress)
+	req.Reply(text, &menu)
+}
+
+// sendProgressChapters 显示选择章节的菜单
+func sendProgressChapters(req *bot_pkg.Request, serverService *services.ServerService) {
+	progress, ok := getItemProgress(req, req.Data.Arg(0), serverService)
+	if !ok {
+		return
+	}
+
+	chapters := progress.Chapters()
+	pageCount := (len(chapters) + bot_pkg.ProgressChaptersPerPage - 1) / bot_pkg.ProgressChaptersPerPage
+	page, _ := req.Data.IntArg(1)
+	if page < 0 || page >= pageCount {
+		page = 0
+	}
+
+	text := fmt.Sprintf("📑 《%s》共 %d 章，点击章节跳到该章的开头：", progress.Item.Title(), len(chapters))
+	menu := bot_pkg.CreateProgressChaptersMenu(progress, page)
+	req.Reply(text, &menu)
+}
+
+// promptForProgressTime 提示用户输入要跳转到的播放位置
+func promptForProgressTime(req *bot_pkg.Request) {
+	itemID := req.Data.Arg(0)
+	conversations.Set(req.ChatID, bot_pkg.StateAwaitingProgressTime, itemID)
+	req.Reply("⌨️ 请输入要跳转到的播放位置，例如 1:02:03、62:03 或 1h2m，发送 /cancel 取消：", nil)
+}
+
+// seekToTimestamp 将图书的播放位置设置为用户输入的时间
+func seekToTimestamp(req *bot_pkg.Request, itemID, text string, serverService *services.ServerService) {
+	seconds, err := services.ParseTimestamp(text)
+	if err != nil {
+		// 保持等待输入的状态，用户可以直接重新输入
+		req.Reply(fmt.Sprintf("❌ %v\n\n请输入例如 1:02:03、62:03 或 1h2m 的播放位置，或发送 /cancel 取消", err), nil)
+		return
+	}
+	conversations.Clear(req.ChatID)
+
+	progress, ok := getItemProgress(req, itemID, serverService)
+	if !ok {
+		return
+	}
+	if duration := progress.Duration(); duration > 0 && seconds > duration {
+		req.Reply(fmt.Sprintf("❌ 播放位置超过了图书的总时长 %s", formatSeconds(duration)), nil)
+		conversations.Set(req.ChatID, bot_pkg.StateAwaitingProgressTime, itemID)
+		return
+	}
+
+	if err := serverService.SeekProgress(req.Context(), progress, seconds); err != nil {
+		replyProgressError(req, itemID, err)
+		return
+	}
+	log.Printf("用户 %d 将条目 %s 的播放位置设置为 %.0f 秒", req.UserID, itemID, seconds)
+
+	showItemProgress(req, itemID, "✅ 播放位置已设置为 "+formatSeconds(seconds), serverService)
+}
+
+// editProgress 执行收听进度菜单中的操作，重置进度需要先确认
+func editProgress(req *bot_pkg.Request, serverService *services.ServerService) {
+	itemID := req.Data.Arg(0)
+	op := req.Data.Arg(1)
+
+	if op == bot_pkg.ProgressOpReset {
+		progress, ok := getItemProgress(req, itemID, serverService)
+		if !ok {
+			return
+		}
+		text := fmt.Sprintf("确定要重置《%s》的收听进度吗？\n\n重置后该书会回到未开始的状态，当前位置 %s 将无法恢复",
+			progress.Item.Title(), formatSeconds(progress.CurrentTime()))
+		menu := bot_pkg.CreateConfirmMenu(
+			bot_pkg.EncodeCallback(bot_pkg.ActionProgressConfirm, itemID, op),
+			bot_pkg.EncodeCallback(bot_pkg.ActionProgress, itemID),
+		)
+		req.Reply(text, &menu)
+		return
+	}
+
+	applyProgressEdit(req, serverService)
+}
+
+// applyProgressEdit 执行按钮对应的收听进度操作，完成后显示修改后的进度
+// 不需要确认的操作由 editProgress 直接调用，重置进度在确认后调用
+func applyProgressEdit(req *bot_pkg.Request, serverService *services.ServerService) {
+	itemID := req.Data.Arg(0)
+	op := req.Data.Arg(1)
+
+	progress, ok := getItemProgress(req, itemID, serverService)
+	if !ok {
+		return
+	}
+
+	var notice string
+	var err error
+	switch op {
+	case bot_pkg.ProgressOpChapter:
+		index, ok := req.Data.IntArg(2)
+		chapters := progress.Chapters()
+		if !ok || index < 0 || index >= len(chapters) {
+			req.Reply("❌ 章节不存在，图书的章节可能已经变化", req.MainMenu())
+			return
+		}
+		chapter := chapters[index]
+		err = serverService.SeekProgress(req.Context(), progress, chapter.Start)
+		notice = fmt.Sprintf("✅ 已跳到第 %d 章「%s」", index+1, chapter.Title)
+	case bot_pkg.ProgressOpFinish:
+		err = serverService.SetProgressFinished(req.Context(), progress, true)
+		notice = "✅ 已标记为听完"
+	case bot_pkg.ProgressOpUnfinish:
+		err = serverService.SetProgressFinished(req.Context(), progress, false)
+		notice = "↩️ 已标记为未听完"
+	case bot_pkg.ProgressOpReset:
+		err = serverService.ResetProgress(req.Context(), progress)
+		notice = "🗑 收听进度已重置"
+	default:
+		req.Reply("❌ 未知的操作", req.MainMenu())
+		return
+	}
+	if err != nil {
+		replyProgressError(req, itemID, err)
+		return
+	}
+	log.Printf("用户 %d 修改了条目 %s 的收听进度: %s", req.UserID, itemID, op)
+
+	showItemProgress(req, itemID, notice, serverService)
+}
+
+// getItemProgress 获取图书的收听进度，失败或条目是播客时回复错误并返回 false
+func getItemProgress(req *bot_pkg.Request, itemID string, serverService *services.ServerService) (*models.ItemProgress, bool) {
+	progress, err := serverService.GetItemProgress(req.Context(), itemID)
+	if err != nil {
+		req.Reply("❌ 获取收听进度失败: "+services.DescribeError(err), req.MainMenu())
+		return nil, false
+	}
+	if progress.Item.Book == nil {
+		replyProgressError(req, itemID, services.ErrPodcastProgress)
+		return nil, false
+	}
+	return progress, true
+}
+
+// replyProgressError 回复修改收听进度失败的原因
+func replyProgressError(req *bot_pkg.Request, itemID string, err error) {
+	menu := bot_pkg.CreateItemResultMenu(itemID)
+	if errors.Is(err, services.ErrPodcastProgress) {
+		req.Reply("⚠️ "+err.Error(), &menu)
+		return
+	}
+	req.Reply("❌ 修改收听进度失败: "+services.DescribeError(err), &menu)
+}
+
+// formatItemProgress 格式化图书的收听进度
+func formatItemProgress(progress *models.ItemProgress) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🎧 《%s》的收听进度\n\n", progress.Item.Title()))
+
+	status := "📖 收听中"
+	switch {
+	case progress.Progress == nil:
+		status = "⚪ 未开始"
+	case progress.IsFinished():
+		status = "✅ 已听完"
+	}
+	sb.WriteString(fmt.Sprintf("状态: %s\n", status))
+	sb.WriteString(fmt.Sprintf("⏱ 位置: %s\n", formatSessionPosition(progress.CurrentTime(), progress.Duration())))
+
+	chapters := progress.Chapters()
+	if index := progress.CurrentChapter(); index >= 0 {
+		sb.WriteString(fmt.Sprintf("📑 章节: 第 %d/%d 章「%s」\n", index+1, len(chapters), chapters[index].Title))
+	} else if len(chapters) > 0 {
+		sb.WriteString(fmt.Sprintf("📑 章节: 共 %d 章\n", len(chapters)))
+	}
+
+	if p := progress.Progress; p != nil && p.LastUpdate > 0 {
+		sb.WriteString(fmt.Sprintf("🕒 最后收听: %s\n", time.Unix(p.LastUpdate/1000, 0).Format("2006-01-02 15:04:05")))
+	}
+	if p := progress.Progress; p != nil && p.IsFinished && p.FinishedAt > 0 {
+		sb.WriteString(fmt.Sprintf("🏁 听完于: %s\n", time.Unix(p.FinishedAt/1000, 0).Format("2006-01-02 15:04:05")))
+	}
+
+	sb.WriteString("\n修改后需要在播放器中重新打开图书才能从新的位置继续播放")
+	return sb.String()
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// newRouter 注册机器人的全部命令和按钮
+// 命令的注册顺序决定了 Telegram 命令菜单和帮助信息中的顺序
+// 听众只能搜索、查看详情和自己的统计，服务器、媒体库和播放会话信息需要运维角色，用户管理需要管理员角色
+// 已绑定 Audiobookshelf 账户的用户以自己的账户执行操作
+// events 为 nil 时扫描媒体库不显示进度
+func newRouter(resolve bot_pkg.RoleResolver, accountService *services.AccountService, notifications *services.NotificationService, events *api.EventStream) *bot_pkg.Router {
+	router := bot_pkg.NewRouter(resolve)
+	service := func(req *bot_pkg.Request) *services.ServerService {
+		return accountService.ServiceFor(req.UserID)
+	}
+	// ownAccount 要求用户以自己的账户执行操作，purpose 说明需要绑定才能进行的操作
+	// 用于修改个人的收听进度和按账户的权限下载音频
+	ownAccount := func(purpose string, handler func(*bot_pkg.Request)) func(*bot_pkg.Request) {
+		return func(req *bot_pkg.Request) {
+			if !hasOwnAccount(req, accountService) {
+				sendLinkRequired(req, purpose)
+				return
+			}
+			handler(req)
+		}
+	}
+
+	router.Handle(bot_pkg.Command{
+		Name:        "start",
+		Description: "显示主菜单",
+		Role:        bot_pkg.RoleListener,
+		Action:      bot_pkg.ActionMainMenu,
+		Handler:     sendMainMenu,
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "serverinfo",
+		Description: "获取服务器信息",
+		Role:        bot_pkg.RoleOperator,
+		MenuLabel:   "📊 服务器信息",
+		MenuRow:     0,
+		Action:      bot_pkg.ActionServerInfo,
+		Handler: func(req *bot_pkg.Request) {
+			sendServerInfo(req, service(req))
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "nowplaying",
+		Description: "查看正在播放的会话",
+		Role:        bot_pkg.RoleOperator,
+		MenuLabel:   "▶️ 正在播放",
+		MenuRow:     0,
+		Action:      bot_pkg.ActionNowPlaying,
+		Handler: func(req *bot_pkg.Request) {
+			sendNowPlaying(req, service(req))
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "users",
+		Description: "获取用户列表",
+		Role:        bot_pkg.RoleAdmin,
+		MenuLabel:   "👥 用户列表",
+		MenuRow:     1,
+		Action:      bot_pkg.ActionUsers,
+		Handler: func(req *bot_pkg.Request) {
+			sendUsersInfo(req, service(req))
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "libraries",
+		Description: "获取媒体库列表",
+		Role:        bot_pkg.RoleOperator,
+		MenuLabel:   "📚 媒体库",
+		MenuRow:     1,
+		Action:      bot_pkg.ActionLibraries,
+		Handler: func(req *bot_pkg.Request) {
+			sendLibrariesList(req, service(req))
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "search",
+		Description: "搜索图书",
+		Usage:       "[关键词]",
+		Role:        bot_pkg.RoleListener,
+		MenuLabel:   "🔍 搜索图书",
+		MenuRow:     2,
+		Action:      bot_pkg.ActionSearch,
+		Handler: func(req *bot_pkg.Request) {
+			// 命令后直接带关键词时立即搜索，否则等待用户输入
+			if req.Args != "" {
+				performBookSearch(req, req.Args, service(req))
+				return
+			}
+			promptForSearchTerm(req)
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "mystats",
+		Description: "获取我的统计信息",
+		Role:        bot_pkg.RoleListener,
+		MenuLabel:   "📈 我的统计",
+		MenuRow:     2,
+		Action:      bot_pkg.ActionMyStats,
+		Handler: func(req *bot_pkg.Request) {
+			if !hasOwnAccount(req, accountService) {
+				sendLinkRequired(req, "查看个人统计")
+				return
+			}
+			sendMyStats(req, service(req))
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "progress",
+		Description: "查看和修改我的收听进度",
+		Role:        bot_pkg.RoleListener,
+		MenuLabel:   "🎧 收听进度",
+		MenuRow:     4,
+		Action:      bot_pkg.ActionItemsInProgress,
+		Handler: ownAccount("管理收听进度", func(req *bot_pkg.Request) {
+			sendItemsInProgress(req, service(req))
+		}),
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "userstats",
+		Description: "获取指定用户的统计信息",
+		Usage:       "<用户名>",
+		Role:        bot_pkg.RoleAdmin,
+		Handler: func(req *bot_pkg.Request) {
+			if req.Args == "" {
+				promptForUsername(req)
+				return
+			}
+			sendUserStats(req, req.Args, service(req))
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "link",
+		Description: "绑定 Audiobookshelf 账户",
+		Usage:       "[API Token]",
+		Role:        bot_pkg.RoleListener,
+		MenuLabel:   "🔗 绑定账户",
+		MenuRow:     3,
+		Action:      bot_pkg.ActionLink,
+		Handler: func(req *bot_pkg.Request) {
+			if req.Args != "" {
+				linkWithToken(req, req.Args, accountService)
+				return
+			}
+			sendLinkStatus(req, accountService)
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "login",
+		Description: "使用用户名和密码绑定账户",
+		Usage:       "[用户名] [密码]",
+		Role:        bot_pkg.RoleListener,
+		Handler: func(req *bot_pkg.Request) {
+			promptForLogin(req, accountService)
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "unlink",
+		Description: "解除 Audiobookshelf 账户绑定",
+		Role:        bot_pkg.RoleListener,
+		Action:      bot_pkg.ActionUnlink,
+		Handler: func(req *bot_pkg.Request) {
+			unlinkAccount(req, accountService)
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "subscribe",
+		Description: "订阅媒体库的新书通知",
+		Usage:       "[媒体库]",
+		Role:        bot_pkg.RoleListener,
+		MenuLabel:   "🔔 新书通知",
+		MenuRow:     3,
+		Action:      bot_pkg.ActionSubscriptions,
+		Handler: func(req *bot_pkg.Request) {
+			if req.Args != "" {
+				subscribeByName(req, req.Args, true, service(req), notifications)
+				return
+			}
+			sendSubscriptions(req, service(req), notifications)
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "unsubscribe",
+		Description: "取消订阅媒体库的新书通知",
+		Usage:       "[媒体库]",
+		Role:        bot_pkg.RoleListener,
+		Handler: func(req *bot_pkg.Request) {
+			if req.Args != "" {
+				subscribeByName(req, req.Args, false, service(req), notifications)
+				return
+			}
+			sendSubscriptions(req, service(req), notifications)
+		},
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "cancel",
+		Description: "取消当前操作",
+		Role:        bot_pkg.RoleListener,
+		Handler:     cancelConversation,
+	})
+	router.Handle(bot_pkg.Command{
+		Name:        "help",
+		Description: "显示帮助信息",
+		Role:        bot_pkg.RoleListener,
+		MenuLabel:   "❓ 帮助",
+		MenuRow:     4,
+		Action:      bot_pkg.ActionHelp,
+		Handler:     sendHelpMessage,
+	})
+
+	// 搜索结果和详情页上的按钮
+	router.HandleCallback(bot_pkg.ActionNoop, bot_pkg.RoleListener, func(req *bot_pkg.Request) {
+		// 页码指示按钮，无需处理
+	})
+	router.HandleCallback(bot_pkg.ActionSearchOverview, bot_pkg.RoleListener, backToSearchOverview)
+	router.HandleCallback(bot_pkg.ActionSearchResults, bot_pkg.RoleListener, func(req *bot_pkg.Request) {
+		backToSearchResults(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionItemDetail, bot_pkg.RoleListener, func(req *bot_pkg.Request) {
+		sendItemDetail(req, req.Data.Arg(0), req.Data.Arg(1), hasOwnAccount(req, accountService), service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionSearchPage, bot_pkg.RoleListener, func(req *bot_pkg.Request) {
+		changeSearchPage(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionSearchCategory, bot_pkg.RoleListener, func(req *bot_pkg.Request) {
+		openSearchCategory(req, service(req))
+	})
+
+	// 收听进度页面中的按钮
+	router.HandleCallback(bot_pkg.ActionProgress, bot_pkg.RoleListener, ownAccount("管理收听进度", func(req *bot_pkg.Request) {
+		sendItemProgress(req, service(req))
+	}))
+	router.HandleCallback(bot_pkg.ActionProgressChapters, bot_pkg.RoleListener, ownAccount("管理收听进度", func(req *bot_pkg.Request) {
+		sendProgressChapters(req, service(req))
+	}))
+	router.HandleCallback(bot_pkg.ActionProgressInput, bot_pkg.RoleListener, ownAccount("管理收听进度", promptForProgressTime))
+	router.HandleCallback(bot_pkg.ActionProgressEdit, bot_pkg.RoleListener, ownAccount("管理收听进度", func(req *bot_pkg.Request) {
+		editProgress(req, service(req))
+	}))
+	router.HandleCallback(bot_pkg.ActionProgressConfirm, bot_pkg.RoleListener, ownAccount("管理收听进度", func(req *bot_pkg.Request) {
+		applyProgressEdit(req, service(req))
+	}))
+
+	// 图书详情中的下载按钮，是否允许下载取决于账户的下载权限
+	router.HandleCallback(bot_pkg.ActionDownload, bot_pkg.RoleListener, ownAccount("下载音频", func(req *bot_pkg.Request) {
+		promptForDownload(req, service(req))
+	}))
+	router.HandleCallback(bot_pkg.ActionDownloadConfirm, bot_pkg.RoleListener, ownAccount("下载音频", func(req *bot_pkg.Request) {
+		sendItemAudio(req, service(req))
+	}))
+
+	// 媒体库列表和详情中的按钮
+	router.HandleCallback(bot_pkg.ActionLibraryDetail, bot_pkg.RoleOperator, func(req *bot_pkg.Request) {
+		sendLibraryDetail(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionLibraryItems, bot_pkg.RoleOperator, func(req *bot_pkg.Request) {
+		openLibraryItems(req, service(req))
+	})
+
+	// 媒体库列表和条目详情中的扫描按钮
+	router.HandleCallback(bot_pkg.ActionScanLibrary, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		startLibraryScan(req, service(req), events)
+	})
+	router.HandleCallback(bot_pkg.ActionScanItem, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		rescanItem(req, service(req))
+	})
+
+	// 正在播放页面中的按钮，关闭会话需要管理员角色
+	router.HandleCallback(bot_pkg.ActionNowPlayingAuto, bot_pkg.RoleOperator, func(req *bot_pkg.Request) {
+		autoRefreshNowPlaying(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionCloseSession, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		promptForSessionClose(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionCloseSessionConfirm, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		closeSession(req, service(req))
+	})
+
+	// 用户列表中的按钮
+	router.HandleCallback(bot_pkg.ActionUserStats, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		sendUserStats(req, req.Data.Arg(0), service(req))
+	})
+
+	// 用户管理页面中的按钮
+	router.HandleCallback(bot_pkg.ActionUserAdmin, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		sendUserAdmin(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionUserCreate, bot_pkg.RoleAdmin, promptForNewUser)
+	router.HandleCallback(bot_pkg.ActionUserPassword, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		promptForUserPassword(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionUserTypes, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		sendUserTypes(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionUserPermissions, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		sendUserPermissions(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionUserLibraries, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		sendUserLibraries(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionUserTags, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		sendUserTags(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionUserEdit, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		editUser(req, service(req))
+	})
+	router.HandleCallback(bot_pkg.ActionUserConfirm, bot_pkg.RoleAdmin, func(req *bot_pkg.Request) {
+		confirmUserEdit(req, service(req))
+	})
+
+	// 新书通知订阅菜单中的按钮
+	router.HandleCallback(bot_pkg.ActionToggleSubscription, bot_pkg.RoleListener, func(req *bot_pkg.Request) {
+		toggleSubscription(req, service(req), notifications)
+	})
+
+	router.HandleText(func(req *bot_pkg.Request) {
+		handleTextMessage(req, accountService)
+	})
+
+	return router
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// 扫描进度设置
+const (
+	// scanProgressInterval 两次编辑进度消息之间的最短间隔，避免触发 Telegram 的频率限制
+	scanProgressInterval = 3 * time.Second
+	// scanTrackTimeout 扫描超过该时间仍未结束时停止跟踪，扫描本身在服务器上继续进行
+	scanTrackTimeout = 30 * time.Minute
+)
+
+// startLibraryScan 开始扫描按钮对应的媒体库，并在进度消息中持续显示扫描进度
+// 进度来自实时事件，事件流不可用时只开始扫描，不显示进度
+func startLibraryScan(req *bot_pkg.Request, serverService *services.ServerService, events *api.EventStream) {
+	libraryID := req.Data.Arg(0)
+	force := req.Data.Arg(1) == bot_pkg.ScanForce
+
+	name, err := serverService.GetLibraryName(req.Context(), libraryID)
+	if err != nil {
+		req.Reply("❌ 获取媒体库信息失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+	progress := services.NewScanProgress(libraryID, name)
+	menu := bot_pkg.CreateScanMenu(libraryID)
+
+	// 先订阅再开始扫描，避免错过扫描开始后立即推送的事件
+	var scanEvents <-chan api.Event
+	if events != nil && events.Connected() {
+		var cancel func()
+		scanEvents, cancel = events.Subscribe(256, services.ScanEvents...)
+		defer cancel()
+	}
+
+	if err := serverService.ScanLibrary(req.Context(), libraryID, force); err != nil {
+		req.Reply("❌ 开始扫描失败: "+services.DescribeError(err), &menu)
+		return
+	}
+	log.Printf("用户 %d 开始扫描媒体库 %s (force=%t)", req.UserID, name, force)
+
+	if scanEvents == nil {
+		req.Reply(fmt.Sprintf("🔄 已开始扫描媒体库「%s」\n\n实时事件不可用，无法显示扫描进度，扫描完成后可在媒体库列表中查看结果", name), &menu)
+		return
+	}
+
+	started := time.Now()
+	req.Reply(formatScanProgress(progress, force, time.Since(started)), &menu)
+
+	ticker := time.NewTicker(scanProgressInterval)
+	defer ticker.Stop()
+	timeout := time.NewTimer(scanTrackTimeout)
+	defer timeout.Stop()
+
+	changed := false
+	for {
+		select {
+		case event, ok := <-scanEvents:
+			if !ok {
+				return
+			}
+			if event.Name == api.EventDisconnected {
+				req.Reply(formatScanProgress(progress, force, time.Since(started))+"\n\n⚠️ 与服务器的实时连接已断开，停止跟踪扫描进度", &menu)
+				return
+			}
+			if !progress.Apply(event) {
+				continue
+			}
+			if progress.Finished {
+				serverService.InvalidateLibrariesCache()
+				req.Reply(formatScanResult(progress, time.Since(started)), &menu)
+				return
+			}
+			changed = true
+		case <-ticker.C:
+			if changed {
+				req.Reply(formatScanProgress(progress, force, time.Since(started)), &menu)
+				changed = false
+			}
+		case <-timeout.C:
+			req.Reply(formatScanProgress(progress, force, time.Since(started))+"\n\n⚠️ 扫描时间过长，停止跟踪进度，扫描仍在服务器上进行", &menu)
+			return
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+// rescanItem 重新扫描按钮对应的条目并回复结果
+func rescanItem(req *bot_pkg.Request, serverService *services.ServerService) {
+	itemID := req.Data.Arg(0)
+	req.Reply("🔄 正在重新扫描条目，请稍候...", nil)
+
+	result, err := serverService.ScanItem(req.Context(), itemID)
+	if err != nil {
+		req.Reply("❌ 重新扫描失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	var text string
+	switch result {
+	case api.ItemScanUpdated:
+		text = "✅ 扫描完成，条目信息已更新"
+	case api.ItemScanAdded:
+		text = "✅ 扫描完成，条目已添加"
+	case api.ItemScanRemoved:
+		text = "⚠️ 扫描完成，条目的文件已不存在，条目已被标记为缺失"
+	case api.ItemScanUpToDate, api.ItemScanNothing:
+		text = "✅ 扫描完成，条目没有变化"
+	default:
+		text = "✅ 扫描完成: " + result
+	}
+
+	menu := bot_pkg.CreateItemResultMenu(itemID)
+	req.Reply(text, &menu)
+}
+
+// formatScanProgress 格式化扫描进行中的进度消息
+func formatScanProgress(progress *services.ScanProgress, force bool, elapsed time.Duration) string {
+	var sb strings.Builder
+	mode := ""
+	if force {
+		mode = "（强制）"
+	}
+	sb.WriteString(fmt.Sprintf("🔄 正在扫描媒体库「%s」%s\n\n", progress.LibraryName, mode))
+	if progress.Started {
+		sb.WriteString(fmt.Sprintf("➕ 已新增: %d\n", progress.Added))
+		sb.WriteString(fmt.Sprintf("✏️ 已更新: %d\n", progress.Updated))
+		sb.WriteString(fmt.Sprintf("❓ 已缺失: %d\n", progress.Missing))
+	} else {
+		sb.WriteString("⏳ 等待服务器开始扫描...\n")
+	}
+	sb.WriteString(fmt.Sprintf("⏱ 已用时: %s", services.FormatDuration(elapsed)))
+	return sb.String()
+}
+
+// formatScanResult 格式化扫描结束后的结果
+// 服务器给出统计时以服务器为准，否则使用扫描过程中收到的事件数量
+func formatScanResult(progress *services.ScanProgress, elapsed time.Duration) string {
+	var sb strings.Builder
+	if progress.Error != "" {
+		sb.WriteString(fmt.Sprintf("❌ 媒体库「%s」扫描失败\n\n%s\n", progress.LibraryName, progress.Error))
+		sb.WriteString(fmt.Sprintf("\n⏱ 用时: %s", services.FormatDuration(elapsed)))
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("✅ 媒体库「%s」扫描完成\n\n", progress.LibraryName))
+	added, updated, missing := progress.Added, progress.Updated, progress.Missing
+	if results := progress.Results; results != nil {
+		added, updated, missing = results.Added, results.Updated, results.Missing
+	}
+	sb.WriteString(fmt.Sprintf("➕ 新增: %d\n", added))
+	sb.WriteString(fmt.Sprintf("✏️ 更新: %d\n", updated))
+	sb.WriteString(fmt.Sprintf("❓ 缺失: %d\n", missing))
+	if progress.Results == nil && progress.Summary != "" {
+		sb.WriteString(fmt.Sprintf("📋 %s\n", progress.Summary))
+	}
+	sb.WriteString(fmt.Sprintf("\n⏱ 用时: %s", services.FormatDuration(elapsed)))
+	return sb.String()
+}
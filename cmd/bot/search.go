@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// 搜索结果分页设置
+const (
+	// searchPageSize 每页显示的条目数量
+	searchPageSize = 5
+	// searchCacheTTL 搜索结果的有效期，过期后翻页需要重新搜索
+	searchCacheTTL = 30 * time.Minute
+)
+
+// searchCache 按聊天缓存最近一次的搜索结果，用于翻页和从详情页返回
+var searchCache = bot_pkg.NewSearchCache(searchCacheTTL)
+
+// promptForSearchTerm 提示用户输入搜索词
+func promptForSearchTerm(req *bot_pkg.Request) {
+	conversations.Set(req.ChatID, bot_pkg.StateAwaitingSearchTerm, "")
+
+	menu := bot_pkg.CreateSearchMenu()
+	req.Reply("🔍 请输入您要搜索的图书名称、作者或其他关键词（发送 /cancel 取消）：", &menu)
+}
+
+// performBookSearch 执行搜索，搜索范围包括图书、播客、作者、系列、标签和朗读者
+func performBookSearch(req *bot_pkg.Request, searchTerm string, serverService *services.ServerService) {
+	// 添加调试日志
+	log.Printf("执行图书搜索: %s", searchTerm)
+
+	// 调用搜索服务时不指定特定的媒体库，让服务自行处理所有媒体库的搜索
+	results, err := serverService.Search(req.Context(), searchTerm)
+	if err != nil {
+		log.Printf("搜索出错: %v", err)
+		req.Reply("❌ 搜索出错: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	result := searchCache.Put(req.ChatID, searchTerm, results)
+	// 只搜索到图书和播客时直接显示条目列表，否则先显示按类别分组的概览
+	if !results.HasCategories() {
+		showSearchPage(req, result, 0, serverService)
+		return
+	}
+	showSearchOverview(req, result)
+}
+
+// showSearchOverview 显示按类别分组的搜索结果
+func showSearchOverview(req *bot_pkg.Request, result *bot_pkg.SearchResult) {
+	menu := bot_pkg.CreateSearchOverviewMenu(result.ID, result.Results)
+	req.ReplyMarkdown(formatSearchOverview(result.Term, result.Results), &menu)
+}
+
+// showSearchPage 显示当前条目列表的指定页
+func showSearchPage(req *bot_pkg.Request, result *bot_pkg.SearchResult, page int, serverService *services.ServerService) {
+	items, offset, page := result.Page(page, searchPageSize)
+	searchCache.SetPage(req.ChatID, result.ID, page)
+
+	title := result.ListTitle
+	if title == "" {
+		title = fmt.Sprintf("🔎 搜索 \"%s\" 的结果:", result.Term)
+	}
+	response := formatSearchResults(req.Context(), title, items, offset, len(result.Items), serverService)
+
+	withOverview := result.Results.HasCategories()
+	switch {
+	case len(items) > 0:
+		menu := bot_pkg.CreateSearchResultsMenu(result.ID, items, offset, page, result.PageCount(searchPageSize), withOverview)
+		req.ReplyMarkdown(response, &menu)
+	case withOverview:
+		menu := bot_pkg.CreateSearchResultsMenu(result.ID, nil, 0, 0, 1, true)
+		req.ReplyMarkdown(response, &menu)
+	default:
+		req.ReplyMarkdown(response, req.MainMenu())
+	}
+}
+
+// sendSearchExpired 提示搜索结果已过期，或按钮所在的消息不是最近一次搜索的结果
+func sendSearchExpired(req *bot_pkg.Request) {
+	req.Reply("⌛ 搜索结果已过期，请重新搜索", req.MainMenu())
+}
+
+// backToSearchOverview 返回按类别分组的搜索概览，搜索 ID 来自回调数据
+func backToSearchOverview(req *bot_pkg.Request) {
+	result, ok := searchCache.Get(req.ChatID, req.Data.Arg(0))
+	if !ok {
+		sendSearchExpired(req)
+		return
+	}
+
+	showSearchOverview(req, result)
+}
+
+// openSearchCategory 打开搜索结果中的某个作者、系列、标签或朗读者，列出其下的条目
+func openSearchCategory(req *bot_pkg.Request, serverService *services.ServerService) {
+	searchID := req.Data.Arg(0)
+	category := req.Data.Arg(1)
+	index, ok := req.Data.IntArg(2)
+	if !ok || index < 0 {
+		return
+	}
+
+	result, ok := searchCache.Get(req.ChatID, searchID)
+	if !ok {
+		sendSearchExpired(req)
+		return
+	}
+	results := result.Results
+	ctx := req.Context()
+
+	var title string
+	var list func() (*services.FilteredItems, error)
+	switch {
+	case category == bot_pkg.SearchCategoryItems:
+		updated, ok := searchCache.ShowList(req.ChatID, searchID, "", results.Items())
+		if !ok {
+			sendSearchExpired(req)
+			return
+		}
+		showSearchPage(req, updated, 0, serverService)
+		return
+	case category == bot_pkg.SearchCategoryAuthors && index < len(results.Authors):
+		author := results.Authors[index]
+		title = "✍️ 作者: " + author.Name
+		list = func() (*services.FilteredItems, error) { return serverService.ListAuthorItems(ctx, author) }
+	case category == bot_pkg.SearchCategorySeries && index < len(results.Series):
+		series := results.Series[index].Series
+		title = "📖 系列: " + series.Name
+		list = func() (*services.FilteredItems, error) { return serverService.ListSeriesItems(ctx, series) }
+	case category == bot_pkg.SearchCategoryTags && index < len(results.Tags):
+		tag := results.Tags[index]
+		title = "🏷 标签: " + tag.Name
+		list = func() (*services.FilteredItems, error) { return serverService.ListTagItems(ctx, tag) }
+	case category == bot_pkg.SearchCategoryNarrators && index < len(results.Narrators):
+		narrator := results.Narrators[index]
+		title = "🎙 朗读者: " + narrator.Name
+		list = func() (*services.FilteredItems, error) { return serverService.ListNarratorItems(ctx, narrator) }
+	default:
+		// 下标超出范围，搜索 ID 相同时不应出现
+		sendSearchExpired(req)
+		return
+	}
+
+	req.Reply("📚 正在获取图书列表，请稍候...", nil)
+	filtered, err := list()
+	if err != nil {
+		log.Printf("获取分类条目失败: %v", err)
+		menu := bot_pkg.CreateSearchResultsMenu(searchID, nil, 0, 0, 1, true)
+		req.Reply("❌ 获取图书列表失败: "+services.DescribeError(err), &menu)
+		return
+	}
+
+	if filtered.Total > len(filtered.Items) {
+		title += fmt.Sprintf(" (共 %d 本，仅显示前 %d 本)", filtered.Total, len(filtered.Items))
+	}
+	updated, ok := searchCache.ShowList(req.ChatID, searchID, title, filtered.Items)
+	if !ok {
+		sendSearchExpired(req)
+		return
+	}
+	showSearchPage(req, updated, 0, serverService)
+}
+
+// changeSearchPage 在原消息上翻到搜索结果的指定页，搜索 ID 和页码来自回调数据
+func changeSearchPage(req *bot_pkg.Request, serverService *services.ServerService) {
+	page, ok := req.Data.IntArg(1)
+	if !ok {
+		return
+	}
+
+	result, ok := searchCache.Get(req.ChatID, req.Data.Arg(0))
+	if !ok {
+		sendSearchExpired(req)
+		return
+	}
+
+	showSearchPage(req, result, page, serverService)
+}
+
+// backToSearchResults 从详情页返回搜索结果中最后浏览的一页，搜索 ID 来自回调数据
+func backToSearchResults(req *bot_pkg.Request, serverService *services.ServerService) {
+	result, ok := searchCache.Get(req.ChatID, req.Data.Arg(0))
+	if !ok {
+		sendSearchExpired(req)
+		return
+	}
+
+	// 详情页可能是图片消息，Reply 会删除后重新发送结果列表
+	showSearchPage(req, result, result.CurrentPage, serverService)
+}
+
+// formatSearchOverview 格式化按类别分组的搜索结果
+func formatSearchOverview(searchTerm string, results *models.SearchResults) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔎 搜索 \"%s\" 的结果:\n\n", escapeMarkdown(searchTerm)))
+
+	if len(results.Books) > 0 {
+		sb.WriteString(fmt.Sprintf("📚 *图书:* %d 本\n", len(results.Books)))
+	}
+	if len(results.Podcasts) > 0 {
+		sb.WriteString(fmt.Sprintf("📻 *播客:* %d 个\n", len(results.Podcasts)))
+	}
+
+	var names []string
+	for _, author := range results.Authors {
+		names = append(names, author.Name)
+	}
+	writeCategoryLine(&sb, "✍️ *作者:*", names)
+
+	names = names[:0]
+	for _, series := range results.Series {
+		names = append(names, series.Series.Name)
+	}
+	writeCategoryLine(&sb, "📖 *系列:*", names)
+
+	names = names[:0]
+	for _, tag := range results.Tags {
+		names = append(names, tag.Name)
+	}
+	writeCategoryLine(&sb, "🏷 *标签:*", names)
+
+	names = names[:0]
+	for _, narrator := range results.Narrators {
+		names = append(names, narrator.Name)
+	}
+	writeCategoryLine(&sb, "🎙 *朗读者:*", names)
+
+	sb.WriteString("\n点击下方按钮查看对应的图书")
+	return sb.String()
+}
+
+// writeCategoryLine 输出一个类别的名称列表，超出按钮数量的部分只显示数量
+func writeCategoryLine(sb *strings.Builder, label string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	shown := names
+	if len(shown) > bot_pkg.MaxCategoryButtons {
+		shown = shown[:bot_pkg.MaxCategoryButtons]
+	}
+	escaped := make([]string, len(shown))
+	for i, name := range shown {
+		escaped[i] = escapeMarkdown(name)
+	}
+
+	sb.WriteString(fmt.Sprintf("%s %s", label, strings.Join(escaped, ", ")))
+	if len(names) > len(shown) {
+		sb.WriteString(fmt.Sprintf(" 等 %d 个", len(names)))
+	}
+	sb.WriteString("\n")
+}
+
+// formatSearchResults 格式化条目列表的一页，offset 为本页第一本书在全部结果中的序号
+func formatSearchResults(ctx context.Context, title string, books []models.LibraryItem, offset, total int, serverService *services.ServerService) string {
+	var sb strings.Builder
+	sb.WriteString(escapeMarkdown(title) + "\n\n")
+
+	if total == 0 {
+		sb.WriteString("未找到相关书籍。\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("*📚 找到 %d 个结果:*\n", total))
+	for i, book := range books {
+		// 获取媒体库名称
+		libraryName, err := serverService.GetLibraryName(ctx, book.LibraryID)
+		if err != nil {
+			libraryName = "未知媒体库"
+		}
+
+		// 没有元数据时退回使用相对路径作为标题
+		title := book.Title()
+		if title == "" {
+			title = book.RelPath
+		}
+		sb.WriteString(fmt.Sprintf("%d. *%s*\n", offset+i+1, escapeMarkdown(title)))
+		if author := book.AuthorNames(); author != "" {
+			sb.WriteString(fmt.Sprintf("  ✍️ 作者: %s\n", escapeMarkdown(author)))
+		}
+		if book.Book != nil {
+			if narrator := book.Book.Metadata.NarratorNames(); narrator != "" {
+				sb.WriteString(fmt.Sprintf("  🎙 朗读: %s\n", escapeMarkdown(narrator)))
+			}
+			if series := book.Book.Metadata.SeriesNames(); series != "" {
+				sb.WriteString(fmt.Sprintf("  📚 系列: %s\n", escapeMarkdown(series)))
+			}
+		}
+		if duration := book.Duration(); duration > 0 {
+			sb.WriteString(fmt.Sprintf("  ⏱ 时长: %s\n", services.FormatDuration(time.Duration(duration)*time.Second)))
+		}
+		// 格式化添加时间 - 正确处理毫秒级时间戳
+		addedTime := time.Unix(book.AddedAt/1000, 0).Format("2006-01-02 15:04:05")
+		sb.WriteString(fmt.Sprintf("  📁 媒体库: %s\n  💾 大小: %s\n  ⏳ 添加时间: %s\n\n",
+			escapeMarkdown(libraryName),
+			services.FormatBytes(book.Size),
+			addedTime))
+	}
+
+	return sb.String()
+}
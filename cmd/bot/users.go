@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	bot_pkg "github.com/Heathcliff-third-space/AudiobookshelfManager/internal/bot"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/services"
+)
+
+// sendUserAdmin 显示按钮对应用户的管理页面
+func sendUserAdmin(req *bot_pkg.Request, serverService *services.ServerService) {
+	user, ok := getUserForAdmin(req, req.Data.Arg(0), serverService)
+	if !ok {
+		return
+	}
+	showUserAdmin(req, user, "", serverService)
+}
+
+// showUserAdmin 显示用户的管理页面，notice 不为空时显示在页面顶部，用于提示上一步操作的结果
+func showUserAdmin(req *bot_pkg.Request, user *models.UserInfo, notice string, serverService *services.ServerService) {
+	text := formatUserAdmin(serverService.GetUserView(req.Context(), user))
+	if notice != "" {
+		text = notice + "\n\n" + text
+	}
+	menu := bot_pkg.CreateUserAdminMenu(user)
+	req.ReplyMarkdown(text, &menu)
+}
+
+// sendUserTypes 显示修改用户类型的菜单
+func sendUserTypes(req *bot_pkg.Request, serverService *services.ServerService) {
+	user, ok := getUserForAdmin(req, req.Data.Arg(0), serverService)
+	if !ok {
+		return
+	}
+
+	menu := bot_pkg.CreateUserTypeMenu(user)
+	req.Reply(fmt.Sprintf("👤 用户 %s 当前的类型为 %s，请选择新的类型：", user.Username, models.RoleBadge(user.Type)), &menu)
+}
+
+// sendUserPermissions 显示用户权限菜单
+func sendUserPermissions(req *bot_pkg.Request, serverService *services.ServerService) {
+	user, ok := getUserForAdmin(req, req.Data.Arg(0), serverService)
+	if !ok {
+		return
+	}
+	showUserPermissions(req, user)
+}
+
+// showUserPermissions 显示用户权限菜单
+func showUserPermissions(req *bot_pkg.Request, user *models.UserInfo) {
+	menu := bot_pkg.CreateUserPermissionsMenu(user)
+	req.Reply(fmt.Sprintf("🛡 用户 %s 的权限\n\n点击按钮开启（✅）或关闭（❌）对应的权限：", user.Username), &menu)
+}
+
+// sendUserLibraries 显示用户媒体库访问菜单
+func sendUserLibraries(req *bot_pkg.Request, serverService *services.ServerService) {
+	user, ok := getUserForAdmin(req, req.Data.Arg(0), serverService)
+	if !ok {
+		return
+	}
+	showUserLibraries(req, user, serverService)
+}
+
+// showUserLibraries 显示用户媒体库访问菜单
+func showUserLibraries(req *bot_pkg.Request, user *models.UserInfo, serverService *services.ServerService) {
+	libraries, err := serverService.ListLibraries(req.Context())
+	if err != nil {
+		req.Reply("❌ 获取媒体库列表失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	menu := bot_pkg.CreateUserLibrariesMenu(user, libraries)
+	req.Reply(fmt.Sprintf("📚 用户 %s 可以访问的媒体库\n\n关闭「所有媒体库」后，点击媒体库允许（✅）或禁止（➕）访问：", user.Username), &menu)
+}
+
+// sendUserTags 显示用户标签访问菜单
+func sendUserTags(req *bot_pkg.Request, serverService *services.ServerService) {
+	user, ok := getUserForAdmin(req, req.Data.Arg(0), serverService)
+	if !ok {
+		return
+	}
+	showUserTags(req, user, serverService)
+}
+
+// showUserTags 显示用户标签访问菜单
+func showUserTags(req *bot_pkg.Request, user *models.UserInfo, serverService *services.ServerService) {
+	tags, err := serverService.ListTags(req.Context())
+	if err != nil {
+		req.Reply("❌ 获取标签列表失败: "+services.DescribeError(err), req.MainMenu())
+		return
+	}
+
+	text := fmt.Sprintf("🏷 用户 %s 可以访问的标签\n\n关闭「所有标签」后，", user.Username)
+	if user.Permissions.SelectedTagsNotAccessible {
+		text += "用户无法访问带有选中（✅）标签的条目："
+	} else {
+		text += "用户只能访问带有选中（✅）标签的条目："
+	}
+	if len(tags) > bot_pkg.MaxUserTagButtons {
+		text += fmt.Sprintf("\n\n共 %d 个标签，仅列出前 %d 个", len(tags), bot_pkg.MaxUserTagButtons)
+	}
+	menu := bot_pkg.CreateUserTagsMenu(user, tags)
+	req.Reply(text, &menu)
+}
+
+// editUser 执行用户管理按钮对应的操作
+// 停用、删除和修改类型会先显示确认消息，其余操作立即执行并刷新所在的菜单
+func editUser(req *bot_pkg.Request, serverService *services.ServerService) {
+	userID, op, arg := req.Data.Arg(0), req.Data.Arg(1), req.Data.Arg(2)
+	user, ok := getUserForAdmin(req, userID, serverService)
+	if !ok {
+		return
+	}
+
+	ctx := req.Context()
+	var err error
+	switch op {
+	case bot_pkg.UserOpDeactivate, bot_pkg.UserOpDelete, bot_pkg.UserOpType:
+		promptForUserConfirmation(req, user, op, arg)
+		return
+	case bot_pkg.UserOpActivate:
+		if user, err = serverService.SetUserActive(ctx, userID, true); err == nil {
+			showUserAdmin(req, user, "✅ 已启用用户", serverService)
+		}
+	case bot_pkg.UserOpPermission:
+		if user, err = serverService.ToggleUserPermission(ctx, userID, arg); err != nil {
+			break
+		}
+		switch arg {
+		case models.PermissionAllLibraries:
+			showUserLibraries(req, user, serverService)
+		case models.PermissionAllTags:
+			showUserTags(req, user, serverService)
+		default:
+			showUserPermissions(req, user)
+		}
+	case bot_pkg.UserOpLibrary:
+		if user, err = serverService.ToggleUserLibrary(ctx, userID, arg); err == nil {
+			showUserLibraries(req, user, serverService)
+		}
+	case bot_pkg.UserOpTag:
+		if user, err = serverService.ToggleUserTag(ctx, userID, arg); err == nil {
+			showUserTags(req, user, serverService)
+		}
+	default:
+		return
+	}
+
+	if err != nil {
+		log.Printf("修改用户 %s 失败 (%s %s): %v", userID, op, arg, err)
+		menu := bot_pkg.CreateUserStatsMenu()
+		req.Reply("❌ 修改用户失败: "+services.DescribeError(err), &menu)
+	}
+}
+
+// promptForUserConfirmation 显示确认消息，确认按钮携带要执行的操作
+func promptForUserConfirmation(req *bot_pkg.Request, user *models.UserInfo, op, arg string) {
+	var text string
+	switch op {
+	case bot_pkg.UserOpDeactivate:
+		text = fmt.Sprintf("⚠️ 确定要停用用户 %s 吗？\n\n停用后该用户将无法登录 Audiobookshelf，之后可以重新启用。", user.Username)
+	case bot_pkg.UserOpDelete:
+		text = fmt.Sprintf("⚠️ 确定要删除用户 %s 吗？\n\n用户的收听进度、书签和播放记录会一并删除，且无法恢复。", user.Username)
+	case bot_pkg.UserOpType:
+		if !services.IsAssignableUserType(arg) {
+			return
+		}
+		text = fmt.Sprintf("⚠️ 确定要将用户 %s 的类型从 %s 修改为 %s 吗？", user.Username, models.RoleBadge(user.Type), models.RoleBadge(arg))
+		if arg == models.UserTypeAdmin {
+			text += "\n\n管理员可以管理其他用户、媒体库和服务器设置。"
+		}
+	}
+
+	menu := bot_pkg.CreateConfirmMenu(
+		bot_pkg.EncodeCallback(bot_pkg.ActionUserConfirm, user.ID, op, arg),
+		bot_pkg.EncodeCallback(bot_pkg.ActionUserAdmin, user.ID),
+	)
+	req.Reply(text, &menu)
+}
+
+// confirmUserEdit 执行确认后的停用、删除或修改类型操作
+func confirmUserEdit(req *bot_pkg.Request, serverService *services.ServerService) {
+	userID, op, arg := req.Data.Arg(0), req.Data.Arg(1), req.Data.Arg(2)
+	user, ok := getUserForAdmin(req, userID, serverService)
+	if !ok {
+		return
+	}
+
+	ctx := req.Context()
+	var err error
+	switch op {
+	case bot_pkg.UserOpDeactivate:
+		if user, err = serverService.SetUserActive(ctx, userID, false); err == nil {
+			log.Printf("Telegram 用户 %d 停用了 Audiobookshelf 用户 %s", req.UserID, user.Username)
+			showUserAdmin(req, user, "⛔ 已停用用户", serverService)
+		}
+	case bot_pkg.UserOpType:
+		if user, err = serverService.SetUserType(ctx, userID, arg); err == nil {
+			log.Printf("Telegram 用户 %d 将 Audiobookshelf 用户 %s 的类型修改为 %s", req.UserID, user.Username, arg)
+			showUserAdmin(req, user, "✅ 已修改用户类型", serverService)
+		}
+	case bot_pkg.UserOpDelete:
+		if err = serverService.DeleteUser(ctx, userID); err == nil {
+			log.Printf("Telegram 用户 %d 删除了 Audiobookshelf 用户 %s", req.UserID, user.Username)
+			menu := bot_pkg.CreateUserStatsMenu()
+			req.Reply(fmt.Sprintf("🗑 已删除用户 %s", user.Username), &menu)
+		}
+	default:
+		return
+	}
+
+	if err != nil {
+		log.Printf("修改用户 %s 失败 (%s %s): %v", userID, op, arg, err)
+		menu := bot_pkg.CreateUserStatsMenu()
+		req.Reply("❌ 修改用户失败: "+services.DescribeError(err), &menu)
+	}
+}
+
+// promptForUserPassword 提示管理员输入用户的新密码
+func promptForUserPassword(req *bot_pkg.Request, serverService *services.ServerService) {
+	user, ok := getUserForAdmin(req, req.Data.Arg(0), serverService)
+	if !ok {
+		return
+	}
+
+	conversations.Set(req.ChatID, bot_pkg.StateAwaitingResetPassword, user.ID)
+	req.Reply(fmt.Sprintf("🔑 请输入用户 %s 的新密码，或发送 /cancel 取消。\n密码消息会在修改后立即删除。", user.Username), nil)
+}
+
+// resetUserPassword 重置用户的密码，包含密码的消息会被删除
+func resetUserPassword(req *bot_pkg.Request, userID, password string, serverService *services.ServerService) {
+	req.DeleteIncomingMessage()
+	req.Reply("🔑 正在重置密码，请稍候...", nil)
+
+	if err := serverService.ResetUserPassword(req.Context(), userID, password); err != nil {
+		log.Printf("重置用户 %s 的密码失败: %v", userID, err)
+		menu := bot_pkg.CreateUserStatsMenu()
+		req.Reply("❌ 重置密码失败: "+services.DescribeError(err), &menu)
+		return
+	}
+
+	user, ok := getUserForAdmin(req, userID, serverService)
+	if !ok {
+		return
+	}
+	log.Printf("Telegram 用户 %d 重置了 Audiobookshelf 用户 %s 的密码", req.UserID, user.Username)
+	showUserAdmin(req, user, "✅ 已重置密码", serverService)
+}
+
+// promptForNewUser 开始创建用户，提示输入用户名
+func promptForNewUser(req *bot_pkg.Request) {
+	conversations.Set(req.ChatID, bot_pkg.StateAwaitingNewUsername, "")
+	req.Reply("➕ 请输入新用户的用户名，或发送 /cancel 取消：", nil)
+}
+
+// promptForNewUserPassword 提示输入新用户的密码
+func promptForNewUserPassword(req *bot_pkg.Request, username string) {
+	conversations.Set(req.ChatID, bot_pkg.StateAwaitingNewUserPassword, username)
+	req.Reply(fmt.Sprintf("🔑 请输入新用户 %s 的密码，或发送 /cancel 取消。\n密码消息会在创建后立即删除。", username), nil)
+}
+
+// createUser 创建用户并显示其管理页面，包含密码的消息会被删除
+func createUser(req *bot_pkg.Request, username, password string, serverService *services.ServerService) {
+	req.DeleteIncomingMessage()
+	req.Reply("➕ 正在创建用户，请稍候...", nil)
+
+	user, err := serverService.CreateUser(req.Context(), username, password)
+	if err != nil {
+		log.Printf("创建用户 %s 失败: %v", username, err)
+		menu := bot_pkg.CreateUserStatsMenu()
+		req.Reply("❌ 创建用户失败: "+services.DescribeError(err), &menu)
+		return
+	}
+
+	log.Printf("Telegram 用户 %d 创建了 Audiobookshelf 用户 %s", req.UserID, user.Username)
+	showUserAdmin(req, user, "✅ 已创建用户，可以在下方修改权限和可以访问的媒体库", serverService)
+}
+
+// getUserForAdmin 获取要管理的用户，失败时回复错误信息
+func getUserForAdmin(req *bot_pkg.Request, userID string, serverService *services.ServerService) (*models.UserInfo, bool) {
+	user, err := serverService.GetUser(req.Context(), userID)
+	if err != nil {
+		log.Printf("获取用户 %s 失败: %v", userID, err)
+		menu := bot_pkg.CreateUserStatsMenu()
+		req.Reply("❌ 获取用户信息失败: "+services.DescribeError(err), &menu)
+		return nil, false
+	}
+	return user, true
+}
+
+// formatUserAdmin 格式化用户管理页面
+func formatUserAdmin(view *models.UserView) string {
+	user := view.User
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⚙️ *用户管理: %s*\n\n", escapeMarkdown(user.Username)))
+
+	status := "✅ 已启用"
+	if !user.IsActive {
+		status = "⛔ 已停用"
+	}
+	sb.WriteString(fmt.Sprintf("类型: %s | 状态: %s", view.Role, status))
+	if presence := view.Presence(); presence != "" {
+		sb.WriteString(" | " + presence)
+	}
+	sb.WriteString("\n")
+	if user.CreatedAt > 0 {
+		sb.WriteString(fmt.Sprintf("📅 创建于: %s\n", time.Unix(user.CreatedAt/1000, 0).Format("2006-01-02 15:04:05")))
+	}
+
+	sb.WriteString(fmt.Sprintf("🛡 权限: %s\n", formatNameList(view.Permissions)))
+	if view.AllLibraries {
+		sb.WriteString("📚 媒体库: 全部\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("📚 媒体库: %s\n", formatNameList(view.Libraries)))
+	}
+	sb.WriteString(fmt.Sprintf("🏷 标签: %s\n", escapeMarkdown(view.Tags)))
+
+	return sb.String()
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
 	"net/http"
@@ -40,7 +41,7 @@ func TestFullIntegration(t *testing.T) {
 		}
 
 		client := api.NewClient(cfg)
-		_, err := client.GetLibraries()
+		_, err := client.GetLibraries(context.Background())
 		if err != nil {
 			t.Errorf("无法连接到 Audiobookshelf 服务器: %v", err)
 		} else {
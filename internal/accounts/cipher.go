@@ -0,0 +1,106 @@
+package accounts
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrDecrypt 密文被篡改或使用了不同的密钥加密
+var ErrDecrypt = errors.New("accounts: unable to decrypt credentials")
+
+// Cipher 使用 AES-256-GCM 加密保存到磁盘上的凭据
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher 根据密钥字符串创建 Cipher，密钥经过 SHA-256 得到 256 位的 AES 密钥
+func NewCipher(secret string) (*Cipher, error) {
+	if secret == "" {
+		return nil, errors.New("accounts: empty encryption key")
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// LoadOrCreateKey 读取 path 中保存的密钥，文件不存在时生成 32 字节的随机密钥并以 0600 权限写入
+// 密钥文件丢失后已保存的凭据无法解密，用户需要重新绑定账户
+func LoadOrCreateKey(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return "", fmt.Errorf("accounts: key file %s is empty", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("error reading key file: %w", err)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, raw); err != nil {
+		return "", fmt.Errorf("error generating key: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(raw)
+
+	// O_EXCL 避免覆盖同时创建的密钥文件
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("error creating key file: %w", err)
+	}
+	if _, err := file.WriteString(key + "\n"); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("error writing key file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("error writing key file: %w", err)
+	}
+	return key, nil
+}
+
+// Encrypt 加密明文，返回 base64 编码的随机 nonce 和密文
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密 Encrypt 的输出
+func (c *Cipher) Decrypt(encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+
+	return string(plaintext), nil
+}
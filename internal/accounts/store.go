@@ -0,0 +1,89 @@
+package accounts
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/store"
+)
+
+// Link Telegram 用户与 Audiobookshelf 账户的绑定
+type Link struct {
+	// TelegramUserID Telegram 用户 ID
+	TelegramUserID int64
+	// UserID Audiobookshelf 用户 ID
+	UserID string
+	// Username Audiobookshelf 用户名
+	Username string
+	// Token 以该用户身份访问 Audiobookshelf 的 API Token，只在内存中以明文出现
+	Token    string
+	LinkedAt time.Time
+}
+
+// Store 读写账户绑定，Token 使用 Cipher 加密后交给仓库保存
+type Store struct {
+	repo   *store.LinkRepository
+	cipher *Cipher
+}
+
+// NewStore 创建账户绑定存储
+func NewStore(repo *store.LinkRepository, cipher *Cipher) *Store {
+	return &Store{repo: repo, cipher: cipher}
+}
+
+// Get 返回 Telegram 用户的绑定
+// 无法读取或解密的绑定（例如更换了密钥）视为未绑定，对应用户需要重新绑定
+func (s *Store) Get(telegramUserID int64) (Link, bool) {
+	record, ok, err := s.repo.Get(telegramUserID)
+	if err != nil {
+		log.Printf("读取 Telegram 用户 %d 的账户绑定失败: %v", telegramUserID, err)
+		return Link{}, false
+	}
+	if !ok {
+		return Link{}, false
+	}
+
+	token, err := s.cipher.Decrypt(record.EncryptedToken)
+	if err != nil {
+		log.Printf("无法解密 Telegram 用户 %d 的账户绑定，已忽略: %v", telegramUserID, err)
+		return Link{}, false
+	}
+
+	return Link{
+		TelegramUserID: record.TelegramUserID,
+		UserID:         record.UserID,
+		Username:       record.Username,
+		Token:          token,
+		LinkedAt:       record.LinkedAt,
+	}, true
+}
+
+// Save 加密 Token 后保存或替换绑定
+func (s *Store) Save(link Link) error {
+	encrypted, err := s.cipher.Encrypt(link.Token)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Save(store.LinkRecord{
+		TelegramUserID: link.TelegramUserID,
+		UserID:         link.UserID,
+		Username:       link.Username,
+		EncryptedToken: encrypted,
+		LinkedAt:       link.LinkedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error saving account link: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除绑定，返回删除前是否存在
+func (s *Store) Delete(telegramUserID int64) (bool, error) {
+	deleted, err := s.repo.Delete(telegramUserID)
+	if err != nil {
+		return false, fmt.Errorf("error deleting account link: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,128 @@
+package accounts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/store"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	c, err := NewCipher("secret")
+	if err != nil {
+		t.Fatalf("创建 Cipher 失败: %v", err)
+	}
+
+	first, err := c.Encrypt("token-123")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	second, _ := c.Encrypt("token-123")
+	if first == second {
+		t.Error("相同明文两次加密的结果应该不同")
+	}
+
+	plaintext, err := c.Decrypt(first)
+	if err != nil || plaintext != "token-123" {
+		t.Errorf("解密结果不正确: %q, %v", plaintext, err)
+	}
+
+	other, _ := NewCipher("other")
+	if _, err := other.Decrypt(first); err != ErrDecrypt {
+		t.Errorf("使用其他密钥解密期望返回 ErrDecrypt，实际为 %v", err)
+	}
+	if _, err := c.Decrypt("not base64!"); err != ErrDecrypt {
+		t.Errorf("无效密文期望返回 ErrDecrypt，实际为 %v", err)
+	}
+
+	if _, err := NewCipher(""); err == nil {
+		t.Error("空密钥应返回错误")
+	}
+}
+
+func TestLoadOrCreateKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.key")
+
+	key, err := LoadOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	if len(key) < 32 {
+		t.Errorf("生成的密钥过短: %q", key)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("密钥文件不存在: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("密钥文件权限期望为 0600，实际为 %o", perm)
+	}
+
+	again, err := LoadOrCreateKey(path)
+	if err != nil || again != key {
+		t.Errorf("再次读取应得到相同的密钥，实际为 %q, %v", again, err)
+	}
+
+	if err := os.WriteFile(path, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateKey(path); err == nil {
+		t.Error("空的密钥文件应返回错误")
+	}
+}
+
+func TestStorePersistsEncryptedLinks(t *testing.T) {
+	dir := t.TempDir()
+	db, err := store.Open(dir)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	c, _ := NewCipher("secret")
+
+	links := NewStore(store.NewLinkRepository(db), c)
+	if _, ok := links.Get(1); ok {
+		t.Fatal("新存储不应包含绑定")
+	}
+
+	link := Link{TelegramUserID: 1, UserID: "usr_1", Username: "alice", Token: "plain-token", LinkedAt: time.Now().UTC()}
+	if err := links.Save(link); err != nil {
+		t.Fatalf("保存绑定失败: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatalf("读取文件失败: %v", err)
+	}
+	if strings.Contains(string(data), "plain-token") {
+		t.Error("文件中不应出现明文 Token")
+	}
+
+	reopened, err := store.Open(dir)
+	if err != nil {
+		t.Fatalf("重新打开存储失败: %v", err)
+	}
+	got, ok := NewStore(store.NewLinkRepository(reopened), c).Get(1)
+	if !ok || got.Token != "plain-token" || got.Username != "alice" || got.UserID != "usr_1" {
+		t.Errorf("重新加载的绑定不正确: %+v", got)
+	}
+
+	// 更换密钥后无法解密的绑定视为未绑定
+	other, _ := NewCipher("other")
+	if _, ok := NewStore(store.NewLinkRepository(reopened), other).Get(1); ok {
+		t.Error("无法解密的绑定应被忽略")
+	}
+
+	deleted, err := links.Delete(1)
+	if err != nil || !deleted {
+		t.Fatalf("删除绑定失败: %v, %v", deleted, err)
+	}
+	if deleted, _ := links.Delete(1); deleted {
+		t.Error("重复删除应返回 false")
+	}
+	if _, ok := links.Get(1); ok {
+		t.Error("删除后不应包含绑定")
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态，请求未被发送
+// 该错误同时也是 ErrServerUnavailable，调用方可以按服务器不可用处理
+var ErrCircuitOpen = fmt.Errorf("%w: circuit breaker open", ErrServerUnavailable)
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	// BreakerClosed 正常状态，请求直接发送
+	BreakerClosed BreakerState = iota
+	// BreakerOpen 连续失败过多，冷却期内拒绝所有请求
+	BreakerOpen
+	// BreakerHalfOpen 冷却期结束，允许一个试探请求通过
+	BreakerHalfOpen
+)
+
+// String 返回状态名称
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// BreakerStatus 熔断器当前状态的快照
+type BreakerStatus struct {
+	// Enabled 是否启用了熔断器
+	Enabled             bool
+	State               BreakerState
+	ConsecutiveFailures int
+	// RetryAt 熔断器打开时，允许下一次试探请求的时间
+	RetryAt time.Time
+}
+
+// circuitBreaker 在连续失败达到阈值后，在冷却期内直接拒绝请求
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	// probing 半开状态下是否已有试探请求在进行
+	probing bool
+	now     func() time.Time
+}
+
+// newCircuitBreaker 创建熔断器，threshold 小于等于 0 时返回 nil 表示不启用
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow 判断是否允许发送请求
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record 记录请求结果
+func (b *circuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	// 调用方主动取消的请求无法说明服务器状态
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if !isBreakerFailure(err) {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// status 返回熔断器当前状态
+func (b *circuitBreaker) status() BreakerStatus {
+	if b == nil {
+		return BreakerStatus{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		Enabled:             true,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state == BreakerOpen {
+		status.RetryAt = b.openedAt.Add(b.cooldown)
+	}
+	return status
+}
+
+// isBreakerFailure 判断错误是否说明服务器本身出了问题
+// 4xx 等业务错误说明服务器仍在正常响应，不计入失败次数
+func isBreakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.StatusCode == 0 {
+		return true
+	}
+	return apiErr.StatusCode >= http.StatusInternalServerError
+}
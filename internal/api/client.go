@@ -4,9 +4,11 @@ import (
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"sync"
@@ -18,6 +20,10 @@ type Client struct {
 	baseURL    string
 	token      string
 	httpClient *http.Client
+	// timeout 单个请求的超时时间，0 表示不限制
+	timeout time.Duration
+	retry   RetryPolicy
+	breaker *circuitBreaker
 
 	// 添加缓存相关字段
 	librariesCache      []models.LibraryInfo
@@ -36,21 +42,90 @@ func NewClient(config *config.Config) *Client {
 	// 创建不使用代理的 HTTP 客户端
 	client := &http.Client{}
 
+	// 仅对幂等的 GET 请求重试
+	retry := RetryPolicy{
+		MaxAttempts:    config.RetryMaxAttempts,
+		InitialBackoff: config.RetryInitialBackoff,
+		MaxBackoff:     config.RetryMaxBackoff,
+		Multiplier:     2,
+	}
+
 	return &Client{
 		baseURL:     baseURL,
 		token:       config.AudiobookshelfToken,
 		httpClient:  client,
+		timeout:     config.RequestTimeout,
+		retry:       retry,
+		breaker:     newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
 		cacheExpiry: 30 * time.Minute, // 默认30分钟缓存过期时间
 	}
 }
 
+// WithToken 返回以另一个用户的 token 访问同一服务器的客户端
+// 新客户端与原客户端共享 HTTP 连接、重试策略和熔断器；媒体库缓存相互独立，因为不同用户可以访问的媒体库不同
+func (c *Client) WithToken(token string) *Client {
+	return &Client{
+		baseURL:     c.baseURL,
+		token:       token,
+		httpClient:  c.httpClient,
+		timeout:     c.timeout,
+		retry:       c.retry,
+		breaker:     c.breaker,
+		cacheExpiry: c.cacheExpiry,
+	}
+}
+
 // DoRequestRaw performs an HTTP request to the Audiobookshelf API and returns raw response
-func (c *Client) DoRequestRaw(method, path string, body interface{}) ([]byte, error) {
-	return c.doRequest(method, path, body)
+func (c *Client) DoRequestRaw(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	return c.doRequest(ctx, method, path, body)
 }
 
-// doRequest performs an HTTP request to the Audiobookshelf API
-func (c *Client) doRequest(method, path string, body interface{}) ([]byte, error) {
+// BreakerStatus 返回熔断器当前状态
+func (c *Client) BreakerStatus() BreakerStatus {
+	return c.breaker.status()
+}
+
+// doRequest performs an HTTP request to the Audiobookshelf API.
+// GET 请求在遇到 5xx、超时或连接被重置时按重试策略重试；
+// 连续失败过多时熔断器打开，冷却期内的请求直接返回 ErrCircuitOpen。
+func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	attempts := 1
+	if method == http.MethodGet && c.retry.MaxAttempts > 1 {
+		attempts = c.retry.MaxAttempts
+	}
+
+	var data []byte
+	var err error
+	for attempt := 1; ; attempt++ {
+		data, err = c.doRequestOnce(ctx, method, path, body)
+		if err == nil || attempt >= attempts || !isRetryable(err) || ctx.Err() != nil {
+			break
+		}
+
+		wait := c.retry.backoff(attempt)
+		log.Printf("请求 %s %s 失败 (第 %d/%d 次)，%s 后重试: %v", method, endpointOf(path), attempt, attempts, wait, err)
+		if sleepErr := sleepContext(ctx, wait); sleepErr != nil {
+			break
+		}
+	}
+
+	c.breaker.record(err)
+	return data, err
+}
+
+// doRequestOnce 发送一次请求。
+// 请求会在 ctx 被取消或超过客户端配置的超时时间后中止。
+func (c *Client) doRequestOnce(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
 	var reqBody io.Reader
 
 	if body != nil {
@@ -61,40 +136,43 @@ func (c *Client) doRequest(method, path string, body interface{}) ([]byte, error
 		reqBody = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, c.baseURL+path, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	// 登录请求不携带 token
+	if c.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+		return nil, &APIError{Method: method, Endpoint: endpointOf(path), Err: err}
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, &APIError{Method: method, Endpoint: endpointOf(path), Err: fmt.Errorf("error reading response body: %w", err)}
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(method, path, resp.StatusCode, respBody)
 	}
 
 	return respBody, nil
 }
 
 // GetLibraries retrieves the list of libraries from Audiobookshelf
-func (c *Client) GetLibraries() ([]byte, error) {
-	return c.doRequest("GET", "/api/libraries", nil)
+func (c *Client) GetLibraries(ctx context.Context) ([]byte, error) {
+	return c.doRequest(ctx, "GET", "/api/libraries", nil)
 }
 
 // GetServerStatus 获取服务器状态信息
-func (c *Client) GetServerStatus() (*models.ServerStatus, error) {
-	data, err := c.doRequest("GET", "/status", nil)
+func (c *Client) GetServerStatus(ctx context.Context) (*models.ServerStatus, error) {
+	data, err := c.doRequest(ctx, "GET", "/status", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -109,27 +187,18 @@ func (c *Client) GetServerStatus() (*models.ServerStatus, error) {
 }
 
 // GetLibraryItemsCount 获取指定库中的媒体项数量
-func (c *Client) GetLibraryItemsCount(libraryID string) (int, error) {
-	endpoint := fmt.Sprintf("/api/libraries/%s/items", libraryID)
-	data, err := c.doRequest("GET", endpoint, nil)
+// 只请求一条精简条目，读取响应中的 total 字段
+func (c *Client) GetLibraryItemsCount(ctx context.Context, libraryID string) (int, error) {
+	page, err := c.ListLibraryItems(ctx, libraryID, LibraryItemsOptions{Limit: 1, Minified: true})
 	if err != nil {
 		return 0, err
 	}
 
-	var response struct {
-		Total int `json:"total"`
-	}
-
-	err = json.Unmarshal(data, &response)
-	if err != nil {
-		return 0, fmt.Errorf("error unmarshaling library items: %w", err)
-	}
-
-	return response.Total, nil
+	return page.Total, nil
 }
 
 // GetLibrariesInfo 获取媒体库详细信息
-func (c *Client) GetLibrariesInfo() ([]models.LibraryInfo, error) {
+func (c *Client) GetLibrariesInfo(ctx context.Context) ([]models.LibraryInfo, error) {
 	// 检查缓存
 	c.librariesCacheMutex.RLock()
 	if time.Since(c.librariesCacheTime) < c.cacheExpiry && c.librariesCache != nil {
@@ -140,7 +209,7 @@ func (c *Client) GetLibrariesInfo() ([]models.LibraryInfo, error) {
 	c.librariesCacheMutex.RUnlock()
 
 	// 缓存失效，从API获取新数据
-	data, err := c.doRequest("GET", "/api/libraries", nil)
+	data, err := c.doRequest(ctx, "GET", "/api/libraries", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -164,126 +233,9 @@ func (c *Client) GetLibrariesInfo() ([]models.LibraryInfo, error) {
 	return response.Libraries, nil
 }
 
-// SearchBooks 搜索图书，支持并行处理
-func (c *Client) SearchBooks(term string, libraryID string) ([]models.Book, error) {
-	params := url.Values{}
-	params.Add("q", term)
-
-	// 如果指定了特定的媒体库ID，则只搜索该库
-	if libraryID != "" {
-		endpoint := fmt.Sprintf("/api/libraries/%s/search?%s", libraryID, params.Encode())
-
-		data, err := c.doRequest("GET", endpoint, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		var response struct {
-			Results []struct {
-				LibraryItem struct {
-					Path    string `json:"path"`
-					RelPath string `json:"relPath"`
-					Size    int64  `json:"size"`
-					AddedAt int64  `json:"addedAt"`
-				}
-			} `json:"book"`
-		}
-
-		err = json.Unmarshal(data, &response)
-		if err != nil {
-			return nil, fmt.Errorf("error unmarshaling search results: %w", err)
-		}
-
-		// 提取libraryItem中的字段
-		var books []models.Book
-		for _, result := range response.Results {
-			books = append(books, models.Book{
-				LibraryID: libraryID,
-				RelPath:   result.LibraryItem.RelPath,
-				Size:      result.LibraryItem.Size,
-				AddedAt:   result.LibraryItem.AddedAt,
-			})
-		}
-
-		return books, nil
-	}
-
-	// 如果没有指定特定的媒体库ID，则搜索所有库，使用并行处理
-	libraries, err := c.GetLibrariesInfo()
-	if err != nil {
-		return nil, fmt.Errorf("获取媒体库列表失败: %w", err)
-	}
-
-	// 使用并行处理，最大并发数为4
-	const maxConcurrency = 4
-	semaphore := make(chan struct{}, maxConcurrency)
-
-	var allBooks []models.Book
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-	// 使用relPath作为唯一标识符进行去重
-	bookRelPaths := make(map[string]bool)
-
-	for _, lib := range libraries {
-		wg.Add(1)
-		go func(lib models.LibraryInfo) {
-			defer wg.Done()
-
-			// 控制并发数
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			endpoint := fmt.Sprintf("/api/libraries/%s/search?%s", lib.ID, params.Encode())
-
-			data, err := c.doRequest("GET", endpoint, nil)
-			if err != nil {
-				// 继续搜索下一个库而不是完全失败
-				return
-			}
-
-			var response struct {
-				Results []struct {
-					LibraryItem struct {
-						Path    string `json:"path"`
-						RelPath string `json:"relPath"`
-						Size    int64  `json:"size"`
-						AddedAt int64  `json:"addedAt"`
-					}
-				} `json:"book"`
-			}
-
-			err = json.Unmarshal(data, &response)
-			if err != nil {
-				// 继续搜索下一个库而不是完全失败
-				return
-			}
-
-			// 添加去重逻辑
-			mu.Lock()
-			defer mu.Unlock()
-			for _, result := range response.Results {
-				if !bookRelPaths[result.LibraryItem.RelPath] {
-					allBooks = append(allBooks, models.Book{
-						LibraryID: lib.ID,
-						RelPath:   result.LibraryItem.RelPath,
-						Size:      result.LibraryItem.Size,
-						AddedAt:   result.LibraryItem.AddedAt,
-					})
-					bookRelPaths[result.LibraryItem.RelPath] = true
-				}
-			}
-		}(lib)
-	}
-
-	// 等待所有goroutine完成
-	wg.Wait()
-
-	return allBooks, nil
-}
-
 // GetUsers 获取用户列表
-func (c *Client) GetUsers() ([]models.UserInfo, error) {
-	data, err := c.doRequest("GET", "/api/users", nil)
+func (c *Client) GetUsers(ctx context.Context) ([]models.UserInfo, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/users", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -300,33 +252,36 @@ func (c *Client) GetUsers() ([]models.UserInfo, error) {
 	return response.Users, nil
 }
 
-// GetUserMediaProgress 获取用户的媒体播放进度信息
-func (c *Client) GetUserMediaProgress(userID string) ([]interface{}, error) {
-	// 使用 /api/me/listening-stats 端点获取当前用户的收听统计
-	data, err := c.doRequest("GET", "/api/me/listening-stats", nil)
+// GetUser 获取指定用户的详细信息（需要管理员权限）
+func (c *Client) GetUser(ctx context.Context, userID string) (*models.UserInfo, error) {
+	endpoint := fmt.Sprintf("/api/users/%s", url.PathEscape(userID))
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// 解析收听统计数据
-	var stats map[string]interface{}
-	err = json.Unmarshal(data, &stats)
+	var user models.UserInfo
+	err = json.Unmarshal(data, &user)
 	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling listening stats: %w", err)
+		return nil, fmt.Errorf("error unmarshaling user: %w", err)
 	}
 
-	// 从收听统计中提取媒体进度信息
-	mediaProgress := make([]interface{}, 0)
-	if items, ok := stats["recentSessions"].([]interface{}); ok {
-		mediaProgress = items
+	return &user, nil
+}
+
+// GetUserMediaProgress 获取指定用户的媒体播放进度信息（需要管理员权限）
+func (c *Client) GetUserMediaProgress(ctx context.Context, userID string) ([]models.MediaProgress, error) {
+	user, err := c.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	return mediaProgress, nil
+	return user.MediaProgress, nil
 }
 
 // GetCurrentUser 获取当前用户信息
-func (c *Client) GetCurrentUser() (*models.UserInfo, error) {
-	data, err := c.doRequest("GET", "/api/me", nil)
+func (c *Client) GetCurrentUser(ctx context.Context) (*models.UserInfo, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/me", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -340,18 +295,80 @@ func (c *Client) GetCurrentUser() (*models.UserInfo, error) {
 	return &user, nil
 }
 
+// Login 使用用户名和密码登录，返回的用户信息中包含该用户的 API Token
+// 登录请求不携带客户端的 token，用户名或密码错误时返回 ErrUnauthorized
+func (c *Client) Login(ctx context.Context, username, password string) (*models.UserInfo, error) {
+	body := map[string]string{
+		"username": username,
+		"password": password,
+	}
+	data, err := c.WithToken("").doRequest(ctx, "POST", "/login", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.LoginResponse
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling login response: %w", err)
+	}
+	if response.User.Token == "" {
+		return nil, fmt.Errorf("login response does not contain a token")
+	}
+
+	return &response.User, nil
+}
+
 // GetListeningStats 获取当前用户的收听统计信息
-func (c *Client) GetListeningStats() (map[string]interface{}, error) {
-	data, err := c.doRequest("GET", "/api/me/listening-stats", nil)
+func (c *Client) GetListeningStats(ctx context.Context) (*models.ListeningStats, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/me/listening-stats", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var stats map[string]interface{}
+	var stats models.ListeningStats
 	err = json.Unmarshal(data, &stats)
 	if err != nil {
 		return nil, fmt.Errorf("error unmarshaling listening stats: %w", err)
 	}
 
-	return stats, nil
+	return &stats, nil
+}
+
+// GetUserListeningStats 获取指定用户的收听统计信息（需要管理员权限）
+func (c *Client) GetUserListeningStats(ctx context.Context, userID string) (*models.ListeningStats, error) {
+	endpoint := fmt.Sprintf("/api/users/%s/listening-stats", url.PathEscape(userID))
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats models.ListeningStats
+	err = json.Unmarshal(data, &stats)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling listening stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// GetUserListeningSessions 分页获取指定用户的收听会话，按时间倒序排列（需要管理员权限）
+func (c *Client) GetUserListeningSessions(ctx context.Context, userID string, page, itemsPerPage int) (*models.ListeningSessionsPage, error) {
+	params := url.Values{}
+	params.Set("page", fmt.Sprint(page))
+	params.Set("itemsPerPage", fmt.Sprint(itemsPerPage))
+	endpoint := fmt.Sprintf("/api/users/%s/listening-sessions?%s", url.PathEscape(userID), params.Encode())
+
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions models.ListeningSessionsPage
+	err = json.Unmarshal(data, &sessions)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling listening sessions: %w", err)
+	}
+
+	return &sessions, nil
 }
@@ -2,7 +2,13 @@ package api
 
 import (
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -37,4 +43,222 @@ func TestNewClient(t *testing.T) {
 	if client.baseURL != expectedURL {
 		t.Errorf("期望 baseURL 为 '%s'，实际得到 '%s'", expectedURL, client.baseURL)
 	}
-}
\ No newline at end of file
+}
+
+func TestDoRequestTimeout(t *testing.T) {
+	// 模拟一个长时间无响应的服务器
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{
+		AudiobookshelfURL: server.URL,
+		RequestTimeout:    50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	_, err := client.GetLibraries(context.Background())
+	if err == nil {
+		t.Fatal("期望请求超时返回错误，但得到了 nil")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望错误为 context.DeadlineExceeded，实际得到 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("请求未按配置超时，耗时 %s", elapsed)
+	}
+}
+
+func TestDoRequestCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := client.GetServerStatus(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("期望错误为 context.Canceled，实际得到 %v", err)
+	}
+}
+
+func TestGetUserMediaProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 必须查询指定用户，而不是当前 token 对应的用户
+		if r.URL.Path != "/api/users/usr_2" {
+			t.Errorf("请求路径不正确: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"id":"usr_2","username":"alice","mediaProgress":[{"id":"mp_1","libraryItemId":"li_1","progress":0.5,"currentTime":120,"isFinished":false}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	progress, err := client.GetUserMediaProgress(context.Background(), "usr_2")
+	if err != nil {
+		t.Fatalf("获取播放进度失败: %v", err)
+	}
+
+	if len(progress) != 1 || progress[0].LibraryItemID != "li_1" || progress[0].Progress != 0.5 {
+		t.Errorf("播放进度解析不正确: %+v", progress)
+	}
+}
+
+func TestGetListeningStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"totalTime": 7200,
+			"today": 600,
+			"days": {"2024-05-01": 3600},
+			"dayOfWeek": {"Wednesday": 3600},
+			"items": {
+				"li_1": {"id": "li_1", "timeListening": 1800, "mediaMetadata": {"title": "三体"}},
+				"li_2": {"id": "li_2", "timeListening": 5400, "mediaMetadata": {"title": "球状闪电"}}
+			},
+			"recentSessions": [{"id": "ses_1", "displayTitle": "三体", "timeListening": 300, "updatedAt": 1714550400000}]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	stats, err := client.GetListeningStats(context.Background())
+	if err != nil {
+		t.Fatalf("获取收听统计失败: %v", err)
+	}
+
+	if stats.TotalTime != 7200 || stats.Days["2024-05-01"] != 3600 || stats.DayOfWeek["Wednesday"] != 3600 {
+		t.Errorf("收听统计解析不正确: %+v", stats)
+	}
+
+	top := stats.TopItems(1)
+	if len(top) != 1 || top[0].MediaMetadata.Title != "球状闪电" {
+		t.Errorf("期望收听时间最长的是 '球状闪电'，实际得到 %+v", top)
+	}
+
+	if len(stats.RecentSessions) != 1 || stats.RecentSessions[0].Title() != "三体" {
+		t.Errorf("最近会话解析不正确: %+v", stats.RecentSessions)
+	}
+}
+
+func TestGetUserListeningSessions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/users/usr_2/listening-sessions" {
+			t.Errorf("请求路径不正确: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("page") != "0" || r.URL.Query().Get("itemsPerPage") != "5" {
+			t.Errorf("分页参数不正确: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"total":12,"numPages":3,"page":0,"itemsPerPage":5,"sessions":[{"id":"ses_1","userId":"usr_2","timeListening":60}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	page, err := client.GetUserListeningSessions(context.Background(), "usr_2", 0, 5)
+	if err != nil {
+		t.Fatalf("获取收听会话失败: %v", err)
+	}
+
+	if page.Total != 12 || len(page.Sessions) != 1 || page.Sessions[0].UserID != "usr_2" {
+		t.Errorf("收听会话解析不正确: %+v", page)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/login" {
+			t.Errorf("请求不正确: %s %s", r.Method, r.URL.Path)
+		}
+		// 登录请求不应携带机器人自己的 token
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("登录请求不应包含 Authorization 头，实际为 %q", auth)
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["username"] != "alice" || body["password"] != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Unauthorized"))
+			return
+		}
+		w.Write([]byte(`{"user":{"id":"usr_2","username":"alice","type":"user","token":"alice-token"},"userDefaultLibraryId":"lib_1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL, AudiobookshelfToken: "bot-token"})
+	user, err := client.Login(context.Background(), "alice", "secret")
+	if err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+	if user.ID != "usr_2" || user.Token != "alice-token" {
+		t.Errorf("登录响应解析不正确: %+v", user)
+	}
+
+	_, err = client.Login(context.Background(), "alice", "wrong")
+	if !IsUnauthorized(err) {
+		t.Errorf("密码错误期望返回 ErrUnauthorized，实际为 %v", err)
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer alice-token" {
+			t.Errorf("期望使用用户的 token，实际为 %q", auth)
+		}
+		w.Write([]byte(`{"id":"usr_2","username":"alice"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL, AudiobookshelfToken: "bot-token"})
+	user, err := client.WithToken("alice-token").GetCurrentUser(context.Background())
+	if err != nil || user.Username != "alice" {
+		t.Errorf("以用户身份请求失败: %+v, %v", user, err)
+	}
+	if client.token != "bot-token" {
+		t.Errorf("WithToken 不应修改原客户端的 token，实际为 %q", client.token)
+	}
+}
+
+func TestScanLibrary(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if r.URL.Path == "/api/items/li_1/scan" {
+			w.Write([]byte(`{"result":"UPDATED"}`))
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	if err := client.ScanLibrary(context.Background(), "lib_1", false); err != nil {
+		t.Fatalf("扫描媒体库失败: %v", err)
+	}
+	if err := client.ScanLibrary(context.Background(), "lib_1", true); err != nil {
+		t.Fatalf("强制扫描媒体库失败: %v", err)
+	}
+	result, err := client.ScanItem(context.Background(), "li_1")
+	if err != nil || result != ItemScanUpdated {
+		t.Errorf("重新扫描条目的结果不正确: %q, %v", result, err)
+	}
+
+	want := []string{"POST /api/libraries/lib_1/scan", "POST /api/libraries/lib_1/scan?force=1", "POST /api/items/li_1/scan"}
+	if len(requests) != len(want) {
+		t.Fatalf("期望 %d 个请求，实际为 %v", len(want), requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("第 %d 个请求期望为 %s，实际为 %s", i+1, want[i], requests[i])
+		}
+	}
+}
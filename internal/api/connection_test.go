@@ -43,7 +43,7 @@ func TestAudiobookshelfConnection(t *testing.T) {
 
 		// 测试连接
 		t.Log("正在测试 Audiobookshelf 连接...")
-		testResult.libraries, testResult.err = client.GetLibraries()
+		testResult.libraries, testResult.err = client.GetLibraries(ctx)
 		done <- true
 	}()
 
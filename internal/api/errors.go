@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 可用于 errors.Is 判断的错误类别
+var (
+	// ErrUnauthorized token 无效或已被撤销
+	ErrUnauthorized = errors.New("audiobookshelf: unauthorized")
+	// ErrForbidden 当前用户没有执行该操作的权限
+	ErrForbidden = errors.New("audiobookshelf: forbidden")
+	// ErrNotFound 请求的资源不存在
+	ErrNotFound = errors.New("audiobookshelf: not found")
+	// ErrServerUnavailable 服务器不可达或暂时无法提供服务
+	ErrServerUnavailable = errors.New("audiobookshelf: server unavailable")
+)
+
+// APIError Audiobookshelf API 请求失败时返回的错误
+type APIError struct {
+	// StatusCode HTTP 状态码，请求未能到达服务器时为 0
+	StatusCode int
+	Method     string
+	Endpoint   string
+	// Message 从响应体中解析出的服务器错误信息
+	Message string
+	// Err 请求未能到达服务器时的底层错误
+	Err error
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s %s: request failed: %v", e.Method, e.Endpoint, e.Err)
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("%s %s: status %d", e.Method, e.Endpoint, e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: status %d: %s", e.Method, e.Endpoint, e.StatusCode, e.Message)
+}
+
+// Unwrap 返回底层错误，使 errors.Is 能识别 context.Canceled 等错误
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is 将状态码映射到对应的错误类别
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrServerUnavailable:
+		if e.StatusCode == 0 {
+			// 请求被调用方主动取消不算服务器不可用
+			return !errors.Is(e.Err, context.Canceled)
+		}
+		return e.StatusCode == http.StatusBadGateway ||
+			e.StatusCode == http.StatusServiceUnavailable ||
+			e.StatusCode == http.StatusGatewayTimeout
+	}
+	return false
+}
+
+// IsUnauthorized 判断错误是否由 token 无效引起
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbidden 判断错误是否由权限不足引起
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsNotFound 判断错误是否由资源不存在引起
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsServerUnavailable 判断错误是否由服务器不可达引起
+func IsServerUnavailable(err error) bool {
+	return errors.Is(err, ErrServerUnavailable)
+}
+
+// newAPIError 根据响应构造 APIError
+func newAPIError(method, path string, statusCode int, body []byte) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Method:     method,
+		Endpoint:   endpointOf(path),
+		Message:    parseErrorMessage(body),
+	}
+}
+
+// endpointOf 去掉路径中的查询参数，避免搜索词等内容出现在错误信息中
+func endpointOf(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+// parseErrorMessage 解析 Audiobookshelf 返回的错误信息
+// 服务器有时返回纯文本（如 "Unauthorized"），有时返回 {"error": "..."} 形式的 JSON
+func parseErrorMessage(body []byte) string {
+	var payload struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if payload.Error != "" {
+			return payload.Error
+		}
+		if payload.Message != "" {
+			return payload.Message
+		}
+	}
+
+	message := strings.TrimSpace(string(body))
+	// HTML 错误页（例如反向代理返回的 502 页面）对用户没有意义
+	if strings.HasPrefix(message, "<") {
+		return ""
+	}
+	const maxLength = 200
+	if len([]rune(message)) > maxLength {
+		message = string([]rune(message)[:maxLength]) + "..."
+	}
+	return message
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+)
+
+func TestAPIErrorClassification(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		check       func(error) bool
+		wantMessage string
+	}{
+		{"未授权", http.StatusUnauthorized, "Unauthorized", IsUnauthorized, "Unauthorized"},
+		{"无权限", http.StatusForbidden, `{"error":"Forbidden"}`, IsForbidden, "Forbidden"},
+		{"不存在", http.StatusNotFound, "Not Found", IsNotFound, "Not Found"},
+		{"服务不可用", http.StatusBadGateway, "<html>Bad Gateway</html>", IsServerUnavailable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+			_, err := client.GetLibraries(context.Background())
+
+			if !tt.check(err) {
+				t.Fatalf("错误分类不正确: %v", err)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("期望得到 *APIError，实际得到 %T", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("期望状态码 %d，实际得到 %d", tt.status, apiErr.StatusCode)
+			}
+			if apiErr.Method != "GET" || apiErr.Endpoint != "/api/libraries" {
+				t.Errorf("请求信息不正确: %s %s", apiErr.Method, apiErr.Endpoint)
+			}
+			if apiErr.Message != tt.wantMessage {
+				t.Errorf("期望错误信息 %q，实际得到 %q", tt.wantMessage, apiErr.Message)
+			}
+		})
+	}
+}
+
+func TestAPIErrorConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: url})
+	_, err := client.GetLibraries(context.Background())
+
+	if !IsServerUnavailable(err) {
+		t.Errorf("期望连接失败被识别为服务器不可用，实际得到 %v", err)
+	}
+	if IsUnauthorized(err) {
+		t.Error("连接失败不应被识别为未授权")
+	}
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Audiobookshelf 通过 socket.io 推送的事件名称
+const (
+	// EventItemAdded 新增条目，数据为 models.LibraryItem
+	EventItemAdded = "item_added"
+	// EventItemUpdated 条目更新，数据为 models.LibraryItem
+	EventItemUpdated = "item_updated"
+	// EventItemRemoved 条目被删除，数据为 models.LibraryItem（通常只有 ID）
+	EventItemRemoved = "item_removed"
+	// EventItemsAdded 批量新增条目，数据为 []models.LibraryItem
+	EventItemsAdded = "items_added"
+	// EventItemsUpdated 批量更新条目，数据为 []models.LibraryItem
+	EventItemsUpdated = "items_updated"
+	// EventScanStart 媒体库开始扫描，数据为 models.LibraryScan
+	EventScanStart = "scan_start"
+	// EventScanComplete 媒体库扫描结束，数据为 models.LibraryScan
+	EventScanComplete = "scan_complete"
+	// EventTaskStarted 后台任务开始，数据为 models.Task
+	EventTaskStarted = "task_started"
+	// EventTaskFinished 后台任务结束，数据为 models.Task
+	EventTaskFinished = "task_finished"
+	// EventUserOnline 用户上线或开始播放，数据为 models.OnlineUser
+	EventUserOnline = "user_online"
+	// EventUserOffline 用户下线，数据为 models.OnlineUser
+	EventUserOffline = "user_offline"
+	// EventUserItemProgressUpdated 播放会话更新了用户的收听进度，数据为 models.ProgressUpdate
+	EventUserItemProgressUpdated = "user_item_progress_updated"
+)
+
+// 由 EventStream 自身产生的事件，用于通知订阅方连接状态的变化
+// 断线期间可能错过服务器事件，订阅方可以在重新连接后主动同步
+const (
+	EventConnected    = "connected"
+	EventDisconnected = "disconnected"
+)
+
+// 认证相关的事件，只在 EventStream 内部处理
+const (
+	eventAuth         = "auth"
+	eventInit         = "init"
+	eventInvalidToken = "invalid_token"
+	eventAuthFailed   = "auth_failed"
+)
+
+// Event 一个实时事件
+type Event struct {
+	Name string
+	// Data 事件数据的原始 JSON，没有数据时为 nil
+	Data json.RawMessage
+}
+
+// Decode 将事件数据解析到 v 中
+func (e Event) Decode(v interface{}) error {
+	if len(e.Data) == 0 {
+		return fmt.Errorf("event %s has no data", e.Name)
+	}
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("error unmarshaling %s event: %w", e.Name, err)
+	}
+	return nil
+}
+
+// eventSubscriber 一个事件订阅
+type eventSubscriber struct {
+	ch chan Event
+	// names 关注的事件名称，为空表示全部事件
+	names map[string]bool
+}
+
+// EventStream 通过 socket.io 接收 Audiobookshelf 的实时事件，并分发给各个订阅方
+// 连接使用客户端的 token 认证，断开后按指数退避自动重连
+type EventStream struct {
+	client    *Client
+	reconnect RetryPolicy
+
+	mu          sync.Mutex
+	subscribers map[int]*eventSubscriber
+	nextID      int
+	connected   bool
+}
+
+// NewEventStream 创建事件流，调用 Run 后开始连接
+func NewEventStream(client *Client) *EventStream {
+	return &EventStream{
+		client: client,
+		reconnect: RetryPolicy{
+			InitialBackoff: time.Second,
+			MaxBackoff:     time.Minute,
+			Multiplier:     2,
+		},
+		subscribers: make(map[int]*eventSubscriber),
+	}
+}
+
+// Subscribe 订阅指定名称的事件，不指定名称时订阅全部事件
+// 返回的通道缓冲 buffer 个事件，订阅方处理不及时导致缓冲区已满时新事件会被丢弃；
+// 调用返回的取消函数后通道会被关闭
+func (s *EventStream) Subscribe(buffer int, names ...string) (<-chan Event, func()) {
+	subscriber := &eventSubscriber{ch: make(chan Event, buffer)}
+	if len(names) > 0 {
+		subscriber.names = make(map[string]bool, len(names))
+		for _, name := range names {
+			subscriber.names[name] = true
+		}
+	}
+
+	s.mu.Lock()
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = subscriber
+	s.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subscribers, id)
+			s.mu.Unlock()
+			close(subscriber.ch)
+		})
+	}
+	return subscriber.ch, cancel
+}
+
+// Connected 返回当前是否已连接并通过认证
+func (s *EventStream) Connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.connected
+}
+
+// Run 连接 Audiobookshelf 并持续接收事件，直到 ctx 被取消或 token 被拒绝
+func (s *EventStream) Run(ctx context.Context) error {
+	attempt := 0
+	for {
+		authenticated, err := s.runSession(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if IsUnauthorized(err) {
+			log.Printf("Audiobookshelf 拒绝了事件连接的 token，停止接收实时事件: %v", err)
+			return err
+		}
+
+		// 认证成功过说明服务器正常，重新从最短的等待时间开始
+		if authenticated {
+			attempt = 0
+		}
+		attempt++
+		wait := s.reconnect.backoff(attempt)
+		log.Printf("Audiobookshelf 事件连接断开，%s 后重连: %v", wait, err)
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// runSession 建立一次连接并处理事件，直到连接断开，返回是否曾经认证成功
+func (s *EventStream) runSession(ctx context.Context) (authenticated bool, err error) {
+	session, err := s.client.openPolling(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// 连接默认命名空间
+	if err := session.send(ctx, string([]byte{engineMessage, socketConnect})); err != nil {
+		return false, err
+	}
+
+	defer func() {
+		if authenticated {
+			s.setConnected(false)
+			s.publish(Event{Name: EventDisconnected})
+		}
+	}()
+
+	for {
+		packets, err := session.poll(ctx)
+		if err != nil {
+			return authenticated, err
+		}
+
+		for _, packet := range packets {
+			if packet == "" {
+				continue
+			}
+
+			switch packet[0] {
+			case enginePing:
+				if err := session.send(ctx, string(enginePong)); err != nil {
+					return authenticated, err
+				}
+			case engineClose:
+				return authenticated, errSocketClosed
+			case engineMessage:
+				done, err := s.handleSocketPacket(ctx, session, packet[1:], &authenticated)
+				if done {
+					return authenticated, err
+				}
+			case engineNoop:
+			}
+		}
+	}
+}
+
+// handleSocketPacket 处理一个 socket.io 数据包，返回连接是否应当结束
+func (s *EventStream) handleSocketPacket(ctx context.Context, session *pollingSession, packet string, authenticated *bool) (bool, error) {
+	if packet == "" {
+		return false, nil
+	}
+
+	switch packet[0] {
+	case socketConnect:
+		// 连接到命名空间后发送 token 进行认证
+		auth, err := encodeSocketEvent(eventAuth, s.client.token)
+		if err != nil {
+			return true, err
+		}
+		if err := session.send(ctx, auth); err != nil {
+			return true, err
+		}
+	case socketConnectError:
+		return true, fmt.Errorf("socket.io: connect error: %s", packet[1:])
+	case socketDisconnect:
+		return true, errSocketClosed
+	case socketEvent:
+		event, err := decodeSocketEvent(packet[1:])
+		if err != nil {
+			log.Printf("忽略无法解析的 Audiobookshelf 事件: %v", err)
+			return false, nil
+		}
+
+		switch event.Name {
+		case eventInit:
+			*authenticated = true
+			s.setConnected(true)
+			log.Println("已连接 Audiobookshelf 实时事件")
+			s.publish(Event{Name: EventConnected})
+		case eventInvalidToken, eventAuthFailed:
+			return true, &APIError{StatusCode: http.StatusUnauthorized, Method: "SOCKET", Endpoint: socketPath, Message: event.Name}
+		default:
+			s.publish(event)
+		}
+	}
+	return false, nil
+}
+
+// setConnected 更新连接状态
+func (s *EventStream) setConnected(connected bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.connected = connected
+}
+
+// publish 将事件发送给关注该事件的订阅方，不会因为某个订阅方处理缓慢而阻塞
+func (s *EventStream) publish(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subscriber := range s.subscribers {
+		if subscriber.names != nil && !subscriber.names[event.Name] {
+			continue
+		}
+		select {
+		case subscriber.ch <- event:
+		default:
+			log.Printf("事件订阅方处理不及时，丢弃事件 %s", event.Name)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// fakeSocketServer 模拟 Audiobookshelf 的 socket.io 长轮询服务端
+type fakeSocketServer struct {
+	token string
+
+	mu      sync.Mutex
+	pending []string
+	ready   chan struct{}
+}
+
+func newFakeSocketServer(token string) *httptest.Server {
+	fake := &fakeSocketServer{token: token, ready: make(chan struct{}, 1)}
+	return httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+}
+
+func (f *fakeSocketServer) push(packets ...string) {
+	f.mu.Lock()
+	f.pending = append(f.pending, packets...)
+	f.mu.Unlock()
+
+	select {
+	case f.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (f *fakeSocketServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if r.URL.Path != socketPath || query.Get("EIO") != "4" || query.Get("transport") != "polling" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if query.Get("sid") == "" {
+		w.Write([]byte(`0{"sid":"sid_1","upgrades":[],"pingInterval":1000,"pingTimeout":1000}`))
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		body, _ := io.ReadAll(r.Body)
+		for _, packet := range strings.Split(string(body), packetSeparator) {
+			switch {
+			case packet == "40":
+				f.push(`40{"sid":"socket_1"}`)
+			case packet == `42["auth","`+f.token+`"]`:
+				f.push(`42["init",{"usersOnline":[]}]`, `42["item_added",{"id":"li_1","libraryId":"lib_1"}]`)
+			case strings.HasPrefix(packet, `42["auth"`):
+				f.push(`42["invalid_token"]`)
+			}
+		}
+		w.Write([]byte("ok"))
+		return
+	}
+
+	// 长轮询：有数据时立即返回，否则模拟服务器的 ping
+	select {
+	case <-f.ready:
+	case <-time.After(200 * time.Millisecond):
+		f.push("2")
+	case <-r.Context().Done():
+		return
+	}
+	f.mu.Lock()
+	packets := f.pending
+	f.pending = nil
+	f.mu.Unlock()
+	w.Write([]byte(strings.Join(packets, packetSeparator)))
+}
+
+func TestEventStreamDeliversEvents(t *testing.T) {
+	server := newFakeSocketServer("good-token")
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL, AudiobookshelfToken: "good-token"})
+	stream := NewEventStream(client)
+	items, cancelItems := stream.Subscribe(10, EventItemAdded)
+	defer cancelItems()
+	all, cancelAll := stream.Subscribe(10)
+	defer cancelAll()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- stream.Run(ctx) }()
+
+	select {
+	case event := <-items:
+		var item models.LibraryItem
+		if err := event.Decode(&item); err != nil || item.ID != "li_1" || item.LibraryID != "lib_1" {
+			t.Errorf("事件数据解析不正确: %+v, %v", item, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("未收到 item_added 事件")
+	}
+
+	if event := <-all; event.Name != EventConnected {
+		t.Errorf("第一个事件应为 %s，实际为 %s", EventConnected, event.Name)
+	}
+	if !stream.Connected() {
+		t.Error("认证成功后应处于已连接状态")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("取消后期望返回 context.Canceled，实际为 %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("取消后 Run 没有返回")
+	}
+	if stream.Connected() {
+		t.Error("断开后不应处于已连接状态")
+	}
+}
+
+func TestEventStreamInvalidToken(t *testing.T) {
+	server := newFakeSocketServer("good-token")
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL, AudiobookshelfToken: "bad-token"})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := NewEventStream(client).Run(ctx); !IsUnauthorized(err) {
+		t.Errorf("token 被拒绝时期望返回 ErrUnauthorized，实际为 %v", err)
+	}
+}
+
+func TestOpenPollingTimeout(t *testing.T) {
+	// 模拟一个握手时长时间无响应的服务器
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL, RequestTimeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := client.openPolling(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望握手超时返回 context.DeadlineExceeded，实际为 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("握手未按配置超时，耗时 %s", elapsed)
+	}
+}
+
+func TestDecodeSocketEvent(t *testing.T) {
+	tests := []struct {
+		packet   string
+		wantName string
+		wantData string
+	}{
+		{`["scan_complete",{"id":"lib_1"}]`, "scan_complete", `{"id":"lib_1"}`},
+		{`12["user_online",{"id":"usr_1"}]`, "user_online", `{"id":"usr_1"}`},
+		{`/admin,["init"]`, "init", ""},
+	}
+
+	for _, tt := range tests {
+		event, err := decodeSocketEvent(tt.packet)
+		if err != nil {
+			t.Errorf("解析 %q 失败: %v", tt.packet, err)
+			continue
+		}
+		if event.Name != tt.wantName || string(event.Data) != tt.wantData {
+			t.Errorf("解析 %q 得到 %s %s", tt.packet, event.Name, event.Data)
+		}
+	}
+
+	if _, err := decodeSocketEvent(`not json`); err == nil {
+		t.Error("无效数据包应返回错误")
+	}
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// OpenItemFile 打开条目中的一个文件，返回文件内容的数据流，调用方读取完毕后必须关闭
+// ino 为文件的 inode，即 models.AudioFile 或 models.LibraryFile 的 Ino
+// 文件可能很大，整个下载不受客户端超时时间的限制，也不会重试；
+// 但单次读取等待超过客户端超时时间仍没有数据时会中止，读取返回 context.DeadlineExceeded
+// 两次读取之间（例如调用方正在上传已读取的数据）不计时
+func (c *Client) OpenItemFile(ctx context.Context, itemID, ino string) (io.ReadCloser, error) {
+	endpoint := fmt.Sprintf("/api/items/%s/file/%s", url.PathEscape(itemID), url.PathEscape(ino))
+	return c.openStream(ctx, endpoint)
+}
+
+// openStream 发送 GET 请求并返回响应的数据流，响应状态码不是 2xx 时返回 APIError
+// 等待响应头和每次读取数据时，超过 c.timeout 没有进展就取消请求
+func (c *Client) openStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	idle := newIdleTimer(c.timeout, cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		idle.stop()
+		cancel()
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		idle.stop()
+		cancel()
+		err = &APIError{Method: http.MethodGet, Endpoint: endpointOf(path), Err: idle.wrap(err)}
+		c.breaker.record(err)
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer cancel()
+		defer idle.stop()
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		err := newAPIError(http.MethodGet, path, resp.StatusCode, respBody)
+		c.breaker.record(err)
+		return nil, err
+	}
+
+	// 计时器只在 Read 阻塞期间运行
+	idle.stop()
+	c.breaker.record(nil)
+	return &idleReader{body: resp.Body, idle: idle, cancel: cancel, endpoint: endpointOf(path)}, nil
+}
+
+// idleTimer 在等待超过 timeout 时取消请求，timeout 为 0 时不限制
+// 创建后立即开始计时，之后由 start 和 stop 控制
+type idleTimer struct {
+	timeout time.Duration
+	timer   *time.Timer
+
+	mu      sync.Mutex
+	expired bool
+}
+
+// newIdleTimer 创建并启动计时器，超时后调用 cancel
+func newIdleTimer(timeout time.Duration, cancel context.CancelFunc) *idleTimer {
+	t := &idleTimer{timeout: timeout}
+	if timeout > 0 {
+		t.timer = time.AfterFunc(timeout, func() {
+			t.mu.Lock()
+			t.expired = true
+			t.mu.Unlock()
+			cancel()
+		})
+	}
+	return t
+}
+
+// start 开始等待数据，重新开始计时
+func (t *idleTimer) start() {
+	if t.timer != nil {
+		t.timer.Reset(t.timeout)
+	}
+}
+
+// stop 停止计时
+func (t *idleTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+// wrap 请求因超时被取消时，把取消导致的错误替换为 context.DeadlineExceeded
+func (t *idleTimer) wrap(err error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.expired {
+		return err
+	}
+	return fmt.Errorf("no data received for %s: %w", t.timeout, context.DeadlineExceeded)
+}
+
+// idleReader 响应的数据流，每次 Read 阻塞等待数据的时间不能超过空闲超时
+type idleReader struct {
+	body     io.ReadCloser
+	idle     *idleTimer
+	cancel   context.CancelFunc
+	endpoint string
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	r.idle.start()
+	n, err := r.body.Read(p)
+	r.idle.stop()
+	if err != nil && err != io.EOF {
+		err = &APIError{Method: http.MethodGet, Endpoint: r.endpoint, Err: r.idle.wrap(err)}
+	}
+	return n, err
+}
+
+func (r *idleReader) Close() error {
+	r.idle.stop()
+	r.cancel()
+	return r.body.Close()
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+)
+
+func TestOpenItemFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("请求没有携带 token: %q", r.Header.Get("Authorization"))
+		}
+		switch r.URL.Path {
+		case "/api/items/li_1/file/123":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Write([]byte("ID3 audio data"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL, AudiobookshelfToken: "test-token"})
+	ctx := context.Background()
+
+	file, err := client.OpenItemFile(ctx, "li_1", "123")
+	if err != nil {
+		t.Fatalf("打开文件失败: %v", err)
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil || string(data) != "ID3 audio data" {
+		t.Errorf("文件内容不正确: %q, %v", data, err)
+	}
+
+	if _, err := client.OpenItemFile(ctx, "li_1", "456"); !IsNotFound(err) {
+		t.Errorf("文件不存在时期望 ErrNotFound，实际为 %v", err)
+	}
+}
+
+func TestOpenItemFileIdleTimeout(t *testing.T) {
+	// 先发送一部分数据，之后长时间没有数据
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ID3"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL, RequestTimeout: 100 * time.Millisecond})
+	file, err := client.OpenItemFile(context.Background(), "li_1", "123")
+	if err != nil {
+		t.Fatalf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	start := time.Now()
+	data, err := io.ReadAll(file)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望长时间没有数据时返回 context.DeadlineExceeded，实际为 %v", err)
+	}
+	if string(data) != "ID3" {
+		t.Errorf("超时前收到的数据不正确: %q", data)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("读取未按配置超时，耗时 %s", elapsed)
+	}
+}
+
+func TestOpenItemFileSlowStream(t *testing.T) {
+	// 总耗时超过超时时间，但一直有数据，不应中止
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 8; i++ {
+			w.Write([]byte("x"))
+			w.(http.Flusher).Flush()
+			time.Sleep(25 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL, RequestTimeout: 100 * time.Millisecond})
+	file, err := client.OpenItemFile(context.Background(), "li_1", "123")
+	if err != nil {
+		t.Fatalf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	if data, err := io.ReadAll(file); err != nil || len(data) != 8 {
+		t.Errorf("期望完整读取 8 字节，实际为 %d 字节, %v", len(data), err)
+	}
+}
+
+func TestOpenItemFileConsumerStall(t *testing.T) {
+	// 调用方在两次读取之间停顿（例如上传到 Telegram），停顿时间不应计入空闲超时
+	resume := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ID3"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-resume:
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte(" more"))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL, RequestTimeout: 50 * time.Millisecond})
+	file, err := client.OpenItemFile(context.Background(), "li_1", "123")
+	if err != nil {
+		t.Fatalf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	head := make([]byte, 3)
+	if _, err := io.ReadFull(file, head); err != nil {
+		t.Fatalf("读取开头失败: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	close(resume)
+
+	rest, err := io.ReadAll(file)
+	if err != nil || string(rest) != " more" {
+		t.Errorf("停顿后期望继续读取到剩余数据，实际为 %q, %v", rest, err)
+	}
+}
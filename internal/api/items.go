@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// GetLibraryItem 获取单个条目的详细信息
+// expanded 为 true 时返回章节、音轨和音频文件等完整信息
+func (c *Client) GetLibraryItem(ctx context.Context, itemID string, expanded bool) (*models.LibraryItem, error) {
+	endpoint := fmt.Sprintf("/api/items/%s", url.PathEscape(itemID))
+	if expanded {
+		endpoint += "?expanded=1"
+	}
+
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var item models.LibraryItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("error unmarshaling library item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// GetItemCover 获取条目封面图片的原始数据，条目没有封面时返回 ErrNotFound
+func (c *Client) GetItemCover(ctx context.Context, itemID string) ([]byte, error) {
+	endpoint := fmt.Sprintf("/api/items/%s/cover", url.PathEscape(itemID))
+	return c.doRequest(ctx, "GET", endpoint, nil)
+}
+
+// GetMediaProgress 获取当前用户在指定条目上的播放进度，从未播放过时返回 ErrNotFound
+func (c *Client) GetMediaProgress(ctx context.Context, itemID string) (*models.MediaProgress, error) {
+	endpoint := fmt.Sprintf("/api/me/progress/%s", url.PathEscape(itemID))
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var progress models.MediaProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("error unmarshaling media progress: %w", err)
+	}
+
+	return &progress, nil
+}
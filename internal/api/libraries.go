@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// GetLibrary 获取单个媒体库的信息
+// 与 GetLibrariesInfo 不同，结果不经过缓存，上次扫描时间等信息是最新的
+func (c *Client) GetLibrary(ctx context.Context, libraryID string) (*models.LibraryInfo, error) {
+	endpoint := fmt.Sprintf("/api/libraries/%s", url.PathEscape(libraryID))
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var library models.LibraryInfo
+	if err := json.Unmarshal(data, &library); err != nil {
+		return nil, fmt.Errorf("error unmarshaling library: %w", err)
+	}
+
+	return &library, nil
+}
+
+// GetLibraryStats 获取媒体库的统计信息，包括总大小、总时长以及条目最多的作者和流派
+func (c *Client) GetLibraryStats(ctx context.Context, libraryID string) (*models.LibraryStats, error) {
+	endpoint := fmt.Sprintf("/api/libraries/%s/stats", url.PathEscape(libraryID))
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats models.LibraryStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("error unmarshaling library stats: %w", err)
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+)
+
+func TestGetLibraryStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/libraries/lib1":
+			w.Write([]byte(`{"id":"lib1","name":"有声书","mediaType":"book","lastScan":1700000000000,"folders":[{"id":"fol1","path":"/audiobooks"}]}`))
+		case "/api/libraries/lib1/stats":
+			w.Write([]byte(`{
+				"totalItems": 2,
+				"totalAuthors": 1,
+				"totalGenres": 1,
+				"totalDuration": 7200.5,
+				"totalSize": 1048576,
+				"numAudioTracks": 12,
+				"longestItems": [{"id":"li1","title":"三体","duration":7000}],
+				"largestItems": [{"id":"li1","title":"三体","size":1000000}],
+				"authorsWithCount": [{"id":"aut1","name":"刘慈欣","count":2}],
+				"genresWithCount": [{"genre":"科幻","count":2}]
+			}`))
+		default:
+			t.Errorf("意外的请求路径: %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+
+	library, err := client.GetLibrary(context.Background(), "lib1")
+	if err != nil {
+		t.Fatalf("获取媒体库失败: %v", err)
+	}
+	if library.Name != "有声书" || len(library.Folders) != 1 || library.Folders[0].Path != "/audiobooks" {
+		t.Errorf("媒体库信息不正确: %+v", library)
+	}
+
+	stats, err := client.GetLibraryStats(context.Background(), "lib1")
+	if err != nil {
+		t.Fatalf("获取媒体库统计失败: %v", err)
+	}
+	if stats.TotalItems != 2 || stats.TotalSize != 1048576 || stats.TotalDuration != 7200.5 {
+		t.Errorf("统计信息不正确: %+v", stats)
+	}
+	if len(stats.LargestItems) != 1 || stats.LargestItems[0].Size != 1000000 {
+		t.Errorf("最大条目不正确: %+v", stats.LargestItems)
+	}
+	if len(stats.AuthorsWithCount) != 1 || stats.AuthorsWithCount[0].Name != "刘慈欣" {
+		t.Errorf("作者统计不正确: %+v", stats.AuthorsWithCount)
+	}
+	if len(stats.GenresWithCount) != 1 || stats.GenresWithCount[0].Genre != "科幻" {
+		t.Errorf("流派统计不正确: %+v", stats.GenresWithCount)
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// 条目过滤分组
+const (
+	FilterGenres    = "genres"
+	FilterTags      = "tags"
+	FilterSeries    = "series"
+	FilterAuthors   = "authors"
+	FilterProgress  = "progress"
+	FilterNarrators = "narrators"
+)
+
+// 按收听进度过滤时可用的值
+const (
+	ProgressFinished    = "finished"
+	ProgressNotStarted  = "not-started"
+	ProgressNotFinished = "not-finished"
+	ProgressInProgress  = "in-progress"
+)
+
+// 常用排序字段
+const (
+	SortByTitle    = "media.metadata.title"
+	SortByAuthor   = "media.metadata.authorName"
+	SortByAddedAt  = "addedAt"
+	SortByDuration = "media.duration"
+	SortBySize     = "size"
+)
+
+// defaultIteratorPageSize 迭代器每页获取的条目数量
+const defaultIteratorPageSize = 100
+
+// ItemFilter 条目过滤条件
+// 系列和作者按 ID 过滤，其余分组按名称过滤
+type ItemFilter struct {
+	Group string
+	Value string
+}
+
+// encode 按 Audiobookshelf 的格式编码过滤条件：<分组>.<base64(值)>
+func (f ItemFilter) encode() string {
+	return f.Group + "." + base64.StdEncoding.EncodeToString([]byte(f.Value))
+}
+
+// LibraryItemsOptions 获取媒体库条目时的选项
+type LibraryItemsOptions struct {
+	// Limit 每页数量，0 表示一次返回全部
+	Limit int
+	// Page 页码，从 0 开始
+	Page int
+	// Sort 排序字段，例如 SortByTitle
+	Sort string
+	Desc bool
+	// Filter 过滤条件，为 nil 时不过滤
+	Filter *ItemFilter
+	// Minified 是否返回精简的条目信息
+	Minified bool
+}
+
+// query 将选项转换为查询参数
+func (o LibraryItemsOptions) query() url.Values {
+	params := url.Values{}
+	if o.Limit > 0 {
+		params.Set("limit", strconv.Itoa(o.Limit))
+		params.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.Sort != "" {
+		params.Set("sort", o.Sort)
+	}
+	if o.Desc {
+		params.Set("desc", "1")
+	}
+	if o.Filter != nil {
+		params.Set("filter", o.Filter.encode())
+	}
+	if o.Minified {
+		params.Set("minified", "1")
+	}
+	return params
+}
+
+// ListLibraryItems 分页获取媒体库中的条目
+func (c *Client) ListLibraryItems(ctx context.Context, libraryID string, opts LibraryItemsOptions) (*models.LibraryItemsPage, error) {
+	endpoint := fmt.Sprintf("/api/libraries/%s/items", url.PathEscape(libraryID))
+	if params := opts.query(); len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var page models.LibraryItemsPage
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("error unmarshaling library items: %w", err)
+	}
+
+	return &page, nil
+}
+
+// LibraryItemIterator 逐条遍历媒体库中的全部条目，按需分页请求
+//
+//	it := client.IterateLibraryItems(libraryID, opts)
+//	for it.Next(ctx) {
+//		item := it.Item()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type LibraryItemIterator struct {
+	client    *Client
+	libraryID string
+	opts      LibraryItemsOptions
+
+	items []models.LibraryItem
+	index int
+	total int
+	done  bool
+	err   error
+}
+
+// IterateLibraryItems 创建条目迭代器，opts.Limit 作为每页大小，opts.Page 作为起始页
+func (c *Client) IterateLibraryItems(libraryID string, opts LibraryItemsOptions) *LibraryItemIterator {
+	if opts.Limit <= 0 {
+		opts.Limit = defaultIteratorPageSize
+	}
+	return &LibraryItemIterator{
+		client:    c,
+		libraryID: libraryID,
+		opts:      opts,
+		index:     -1,
+	}
+}
+
+// Next 前进到下一条目，没有更多条目或出错时返回 false
+func (it *LibraryItemIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	if it.index < len(it.items) {
+		return true
+	}
+	if it.done {
+		return false
+	}
+
+	page, err := it.client.ListLibraryItems(ctx, it.libraryID, it.opts)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.items = page.Results
+	it.index = 0
+	it.total = page.Total
+	it.opts.Page++
+	if len(page.Results) < it.opts.Limit || it.opts.Page*it.opts.Limit >= page.Total {
+		it.done = true
+	}
+
+	return len(it.items) > 0
+}
+
+// Item 返回当前条目
+func (it *LibraryItemIterator) Item() models.LibraryItem {
+	return it.items[it.index]
+}
+
+// Total 返回服务器报告的条目总数，第一次调用 Next 之后有效
+func (it *LibraryItemIterator) Total() int {
+	return it.total
+}
+
+// Err 返回遍历过程中遇到的错误
+func (it *LibraryItemIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+func TestListLibraryItemsQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/libraries/lib1/items" {
+			t.Errorf("请求路径不正确: %s", r.URL.Path)
+		}
+
+		q := r.URL.Query()
+		expected := map[string]string{
+			"limit":    "20",
+			"page":     "2",
+			"sort":     SortByTitle,
+			"desc":     "1",
+			"minified": "1",
+			"filter":   "genres." + base64.StdEncoding.EncodeToString([]byte("科幻")),
+		}
+		for key, want := range expected {
+			if got := q.Get(key); got != want {
+				t.Errorf("参数 %s 期望为 %q，实际为 %q", key, want, got)
+			}
+		}
+
+		w.Write([]byte(`{"results":[{"id":"li1","mediaType":"book","media":{"metadata":{"title":"三体","authorName":"刘慈欣"}}}],"total":41,"limit":20,"page":2}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	page, err := client.ListLibraryItems(context.Background(), "lib1", LibraryItemsOptions{
+		Limit:    20,
+		Page:     2,
+		Sort:     SortByTitle,
+		Desc:     true,
+		Filter:   &ItemFilter{Group: FilterGenres, Value: "科幻"},
+		Minified: true,
+	})
+	if err != nil {
+		t.Fatalf("获取条目失败: %v", err)
+	}
+
+	if page.Total != 41 || len(page.Results) != 1 {
+		t.Fatalf("分页信息不正确: %+v", page)
+	}
+	if title := page.Results[0].Title(); title != "三体" {
+		t.Errorf("期望标题为 '三体'，实际得到 '%s'", title)
+	}
+}
+
+func TestIterateLibraryItems(t *testing.T) {
+	const total = 7
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		var results []models.LibraryItem
+		for i := page * limit; i < total && i < (page+1)*limit; i++ {
+			results = append(results, models.LibraryItem{ID: strconv.Itoa(i)})
+		}
+		json.NewEncoder(w).Encode(models.LibraryItemsPage{Results: results, Total: total, Limit: limit, Page: page})
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	it := client.IterateLibraryItems("lib1", LibraryItemsOptions{Limit: 3})
+
+	var ids []string
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Item().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("遍历条目失败: %v", err)
+	}
+
+	if len(ids) != total {
+		t.Fatalf("期望遍历 %d 个条目，实际得到 %d 个", total, len(ids))
+	}
+	for i, id := range ids {
+		if id != strconv.Itoa(i) {
+			t.Errorf("第 %d 个条目 ID 期望为 %d，实际为 %s", i, i, id)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("期望请求 3 页，实际请求 %d 次", requests)
+	}
+	if it.Total() != total {
+		t.Errorf("期望总数为 %d，实际为 %d", total, it.Total())
+	}
+}
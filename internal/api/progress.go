@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// MediaProgressUpdate 修改播放进度时提交的字段，为 nil 的字段保持不变
+// CurrentTime 和 Duration 以秒为单位，Progress 为 0 到 1 之间的比例
+type MediaProgressUpdate struct {
+	CurrentTime *float64 `json:"currentTime,omitempty"`
+	Duration    *float64 `json:"duration,omitempty"`
+	Progress    *float64 `json:"progress,omitempty"`
+	IsFinished  *bool    `json:"isFinished,omitempty"`
+	// HideFromContinueListening 是否从「继续收听」中隐藏
+	HideFromContinueListening *bool `json:"hideFromContinueListening,omitempty"`
+}
+
+// UpdateMediaProgress 修改当前用户在指定条目上的播放进度，条目没有进度时会新建
+func (c *Client) UpdateMediaProgress(ctx context.Context, itemID string, update MediaProgressUpdate) error {
+	endpoint := fmt.Sprintf("/api/me/progress/%s", url.PathEscape(itemID))
+	_, err := c.doRequest(ctx, "PATCH", endpoint, update)
+	return err
+}
+
+// RemoveMediaProgress 删除当前用户的一条播放进度，progressID 为 models.MediaProgress 的 ID 而不是条目 ID
+func (c *Client) RemoveMediaProgress(ctx context.Context, progressID string) error {
+	endpoint := fmt.Sprintf("/api/me/progress/%s", url.PathEscape(progressID))
+	_, err := c.doRequest(ctx, "DELETE", endpoint, nil)
+	return err
+}
+
+// GetItemsInProgress 获取当前用户正在收听的条目，按最后收听时间倒序排列，最多返回 limit 个
+func (c *Client) GetItemsInProgress(ctx context.Context, limit int) ([]models.LibraryItem, error) {
+	endpoint := fmt.Sprintf("/api/me/items-in-progress?limit=%d", limit)
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		LibraryItems []models.LibraryItem `json:"libraryItems"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshaling items in progress: %w", err)
+	}
+
+	return response.LibraryItems, nil
+}
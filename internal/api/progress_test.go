@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+)
+
+func TestMediaProgress(t *testing.T) {
+	var requests []string
+	var patch map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case http.MethodPatch:
+			data, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(data, &patch); err != nil {
+				t.Errorf("请求体不是有效的 JSON: %s", data)
+			}
+			w.Write([]byte("OK"))
+		case http.MethodDelete:
+			w.Write([]byte("OK"))
+		case http.MethodGet:
+			if r.URL.Query().Get("limit") != "10" {
+				t.Errorf("查询参数不正确: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"libraryItems":[{"id":"li_1","mediaType":"book","media":{"metadata":{"title":"三体"}}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	ctx := context.Background()
+
+	currentTime := 600.0
+	finished := false
+	if err := client.UpdateMediaProgress(ctx, "li_1", MediaProgressUpdate{CurrentTime: &currentTime, IsFinished: &finished}); err != nil {
+		t.Fatalf("修改播放进度失败: %v", err)
+	}
+	if patch["currentTime"] != 600.0 || patch["isFinished"] != false {
+		t.Errorf("修改请求的内容不正确: %v", patch)
+	}
+	for _, key := range []string{"duration", "progress", "hideFromContinueListening"} {
+		if _, ok := patch[key]; ok {
+			t.Errorf("未修改的字段 %s 不应提交", key)
+		}
+	}
+
+	if err := client.RemoveMediaProgress(ctx, "prog_1"); err != nil {
+		t.Fatalf("删除播放进度失败: %v", err)
+	}
+
+	items, err := client.GetItemsInProgress(ctx, 10)
+	if err != nil {
+		t.Fatalf("获取正在收听的条目失败: %v", err)
+	}
+	if len(items) != 1 || items[0].Title() != "三体" {
+		t.Errorf("正在收听的条目不正确: %+v", items)
+	}
+
+	want := []string{"PATCH /api/me/progress/li_1", "DELETE /api/me/progress/prog_1", "GET /api/me/items-in-progress"}
+	if len(requests) != len(want) {
+		t.Fatalf("期望 %d 个请求，实际为 %v", len(want), requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("第 %d 个请求期望为 %s，实际为 %s", i+1, want[i], requests[i])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy 幂等请求（GET）失败后的重试策略
+type RetryPolicy struct {
+	// MaxAttempts 包含首次请求在内的最大尝试次数，小于等于 1 表示不重试
+	MaxAttempts int
+	// InitialBackoff 第一次重试前的等待时间
+	InitialBackoff time.Duration
+	// MaxBackoff 单次等待时间的上限
+	MaxBackoff time.Duration
+	// Multiplier 每次重试等待时间的增长倍数
+	Multiplier float64
+}
+
+// backoff 计算第 attempt 次重试前的等待时间（从 1 开始）
+// 使用指数退避并在 [d/2, d] 范围内加入随机抖动，避免多个请求同时重试
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= p.Multiplier
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			d = float64(p.MaxBackoff)
+			break
+		}
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return time.Duration(half + rand.Float64()*half)
+}
+
+// isRetryable 判断请求失败后是否值得重试
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.StatusCode {
+	case 0:
+		return isTransientNetworkError(apiErr.Err)
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTransientNetworkError 判断网络错误是否可能是暂时性的（超时、连接被重置等）
+func isTransientNetworkError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// sleepContext 等待指定时间，ctx 被取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+)
+
+// newFlakyServer 创建一个前 failures 次请求返回 503 的测试服务器
+func newFlakyServer(failures int32) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"libraries":[]}`))
+	}))
+	return server, &hits
+}
+
+func TestRetryOnServerError(t *testing.T) {
+	server, hits := newFlakyServer(2)
+	defer server.Close()
+
+	client := NewClient(&config.Config{
+		AudiobookshelfURL:   server.URL,
+		RetryMaxAttempts:    3,
+		RetryInitialBackoff: time.Millisecond,
+	})
+
+	if _, err := client.GetLibraries(context.Background()); err != nil {
+		t.Fatalf("期望重试后成功，实际得到错误: %v", err)
+	}
+	if got := atomic.LoadInt32(hits); got != 3 {
+		t.Errorf("期望请求 3 次，实际请求 %d 次", got)
+	}
+}
+
+func TestRetryNotAppliedToNonIdempotentRequests(t *testing.T) {
+	server, hits := newFlakyServer(1)
+	defer server.Close()
+
+	client := NewClient(&config.Config{
+		AudiobookshelfURL:   server.URL,
+		RetryMaxAttempts:    3,
+		RetryInitialBackoff: time.Millisecond,
+	})
+
+	if _, err := client.DoRequestRaw(context.Background(), "POST", "/api/libraries", nil); err == nil {
+		t.Fatal("期望 POST 请求失败后直接返回错误")
+	}
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("期望 POST 请求只发送 1 次，实际发送 %d 次", got)
+	}
+}
+
+func TestRetryNotAppliedToClientErrors(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{
+		AudiobookshelfURL:   server.URL,
+		RetryMaxAttempts:    3,
+		RetryInitialBackoff: time.Millisecond,
+	})
+
+	if _, err := client.GetLibraries(context.Background()); !IsUnauthorized(err) {
+		t.Fatalf("期望得到未授权错误，实际得到 %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("期望 401 不重试，实际请求 %d 次", got)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	server, hits := newFlakyServer(2)
+	defer server.Close()
+
+	client := NewClient(&config.Config{
+		AudiobookshelfURL: server.URL,
+		RetryMaxAttempts:  1,
+		BreakerThreshold:  2,
+		BreakerCooldown:   time.Minute,
+	})
+
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetLibraries(context.Background()); !IsServerUnavailable(err) {
+			t.Fatalf("第 %d 次请求期望服务器不可用，实际得到 %v", i+1, err)
+		}
+	}
+
+	if state := client.BreakerStatus().State; state != BreakerOpen {
+		t.Fatalf("期望熔断器打开，实际状态为 %s", state)
+	}
+
+	// 冷却期内请求不应发送到服务器
+	if _, err := client.GetLibraries(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("期望得到 ErrCircuitOpen，实际得到 %v", err)
+	}
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("熔断期间不应发送请求，实际请求 %d 次", got)
+	}
+
+	// 冷却期结束后允许试探请求，成功后熔断器关闭
+	now = now.Add(2 * time.Minute)
+	if _, err := client.GetLibraries(context.Background()); err != nil {
+		t.Fatalf("期望试探请求成功，实际得到 %v", err)
+	}
+	if state := client.BreakerStatus().State; state != BreakerClosed {
+		t.Errorf("期望熔断器关闭，实际状态为 %s", state)
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// 重新扫描单个条目的结果
+const (
+	ItemScanNothing  = "NOTHING"
+	ItemScanAdded    = "ADDED"
+	ItemScanUpdated  = "UPDATED"
+	ItemScanRemoved  = "REMOVED"
+	ItemScanUpToDate = "UPTODATE"
+)
+
+// ScanLibrary 开始扫描媒体库，需要管理员权限
+// 扫描在服务器后台进行，请求立即返回；进度通过 EventScanStart、EventScanComplete 等实时事件推送
+// force 为 true 时重新扫描所有文件，而不只是有变化的文件
+func (c *Client) ScanLibrary(ctx context.Context, libraryID string, force bool) error {
+	endpoint := fmt.Sprintf("/api/libraries/%s/scan", url.PathEscape(libraryID))
+	if force {
+		endpoint += "?force=1"
+	}
+
+	_, err := c.doRequest(ctx, "POST", endpoint, nil)
+	return err
+}
+
+// ScanItem 重新扫描单个条目，返回扫描结果（ItemScanUpdated 等），需要管理员权限
+func (c *Client) ScanItem(ctx context.Context, itemID string) (string, error) {
+	endpoint := fmt.Sprintf("/api/items/%s/scan", url.PathEscape(itemID))
+	data, err := c.doRequest(ctx, "POST", endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Result string `json:"result"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("error unmarshaling scan result: %w", err)
+	}
+
+	return response.Result, nil
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// searchResponse /api/libraries/{id}/search 接口的响应
+type searchResponse struct {
+	Book []struct {
+		LibraryItem models.LibraryItem `json:"libraryItem"`
+	} `json:"book"`
+	Podcast []struct {
+		LibraryItem models.LibraryItem `json:"libraryItem"`
+	} `json:"podcast"`
+	Authors   []models.Author         `json:"authors"`
+	Series    []models.SeriesResult   `json:"series"`
+	Tags      []models.TagResult      `json:"tags"`
+	Narrators []models.NarratorResult `json:"narrators"`
+}
+
+// searchLibrary 在单个媒体库中搜索，返回全部类别的结果
+func (c *Client) searchLibrary(ctx context.Context, libraryID string, params url.Values) (*models.SearchResults, error) {
+	endpoint := fmt.Sprintf("/api/libraries/%s/search?%s", url.PathEscape(libraryID), params.Encode())
+
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshaling search results: %w", err)
+	}
+
+	results := &models.SearchResults{}
+	for _, result := range response.Book {
+		results.Books = append(results.Books, withLibraryID(result.LibraryItem, libraryID))
+	}
+	for _, result := range response.Podcast {
+		results.Podcasts = append(results.Podcasts, withLibraryID(result.LibraryItem, libraryID))
+	}
+	for _, author := range response.Authors {
+		if author.LibraryID == "" {
+			author.LibraryID = libraryID
+		}
+		results.Authors = append(results.Authors, author)
+	}
+	for _, series := range response.Series {
+		if series.Series.LibraryID == "" {
+			series.Series.LibraryID = libraryID
+		}
+		results.Series = append(results.Series, series)
+	}
+	// 标签和朗读者在接口中只有名称，记录所属的媒体库以便之后按名称过滤
+	for _, tag := range response.Tags {
+		tag.LibraryIDs = []string{libraryID}
+		results.Tags = append(results.Tags, tag)
+	}
+	for _, narrator := range response.Narrators {
+		narrator.LibraryIDs = []string{libraryID}
+		results.Narrators = append(results.Narrators, narrator)
+	}
+
+	return results, nil
+}
+
+// withLibraryID 部分版本的搜索接口不返回条目的 libraryId，此时补上
+func withLibraryID(item models.LibraryItem, libraryID string) models.LibraryItem {
+	if item.LibraryID == "" {
+		item.LibraryID = libraryID
+	}
+	return item
+}
+
+// Search 在媒体库中搜索图书、播客、作者、系列、标签和朗读者
+// libraryID 为空时并行搜索所有媒体库并合并结果
+func (c *Client) Search(ctx context.Context, term string, libraryID string) (*models.SearchResults, error) {
+	params := url.Values{}
+	params.Add("q", term)
+
+	// 如果指定了特定的媒体库ID，则只搜索该库
+	if libraryID != "" {
+		return c.searchLibrary(ctx, libraryID, params)
+	}
+
+	// 如果没有指定特定的媒体库ID，则搜索所有库，使用并行处理
+	libraries, err := c.GetLibrariesInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取媒体库列表失败: %w", err)
+	}
+
+	// 使用并行处理，最大并发数为4
+	const maxConcurrency = 4
+	semaphore := make(chan struct{}, maxConcurrency)
+
+	merger := newSearchMerger()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	// 记录第一个错误，所有库都搜索失败时返回该错误，而不是空结果
+	var firstErr error
+	succeeded := 0
+
+	for _, lib := range libraries {
+		wg.Add(1)
+		go func(lib models.LibraryInfo) {
+			defer wg.Done()
+
+			// 控制并发数，等待期间如果请求被取消则直接放弃
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-semaphore }()
+
+			results, err := c.searchLibrary(ctx, lib.ID, params)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				// 继续搜索下一个库而不是完全失败
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+
+			succeeded++
+			merger.add(results)
+		}(lib)
+	}
+
+	// 等待所有goroutine完成
+	wg.Wait()
+
+	// 如果请求已被取消，返回取消原因而不是不完整的结果
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if succeeded == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+
+	return merger.results, nil
+}
+
+// SearchBooks 搜索图书，只返回图书类别的结果
+func (c *Client) SearchBooks(ctx context.Context, term string, libraryID string) ([]models.LibraryItem, error) {
+	results, err := c.Search(ctx, term, libraryID)
+	if err != nil {
+		return nil, err
+	}
+	return results.Books, nil
+}
+
+// searchMerger 合并多个媒体库的搜索结果并去重
+type searchMerger struct {
+	results *models.SearchResults
+
+	// 条目、作者和系列按 ID 去重
+	seenItems   map[string]bool
+	seenAuthors map[string]bool
+	seenSeries  map[string]bool
+	// 标签和朗读者按名称合并，值为在结果中的下标
+	tagIndex      map[string]int
+	narratorIndex map[string]int
+}
+
+func newSearchMerger() *searchMerger {
+	return &searchMerger{
+		results:       &models.SearchResults{},
+		seenItems:     make(map[string]bool),
+		seenAuthors:   make(map[string]bool),
+		seenSeries:    make(map[string]bool),
+		tagIndex:      make(map[string]int),
+		narratorIndex: make(map[string]int),
+	}
+}
+
+// add 合并一个媒体库的搜索结果
+func (m *searchMerger) add(results *models.SearchResults) {
+	m.results.Books = m.addItems(m.results.Books, results.Books)
+	m.results.Podcasts = m.addItems(m.results.Podcasts, results.Podcasts)
+
+	for _, author := range results.Authors {
+		if !m.seenAuthors[author.ID] {
+			m.seenAuthors[author.ID] = true
+			m.results.Authors = append(m.results.Authors, author)
+		}
+	}
+	for _, series := range results.Series {
+		if !m.seenSeries[series.Series.ID] {
+			m.seenSeries[series.Series.ID] = true
+			m.results.Series = append(m.results.Series, series)
+		}
+	}
+
+	for _, tag := range results.Tags {
+		if i, ok := m.tagIndex[tag.Name]; ok {
+			merged := &m.results.Tags[i]
+			merged.NumItems += tag.NumItems
+			merged.LibraryIDs = append(merged.LibraryIDs, tag.LibraryIDs...)
+			continue
+		}
+		m.tagIndex[tag.Name] = len(m.results.Tags)
+		m.results.Tags = append(m.results.Tags, tag)
+	}
+	for _, narrator := range results.Narrators {
+		if i, ok := m.narratorIndex[narrator.Name]; ok {
+			merged := &m.results.Narrators[i]
+			merged.NumBooks += narrator.NumBooks
+			merged.LibraryIDs = append(merged.LibraryIDs, narrator.LibraryIDs...)
+			continue
+		}
+		m.narratorIndex[narrator.Name] = len(m.results.Narrators)
+		m.results.Narrators = append(m.results.Narrators, narrator)
+	}
+}
+
+// addItems 将未出现过的条目追加到 dst
+func (m *searchMerger) addItems(dst, items []models.LibraryItem) []models.LibraryItem {
+	for _, item := range items {
+		if !m.seenItems[item.ID] {
+			m.seenItems[item.ID] = true
+			dst = append(dst, item)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+)
+
+func TestSearchMergesLibraries(t *testing.T) {
+	responses := map[string]string{
+		"/api/libraries/lib1/search": `{
+			"book":[{"libraryItem":{"id":"li1","mediaType":"book","media":{"metadata":{"title":"三体"}}}}],
+			"authors":[{"id":"au1","name":"刘慈欣","numBooks":3}],
+			"series":[{"series":{"id":"se1","name":"地球往事"},"books":[]}],
+			"tags":[{"name":"科幻","numItems":2}],
+			"narrators":[{"name":"冯雪松","numBooks":1}]
+		}`,
+		"/api/libraries/lib2/search": `{
+			"book":[{"libraryItem":{"id":"li1","libraryId":"lib2","mediaType":"book","media":{"metadata":{"title":"三体"}}}}],
+			"podcast":[{"libraryItem":{"id":"li2","mediaType":"podcast","media":{"metadata":{"title":"科幻电台"}}}}],
+			"tags":[{"name":"科幻","numItems":5}]
+		}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/libraries" {
+			w.Write([]byte(`{"libraries":[{"id":"lib1","name":"图书"},{"id":"lib2","name":"播客"}]}`))
+			return
+		}
+		if q := r.URL.Query().Get("q"); q != "科幻" {
+			t.Errorf("搜索词不正确: %q", q)
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	results, err := client.Search(context.Background(), "科幻", "")
+	if err != nil {
+		t.Fatalf("搜索失败: %v", err)
+	}
+
+	if len(results.Books) != 1 || len(results.Podcasts) != 1 {
+		t.Fatalf("期望 1 本图书和 1 个播客，实际 %d 本图书和 %d 个播客", len(results.Books), len(results.Podcasts))
+	}
+	if results.Podcasts[0].Podcast == nil || results.Podcasts[0].LibraryID != "lib2" {
+		t.Errorf("播客条目解析不正确: %+v", results.Podcasts[0])
+	}
+	if len(results.Authors) != 1 || results.Authors[0].LibraryID != "lib1" {
+		t.Errorf("作者应补上所属媒体库: %+v", results.Authors)
+	}
+	if len(results.Series) != 1 || results.Series[0].Series.LibraryID != "lib1" {
+		t.Errorf("系列应补上所属媒体库: %+v", results.Series)
+	}
+	if len(results.Narrators) != 1 {
+		t.Errorf("期望 1 个朗读者，实际 %d 个", len(results.Narrators))
+	}
+
+	// 同名标签合并为一个，并记录出现过的所有媒体库
+	if len(results.Tags) != 1 {
+		t.Fatalf("期望同名标签被合并，实际 %d 个", len(results.Tags))
+	}
+	if tag := results.Tags[0]; tag.NumItems != 7 || len(tag.LibraryIDs) != 2 {
+		t.Errorf("标签合并结果不正确: %+v", tag)
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// GetSessions 分页获取所有用户的收听会话，按最后更新时间倒序排列（需要管理员权限）
+// 返回的会话包含所属用户的简要信息
+func (c *Client) GetSessions(ctx context.Context, page, itemsPerPage int) (*models.ListeningSessionsPage, error) {
+	params := url.Values{}
+	params.Set("page", fmt.Sprint(page))
+	params.Set("itemsPerPage", fmt.Sprint(itemsPerPage))
+	params.Set("sort", "updatedAt")
+	params.Set("desc", "1")
+	endpoint := "/api/sessions?" + params.Encode()
+
+	data, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions models.ListeningSessionsPage
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("error unmarshaling sessions: %w", err)
+	}
+
+	return &sessions, nil
+}
+
+// DeleteSession 删除收听会话，需要管理员权限
+// 会话仍在进行时服务器会先关闭它，用于清理客户端异常退出后遗留的会话
+func (c *Client) DeleteSession(ctx context.Context, sessionID string) error {
+	endpoint := fmt.Sprintf("/api/sessions/%s", url.PathEscape(sessionID))
+	_, err := c.doRequest(ctx, "DELETE", endpoint, nil)
+	return err
+}
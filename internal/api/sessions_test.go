@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+)
+
+func TestSessions(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			query := r.URL.Query()
+			if query.Get("sort") != "updatedAt" || query.Get("desc") != "1" || query.Get("itemsPerPage") != "5" {
+				t.Errorf("查询参数不正确: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{
+				"total": 1, "numPages": 1, "page": 0, "itemsPerPage": 5,
+				"sessions": [{"id":"ses_1","userId":"usr_1","displayTitle":"三体","user":{"id":"usr_1","username":"alice"}}]
+			}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	ctx := context.Background()
+
+	page, err := client.GetSessions(ctx, 0, 5)
+	if err != nil {
+		t.Fatalf("获取收听会话失败: %v", err)
+	}
+	if len(page.Sessions) != 1 || page.Sessions[0].User == nil || page.Sessions[0].User.Username != "alice" {
+		t.Errorf("收听会话不正确: %+v", page.Sessions)
+	}
+
+	if err := client.DeleteSession(ctx, "ses_1"); err != nil {
+		t.Fatalf("删除收听会话失败: %v", err)
+	}
+
+	want := []string{"GET /api/sessions", "DELETE /api/sessions/ses_1"}
+	if len(requests) != len(want) {
+		t.Fatalf("期望 %d 个请求，实际为 %v", len(want), requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("第 %d 个请求期望为 %s，实际为 %s", i+1, want[i], requests[i])
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// engine.io（协议版本 4）数据包类型
+const (
+	engineOpen    = '0'
+	engineClose   = '1'
+	enginePing    = '2'
+	enginePong    = '3'
+	engineMessage = '4'
+	engineNoop    = '6'
+)
+
+// socket.io 数据包类型，位于 engine.io message 数据包之后
+const (
+	socketConnect      = '0'
+	socketDisconnect   = '1'
+	socketEvent        = '2'
+	socketConnectError = '4'
+)
+
+// packetSeparator 长轮询时一次响应中多个数据包之间的分隔符
+const packetSeparator = "\x1e"
+
+// socketPath socket.io 在 Audiobookshelf 服务器上的路径
+const socketPath = "/socket.io/"
+
+// engineHandshake engine.io 握手时服务器返回的参数
+type engineHandshake struct {
+	SID          string `json:"sid"`
+	PingInterval int    `json:"pingInterval"`
+	PingTimeout  int    `json:"pingTimeout"`
+}
+
+// pollingSession 一个使用 HTTP 长轮询传输的 engine.io 连接
+// 长轮询只依赖普通的 HTTP 请求，Audiobookshelf 的 socket.io 服务器默认支持
+type pollingSession struct {
+	client *Client
+	sid    string
+	// pollTimeout 单次长轮询的最长等待时间，超过后认为连接已断开
+	pollTimeout time.Duration
+}
+
+// openPolling 完成 engine.io 握手，返回新的长轮询连接
+// 握手与普通 API 请求一样受 API 超时时间限制
+func (c *Client) openPolling(ctx context.Context) (*pollingSession, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	session := &pollingSession{client: c, pollTimeout: time.Minute}
+	packets, err := session.do(ctx, http.MethodGet, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) == 0 || packets[0] == "" || packets[0][0] != engineOpen {
+		return nil, fmt.Errorf("socket.io: unexpected handshake response %q", strings.Join(packets, packetSeparator))
+	}
+
+	var handshake engineHandshake
+	if err := json.Unmarshal([]byte(packets[0][1:]), &handshake); err != nil || handshake.SID == "" {
+		return nil, fmt.Errorf("socket.io: invalid handshake: %q", packets[0])
+	}
+
+	session.sid = handshake.SID
+	if handshake.PingInterval > 0 {
+		// 服务器每隔 pingInterval 发送一次 ping，超过 pingInterval + pingTimeout 没有数据说明连接已断开
+		session.pollTimeout = time.Duration(handshake.PingInterval+handshake.PingTimeout) * time.Millisecond
+	}
+	return session, nil
+}
+
+// send 发送数据包，服务器收到后立即响应，受 API 超时时间限制
+func (s *pollingSession) send(ctx context.Context, packets ...string) error {
+	if s.client.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.client.timeout)
+		defer cancel()
+	}
+
+	_, err := s.do(ctx, http.MethodPost, strings.Join(packets, packetSeparator))
+	return err
+}
+
+// poll 等待服务器推送数据包，服务器最迟在下一次 ping 时返回
+func (s *pollingSession) poll(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.pollTimeout)
+	defer cancel()
+
+	return s.do(ctx, http.MethodGet, "")
+}
+
+// do 发送一次长轮询请求，返回响应中的数据包
+func (s *pollingSession) do(ctx context.Context, method, body string) ([]string, error) {
+	query := url.Values{}
+	query.Set("EIO", "4")
+	query.Set("transport", "polling")
+	query.Set("t", strconv.FormatInt(time.Now().UnixNano(), 36))
+	if s.sid != "" {
+		query.Set("sid", s.sid)
+	}
+
+	var reqBody io.Reader
+	if body != "" {
+		reqBody = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.client.baseURL+socketPath+"?"+query.Encode(), reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "text/plain;charset=UTF-8")
+	}
+
+	resp, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return nil, &APIError{Method: method, Endpoint: socketPath, Err: err}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &APIError{Method: method, Endpoint: socketPath, Err: fmt.Errorf("error reading response body: %w", err)}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newAPIError(method, socketPath, resp.StatusCode, data)
+	}
+
+	if method == http.MethodPost || len(data) == 0 {
+		return nil, nil
+	}
+	return strings.Split(string(data), packetSeparator), nil
+}
+
+// encodeSocketEvent 编码 socket.io 事件数据包
+func encodeSocketEvent(name string, args ...interface{}) (string, error) {
+	payload, err := json.Marshal(append([]interface{}{name}, args...))
+	if err != nil {
+		return "", fmt.Errorf("error marshaling socket.io event: %w", err)
+	}
+	return string([]byte{engineMessage, socketEvent}) + string(payload), nil
+}
+
+// decodeSocketEvent 解析 socket.io 事件数据包（不含 engine.io 和 socket.io 的类型前缀）
+// 格式为 [命名空间,][确认 ID]["事件名", 数据...]
+func decodeSocketEvent(packet string) (Event, error) {
+	if strings.HasPrefix(packet, "/") {
+		if i := strings.IndexByte(packet, ','); i >= 0 {
+			packet = packet[i+1:]
+		}
+	}
+	packet = strings.TrimLeft(packet, "0123456789")
+
+	var args []json.RawMessage
+	if err := json.Unmarshal([]byte(packet), &args); err != nil || len(args) == 0 {
+		return Event{}, fmt.Errorf("socket.io: invalid event packet %q", packet)
+	}
+
+	var event Event
+	if err := json.Unmarshal(args[0], &event.Name); err != nil {
+		return Event{}, fmt.Errorf("socket.io: invalid event name %q", args[0])
+	}
+	if len(args) > 1 {
+		event.Data = args[1]
+	}
+	return event, nil
+}
+
+// errSocketClosed 服务器关闭了 socket.io 连接
+var errSocketClosed = errors.New("socket.io: connection closed by server")
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// NewUser 创建用户时提交的信息
+type NewUser struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Type 用户类型，例如 models.UserTypeUser
+	Type     string `json:"type"`
+	IsActive bool   `json:"isActive"`
+	// Permissions 为 nil 时使用服务器对该用户类型的默认权限
+	Permissions *models.Permissions `json:"permissions,omitempty"`
+}
+
+// UserUpdate 修改用户时提交的字段，为 nil 的字段保持不变
+type UserUpdate struct {
+	Username    *string             `json:"username,omitempty"`
+	Password    *string             `json:"password,omitempty"`
+	Type        *string             `json:"type,omitempty"`
+	IsActive    *bool               `json:"isActive,omitempty"`
+	Permissions *models.Permissions `json:"permissions,omitempty"`
+	// LibrariesAccessible 权限中没有 AccessAllLibraries 时可以访问的媒体库
+	LibrariesAccessible *[]string `json:"librariesAccessible,omitempty"`
+	// ItemTagsSelected 权限中没有 AccessAllTags 时选择的标签
+	ItemTagsSelected *[]string `json:"itemTagsSelected,omitempty"`
+}
+
+// CreateUser 创建用户，需要管理员权限
+func (c *Client) CreateUser(ctx context.Context, user NewUser) (*models.UserInfo, error) {
+	data, err := c.doRequest(ctx, "POST", "/api/users", user)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		User models.UserInfo `json:"user"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshaling created user: %w", err)
+	}
+
+	return &response.User, nil
+}
+
+// UpdateUser 修改用户，返回修改后的用户信息，需要管理员权限
+// 只有 root 用户可以修改其他管理员
+func (c *Client) UpdateUser(ctx context.Context, userID string, update UserUpdate) (*models.UserInfo, error) {
+	endpoint := fmt.Sprintf("/api/users/%s", url.PathEscape(userID))
+	data, err := c.doRequest(ctx, "PATCH", endpoint, update)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		User models.UserInfo `json:"user"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshaling updated user: %w", err)
+	}
+
+	return &response.User, nil
+}
+
+// DeleteUser 删除用户，需要管理员权限；root 用户和当前用户不能被删除
+func (c *Client) DeleteUser(ctx context.Context, userID string) error {
+	endpoint := fmt.Sprintf("/api/users/%s", url.PathEscape(userID))
+	_, err := c.doRequest(ctx, "DELETE", endpoint, nil)
+	return err
+}
+
+// GetTags 获取所有媒体库中使用的标签，需要管理员权限
+func (c *Client) GetTags(ctx context.Context) ([]string, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshaling tags: %w", err)
+	}
+
+	return response.Tags, nil
+}
+
+// GetOnlineUsers 获取在线用户和进行中的播放会话，需要管理员权限
+func (c *Client) GetOnlineUsers(ctx context.Context) (*models.OnlineUsers, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/users/online", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var online models.OnlineUsers
+	if err := json.Unmarshal(data, &online); err != nil {
+		return nil, fmt.Errorf("error unmarshaling online users: %w", err)
+	}
+
+	return &online, nil
+}
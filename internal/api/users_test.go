@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+func TestUserAdministration(t *testing.T) {
+	var requests []string
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Errorf("请求体不是有效的 JSON: %s", data)
+			}
+		}
+		bodies = append(bodies, body)
+
+		switch r.Method {
+		case http.MethodPost:
+			w.Write([]byte(`{"user":{"id":"usr_new","username":"alice","type":"user","isActive":true}}`))
+		case http.MethodPatch:
+			w.Write([]byte(`{"success":true,"user":{"id":"usr_1","username":"bob","type":"guest","isActive":false}}`))
+		case http.MethodDelete:
+			w.Write([]byte(`{"success":true}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	ctx := context.Background()
+
+	created, err := client.CreateUser(ctx, NewUser{Username: "alice", Password: "secret", Type: models.UserTypeUser, IsActive: true})
+	if err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	if created.ID != "usr_new" || created.Username != "alice" {
+		t.Errorf("创建的用户不正确: %+v", created)
+	}
+	if _, ok := bodies[0]["permissions"]; ok {
+		t.Error("没有指定权限时不应提交 permissions")
+	}
+
+	active := false
+	userType := models.UserTypeGuest
+	libraries := []string{}
+	updated, err := client.UpdateUser(ctx, "usr_1", UserUpdate{IsActive: &active, Type: &userType, LibrariesAccessible: &libraries})
+	if err != nil {
+		t.Fatalf("修改用户失败: %v", err)
+	}
+	if updated.Type != models.UserTypeGuest || updated.IsActive {
+		t.Errorf("修改后的用户不正确: %+v", updated)
+	}
+	patch := bodies[1]
+	if patch["isActive"] != false || patch["type"] != "guest" {
+		t.Errorf("修改请求的内容不正确: %v", patch)
+	}
+	if list, ok := patch["librariesAccessible"].([]interface{}); !ok || len(list) != 0 {
+		t.Errorf("空的媒体库列表也应提交: %v", patch)
+	}
+	for _, key := range []string{"username", "password", "permissions", "itemTagsSelected"} {
+		if _, ok := patch[key]; ok {
+			t.Errorf("未修改的字段 %s 不应提交", key)
+		}
+	}
+
+	if err := client.DeleteUser(ctx, "usr_1"); err != nil {
+		t.Fatalf("删除用户失败: %v", err)
+	}
+
+	want := []string{"POST /api/users", "PATCH /api/users/usr_1", "DELETE /api/users/usr_1"}
+	if len(requests) != len(want) {
+		t.Fatalf("期望 %d 个请求，实际为 %v", len(want), requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("第 %d 个请求期望为 %s，实际为 %s", i+1, want[i], requests[i])
+		}
+	}
+}
+
+func TestGetOnlineUsers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/users/online" {
+			t.Errorf("请求路径不正确: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"usersOnline": [{"id":"usr_1","username":"alice","type":"user","session":{"id":"ses_1","displayTitle":"三体"}}],
+			"openSessions": [{"id":"ses_1","userId":"usr_1","displayTitle":"三体"}]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{AudiobookshelfURL: server.URL})
+	online, err := client.GetOnlineUsers(context.Background())
+	if err != nil {
+		t.Fatalf("获取在线用户失败: %v", err)
+	}
+	if len(online.UsersOnline) != 1 || online.UsersOnline[0].Username != "alice" {
+		t.Errorf("在线用户不正确: %+v", online.UsersOnline)
+	}
+	if online.UsersOnline[0].Session == nil || online.UsersOnline[0].Session.Title() != "三体" {
+		t.Errorf("在线用户的播放会话不正确: %+v", online.UsersOnline[0].Session)
+	}
+	if len(online.OpenSessions) != 1 || online.OpenSessions[0].UserID != "usr_1" {
+		t.Errorf("播放会话不正确: %+v", online.OpenSessions)
+	}
+}
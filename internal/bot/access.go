@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ParseRole 解析角色名称
+func ParseRole(name string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "listener":
+		return RoleListener, nil
+	case "operator":
+		return RoleOperator, nil
+	case "admin":
+		return RoleAdmin, nil
+	}
+	return RoleListener, fmt.Errorf("未知的角色 %q", name)
+}
+
+// AccessControl 根据配置确定 Telegram 用户能否使用机器人以及所具有的角色
+//
+//   - USER_ROLES 中配置的用户使用配置的角色
+//   - 只出现在 ALLOWED_USER_IDS 中的用户视为管理员，与之前的行为保持一致
+//   - 两项都没有配置时，所有用户都可以使用机器人，但只具有听众角色
+//   - 否则未配置的用户无法使用机器人
+type AccessControl struct {
+	roles map[int64]Role
+	// open 没有配置任何用户时为 true
+	open bool
+}
+
+// NewAccessControl 根据角色配置和旧的允许用户列表创建访问控制，无效的角色名称会被忽略
+func NewAccessControl(userRoles map[int64]string, allowedUserIDs []int64) *AccessControl {
+	roles := make(map[int64]Role)
+	for _, id := range allowedUserIDs {
+		roles[id] = RoleAdmin
+	}
+	for id, name := range userRoles {
+		role, err := ParseRole(name)
+		if err != nil {
+			log.Printf("用户 %d 的角色配置无效，已忽略: %v", id, err)
+			continue
+		}
+		roles[id] = role
+	}
+
+	return &AccessControl{
+		roles: roles,
+		open:  len(userRoles) == 0 && len(allowedUserIDs) == 0,
+	}
+}
+
+// Open 是否允许所有用户以听众身份使用机器人
+func (a *AccessControl) Open() bool {
+	return a.open
+}
+
+// Resolve 返回用户的角色，用户无权使用机器人时第二个返回值为 false
+func (a *AccessControl) Resolve(userID int64) (Role, bool) {
+	if role, ok := a.roles[userID]; ok {
+		return role, true
+	}
+	if a.open {
+		return RoleListener, true
+	}
+	return RoleListener, false
+}
@@ -0,0 +1,38 @@
+package bot
+
+import "testing"
+
+func TestAccessControl(t *testing.T) {
+	access := NewAccessControl(map[int64]string{1: "operator", 2: "listener", 3: "superuser"}, []int64{2, 4})
+
+	tests := []struct {
+		userID  int64
+		role    Role
+		allowed bool
+	}{
+		{1, RoleOperator, true},
+		{2, RoleListener, true},  // USER_ROLES 优先于 ALLOWED_USER_IDS
+		{3, RoleListener, false}, // 无效的角色被忽略
+		{4, RoleAdmin, true},     // 只在 ALLOWED_USER_IDS 中的用户视为管理员
+		{5, RoleListener, false},
+	}
+
+	for _, tt := range tests {
+		role, allowed := access.Resolve(tt.userID)
+		if role != tt.role || allowed != tt.allowed {
+			t.Errorf("用户 %d 期望 (%s, %v)，实际为 (%s, %v)", tt.userID, tt.role, tt.allowed, role, allowed)
+		}
+	}
+}
+
+func TestAccessControlOpen(t *testing.T) {
+	access := NewAccessControl(nil, nil)
+	if !access.Open() {
+		t.Fatal("没有配置任何用户时应允许所有用户访问")
+	}
+
+	// 未配置时所有用户都只是听众，而不是管理员
+	if role, allowed := access.Resolve(42); !allowed || role != RoleListener {
+		t.Errorf("期望 (listener, true)，实际为 (%s, %v)", role, allowed)
+	}
+}
@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 回调数据格式: <版本>:<动作>[:<参数>...]
+// 参数过长或包含分隔符时保存在服务端，回调数据中只保留 "~<键>"
+const (
+	callbackVersion   = "1"
+	callbackSeparator = ":"
+	payloadMarker     = "~"
+	// MaxCallbackDataLength Telegram 对回调数据长度的限制（字节）
+	MaxCallbackDataLength = 64
+	// payloadTTL 服务端保存的参数的有效期
+	payloadTTL = 24 * time.Hour
+)
+
+// ErrStaleCallback 回调数据来自旧版本的机器人，或服务端保存的参数已过期
+var ErrStaleCallback = errors.New("stale callback data")
+
+// CallbackData 解码后的回调数据
+type CallbackData struct {
+	Action string
+	Args   []string
+}
+
+// payload 服务端保存的回调参数
+type payload struct {
+	args      []string
+	expiresAt time.Time
+}
+
+// CallbackCodec 编码和解码按钮的回调数据
+type CallbackCodec struct {
+	mu       sync.Mutex
+	payloads map[string]payload
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewCallbackCodec 创建回调数据编解码器，ttl 为服务端保存的参数的有效期
+func NewCallbackCodec(ttl time.Duration) *CallbackCodec {
+	return &CallbackCodec{
+		payloads: make(map[string]payload),
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// defaultCodec 菜单和路由器共用的编解码器
+var defaultCodec = NewCallbackCodec(payloadTTL)
+
+// EncodeCallback 使用默认编解码器编码回调数据
+func EncodeCallback(action string, args ...string) string {
+	return defaultCodec.Encode(action, args...)
+}
+
+// Encode 将动作和参数编码为回调数据，动作名称不能包含分隔符
+func (c *CallbackCodec) Encode(action string, args ...string) string {
+	data := strings.Join(append([]string{callbackVersion, action}, args...), callbackSeparator)
+	if len(data) <= MaxCallbackDataLength && !needsPayload(args) {
+		return data
+	}
+	return callbackVersion + callbackSeparator + action + callbackSeparator + payloadMarker + c.store(args)
+}
+
+// needsPayload 参数中包含分隔符或以标记开头时无法直接放入回调数据
+func needsPayload(args []string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, callbackSeparator) || strings.HasPrefix(arg, payloadMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Decode 解码回调数据，格式或版本不正确、参数已过期时返回 ErrStaleCallback
+func (c *CallbackCodec) Decode(data string) (CallbackData, error) {
+	parts := strings.Split(data, callbackSeparator)
+	if len(parts) < 2 || parts[0] != callbackVersion || parts[1] == "" {
+		return CallbackData{}, ErrStaleCallback
+	}
+
+	decoded := CallbackData{Action: parts[1], Args: parts[2:]}
+	if len(decoded.Args) == 1 && strings.HasPrefix(decoded.Args[0], payloadMarker) {
+		args, ok := c.load(strings.TrimPrefix(decoded.Args[0], payloadMarker))
+		if !ok {
+			return CallbackData{}, ErrStaleCallback
+		}
+		decoded.Args = args
+	}
+	return decoded, nil
+}
+
+// store 在服务端保存参数，返回查找用的键
+func (c *CallbackCodec) store(args []string) string {
+	key := newPayloadKey()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	// 顺便清理过期的参数，避免长期运行时占用内存
+	for k, p := range c.payloads {
+		if !now.Before(p.expiresAt) {
+			delete(c.payloads, k)
+		}
+	}
+	c.payloads[key] = payload{args: append([]string(nil), args...), expiresAt: now.Add(c.ttl)}
+	return key
+}
+
+// load 查找服务端保存的参数
+func (c *CallbackCodec) load(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.payloads[key]
+	if !ok || !c.now().Before(p.expiresAt) {
+		delete(c.payloads, key)
+		return nil, false
+	}
+	return p.args, true
+}
+
+// newPayloadKey 生成随机的短键
+func newPayloadKey() string {
+	buf := make([]byte, 9)
+	if _, err := rand.Read(buf); err != nil {
+		// 随机数不可用时退回使用时间戳，只需在有效期内不重复即可
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// Arg 返回第 i 个参数，不存在时返回空字符串
+func (d CallbackData) Arg(i int) string {
+	if i < 0 || i >= len(d.Args) {
+		return ""
+	}
+	return d.Args[i]
+}
+
+// IntArg 将第 i 个参数解析为整数
+func (d CallbackData) IntArg(i int) (int, bool) {
+	n, err := strconv.Atoi(d.Arg(i))
+	return n, err == nil
+}
@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallbackCodecRoundTrip(t *testing.T) {
+	codec := NewCallbackCodec(time.Hour)
+
+	data := codec.Encode(ActionSearchCategory, SearchCategoryAuthors, "3")
+	if data != "1:search_cat:author:3" {
+		t.Errorf("短参数应直接编码到回调数据中，实际为 %q", data)
+	}
+
+	decoded, err := codec.Decode(data)
+	if err != nil {
+		t.Fatalf("解码失败: %v", err)
+	}
+	if decoded.Action != ActionSearchCategory || decoded.Arg(0) != SearchCategoryAuthors {
+		t.Errorf("解码结果不正确: %+v", decoded)
+	}
+	if index, ok := decoded.IntArg(1); !ok || index != 3 {
+		t.Errorf("期望下标为 3，实际为 %d %v", index, ok)
+	}
+	if decoded.Arg(5) != "" {
+		t.Error("不存在的参数应返回空字符串")
+	}
+}
+
+func TestCallbackCodecLongPayload(t *testing.T) {
+	codec := NewCallbackCodec(time.Hour)
+	now := time.Now()
+	codec.now = func() time.Time { return now }
+
+	long := strings.Repeat("很长的标签名称", 10)
+	for _, args := range [][]string{{long}, {"带:分隔符"}, {"~以标记开头"}} {
+		data := codec.Encode(ActionItemDetail, args...)
+		if len(data) > MaxCallbackDataLength {
+			t.Fatalf("回调数据超过 %d 字节: %q", MaxCallbackDataLength, data)
+		}
+
+		decoded, err := codec.Decode(data)
+		if err != nil {
+			t.Fatalf("解码失败: %v", err)
+		}
+		if decoded.Arg(0) != args[0] {
+			t.Errorf("期望参数为 %q，实际为 %q", args[0], decoded.Arg(0))
+		}
+	}
+
+	// 服务端保存的参数过期后，按钮视为已失效
+	data := codec.Encode(ActionItemDetail, long)
+	now = now.Add(2 * time.Hour)
+	if _, err := codec.Decode(data); !errors.Is(err, ErrStaleCallback) {
+		t.Errorf("期望过期的参数返回 ErrStaleCallback，实际为 %v", err)
+	}
+}
+
+func TestCallbackCodecRejectsStaleData(t *testing.T) {
+	codec := NewCallbackCodec(time.Hour)
+
+	// 旧版本的机器人直接使用动作名称作为回调数据
+	for _, data := range []string{"users_list", "item_detail:li_1", "2:main_menu", "1:", "1:item_detail:~missing"} {
+		if _, err := codec.Decode(data); !errors.Is(err, ErrStaleCallback) {
+			t.Errorf("%q 应被视为失效的按钮，实际为 %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package bot
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/store"
+)
+
+// ConversationState 聊天当前所处的对话状态
+type ConversationState int
+
+const (
+	// StateIdle 没有进行中的多步操作，普通文本消息不会被处理
+	StateIdle ConversationState = iota
+	// StateAwaitingSearchTerm 等待用户输入搜索词
+	StateAwaitingSearchTerm
+	// StateAwaitingUsername 等待用户输入用户名
+	StateAwaitingUsername
+	// StateAwaitingToken 等待用户发送 Audiobookshelf API Token
+	StateAwaitingToken
+	// StateAwaitingLoginUsername 等待用户输入登录 Audiobookshelf 的用户名
+	StateAwaitingLoginUsername
+	// StateAwaitingPassword 等待用户输入登录密码，Data 为已输入的用户名
+	StateAwaitingPassword
+	// StateAwaitingNewUsername 等待管理员输入新用户的用户名
+	StateAwaitingNewUsername
+	// StateAwaitingNewUserPassword 等待管理员输入新用户的密码，Data 为新用户的用户名
+	StateAwaitingNewUserPassword
+	// StateAwaitingResetPassword 等待管理员输入用户的新密码，Data 为用户 ID
+	StateAwaitingResetPassword
+	// StateAwaitingProgressTime 等待用户输入要跳转到的播放位置，Data 为条目 ID
+	StateAwaitingProgressTime
+)
+
+// String 返回状态名称
+func (s ConversationState) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateAwaitingSearchTerm:
+		return "awaiting-search-term"
+	case StateAwaitingUsername:
+		return "awaiting-username"
+	case StateAwaitingToken:
+		return "awaiting-token"
+	case StateAwaitingLoginUsername:
+		return "awaiting-login-username"
+	case StateAwaitingPassword:
+		return "awaiting-password"
+	case StateAwaitingNewUsername:
+		return "awaiting-new-username"
+	case StateAwaitingNewUserPassword:
+		return "awaiting-new-user-password"
+	case StateAwaitingResetPassword:
+		return "awaiting-reset-password"
+	case StateAwaitingProgressTime:
+		return "awaiting-progress-time"
+	}
+	return "unknown"
+}
+
+// Conversation 某个聊天的对话状态
+type Conversation struct {
+	State ConversationState
+	// Data 与当前状态相关的附加数据，例如等待输入密码的用户名
+	Data      string
+	expiresAt time.Time
+}
+
+// ConversationStore 按聊天保存对话状态，超时未完成的对话自动回到空闲状态
+type ConversationStore struct {
+	mu            sync.Mutex
+	conversations map[int64]*Conversation
+	timeout       time.Duration
+	now           func() time.Time
+	// repo 不为空时对话状态的修改会同时写入持久化存储
+	repo *store.ConversationRepository
+}
+
+// NewConversationStore 创建对话状态存储，timeout 为等待用户输入的最长时间
+func NewConversationStore(timeout time.Duration) *ConversationStore {
+	return &ConversationStore{
+		conversations: make(map[int64]*Conversation),
+		timeout:       timeout,
+		now:           time.Now,
+	}
+}
+
+// Persist 从 repo 恢复未超时的对话状态，之后的修改会同时写入 repo，使机器人重启后可以继续进行中的操作
+func (s *ConversationStore) Persist(repo *store.ConversationRepository) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := repo.DeleteExpired(s.now()); err != nil {
+		return err
+	}
+	records, err := repo.All()
+	if err != nil {
+		return err
+	}
+
+	for chatID, record := range records {
+		s.conversations[chatID] = &Conversation{
+			State:     ConversationState(record.State),
+			Data:      record.Data,
+			expiresAt: record.ExpiresAt,
+		}
+	}
+	s.repo = repo
+	return nil
+}
+
+// Set 设置聊天的对话状态，设置为 StateIdle 等同于 Clear
+func (s *ConversationStore) Set(chatID int64, state ConversationState, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state == StateIdle {
+		delete(s.conversations, chatID)
+		s.deletePersisted(chatID)
+		return
+	}
+
+	conversation := &Conversation{
+		State:     state,
+		Data:      data,
+		expiresAt: s.now().Add(s.timeout),
+	}
+	s.conversations[chatID] = conversation
+
+	if s.repo != nil {
+		record := store.ConversationRecord{State: int(state), Data: data, ExpiresAt: conversation.expiresAt}
+		if err := s.repo.Save(chatID, record); err != nil {
+			log.Printf("保存聊天 %d 的对话状态失败: %v", chatID, err)
+		}
+	}
+}
+
+// Get 获取聊天的对话状态，没有进行中的对话或已超时时返回 StateIdle
+// 超时的对话在此时从内存和持久化存储中删除，其余聊天的超时记录在下次启动时清理
+func (s *ConversationStore) Get(chatID int64) Conversation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversation, ok := s.conversations[chatID]
+	if !ok {
+		return Conversation{State: StateIdle}
+	}
+	if !s.now().Before(conversation.expiresAt) {
+		delete(s.conversations, chatID)
+		s.deletePersisted(chatID)
+		return Conversation{State: StateIdle}
+	}
+	return *conversation
+}
+
+// Clear 清除聊天的对话状态，返回清除前是否有进行中的对话
+func (s *ConversationStore) Clear(chatID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversation, ok := s.conversations[chatID]
+	if !ok {
+		return false
+	}
+	delete(s.conversations, chatID)
+	s.deletePersisted(chatID)
+	return s.now().Before(conversation.expiresAt)
+}
+
+// deletePersisted 从持久化存储中删除聊天的对话状态，调用方需持有锁
+func (s *ConversationStore) deletePersisted(chatID int64) {
+	if s.repo == nil {
+		return
+	}
+	if err := s.repo.Delete(chatID); err != nil {
+		log.Printf("删除聊天 %d 的对话状态失败: %v", chatID, err)
+	}
+}
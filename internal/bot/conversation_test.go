@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/store"
+)
+
+func TestConversationStore(t *testing.T) {
+	store := NewConversationStore(time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	if state := store.Get(1).State; state != StateIdle {
+		t.Fatalf("新聊天期望为空闲状态，实际为 %s", state)
+	}
+
+	store.Set(1, StateAwaitingPassword, "alice")
+	conversation := store.Get(1)
+	if conversation.State != StateAwaitingPassword || conversation.Data != "alice" {
+		t.Errorf("对话状态不正确: %+v", conversation)
+	}
+	if state := store.Get(2).State; state != StateIdle {
+		t.Errorf("其他聊天不应受影响，实际为 %s", state)
+	}
+
+	store.Set(1, StateIdle, "")
+	if state := store.Get(1).State; state != StateIdle {
+		t.Errorf("设置为空闲状态后期望清除对话，实际为 %s", state)
+	}
+}
+
+func TestConversationStoreTimeout(t *testing.T) {
+	store := NewConversationStore(time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	store.Set(1, StateAwaitingSearchTerm, "")
+	now = now.Add(30 * time.Second)
+	if state := store.Get(1).State; state != StateAwaitingSearchTerm {
+		t.Fatalf("未超时时期望保持等待状态，实际为 %s", state)
+	}
+
+	now = now.Add(time.Minute)
+	if state := store.Get(1).State; state != StateIdle {
+		t.Errorf("超时后期望回到空闲状态，实际为 %s", state)
+	}
+	if store.Clear(1) {
+		t.Error("超时的对话不应被视为进行中")
+	}
+}
+
+func TestConversationStoreClear(t *testing.T) {
+	store := NewConversationStore(time.Minute)
+
+	store.Set(1, StateAwaitingUsername, "")
+	if !store.Clear(1) {
+		t.Error("期望清除进行中的对话")
+	}
+	if store.Clear(1) {
+		t.Error("没有进行中的对话时应返回 false")
+	}
+}
+
+func TestConversationStorePersist(t *testing.T) {
+	db, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	repo := store.NewConversationRepository(db)
+
+	first := NewConversationStore(time.Minute)
+	if err := first.Persist(repo); err != nil {
+		t.Fatalf("恢复对话状态失败: %v", err)
+	}
+	first.Set(1, StateAwaitingPassword, "alice")
+	first.Set(2, StateAwaitingSearchTerm, "")
+	first.Clear(2)
+
+	// 模拟重启：新的存储从仓库中恢复对话
+	second := NewConversationStore(time.Minute)
+	if err := second.Persist(repo); err != nil {
+		t.Fatalf("恢复对话状态失败: %v", err)
+	}
+	if conversation := second.Get(1); conversation.State != StateAwaitingPassword || conversation.Data != "alice" {
+		t.Errorf("恢复的对话状态不正确: %+v", conversation)
+	}
+	if state := second.Get(2).State; state != StateIdle {
+		t.Errorf("已清除的对话不应被恢复，实际为 %s", state)
+	}
+}
+
+func TestConversationStoreGetDeletesExpiredRecord(t *testing.T) {
+	db, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	repo := store.NewConversationRepository(db)
+
+	conversations := NewConversationStore(time.Minute)
+	now := time.Now()
+	conversations.now = func() time.Time { return now }
+	if err := conversations.Persist(repo); err != nil {
+		t.Fatalf("恢复对话状态失败: %v", err)
+	}
+	conversations.Set(1, StateAwaitingSearchTerm, "")
+	conversations.Set(2, StateAwaitingUsername, "")
+
+	now = now.Add(2 * time.Minute)
+	if state := conversations.Get(1).State; state != StateIdle {
+		t.Errorf("超时的对话应回到空闲状态，实际为 %s", state)
+	}
+
+	records, err := repo.All()
+	if err != nil {
+		t.Fatalf("读取对话状态失败: %v", err)
+	}
+	if _, ok := records[1]; ok {
+		t.Error("读取到超时的对话时应同时从存储中删除")
+	}
+	if _, ok := records[2]; !ok {
+		t.Error("未读取的对话记录应保留到下次启动时清理")
+	}
+}
@@ -1,86 +1,428 @@
 package bot
 
 import (
+	"fmt"
+	"strconv"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
 )
 
-// RegisterCommands 注册 Telegram Bot 命令
-func RegisterCommands(bot *tgbotapi.BotAPI) error {
-	commands := []tgbotapi.BotCommand{
-		{Command: "start", Description: "显示主菜单"},
-		{Command: "serverinfo", Description: "获取服务器信息"},
-		{Command: "users", Description: "获取用户列表"},
-		{Command: "libraries", Description: "获取媒体库列表"},
-		{Command: "search", Description: "搜索图书"},
-		{Command: "mystats", Description: "获取我的统计信息"},
-		{Command: "help", Description: "显示帮助信息"},
+// maxButtonLabelLength 按钮文字的最大长度，过长的书名会被截断
+const maxButtonLabelLength = 32
+
+// CreateServerInfoMenu 创建服务器信息菜单
+func CreateServerInfoMenu() tgbotapi.InlineKeyboardMarkup {
+	buttons := [][]tgbotapi.InlineKeyboardButton{
+		{
+			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", EncodeCallback(ActionMainMenu)),
+		},
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateUsersInfoMenu 创建用户信息菜单，每个用户一个管理按钮和一个查看收听统计的按钮
+func CreateUsersInfoMenu(views []*models.UserView) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	for _, view := range views {
+		user := view.User
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚙️ "+truncateLabel(user.Username), EncodeCallback(ActionUserAdmin, user.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("📈 收听统计", EncodeCallback(ActionUserStats, user.Username)),
+		))
+	}
+
+	buttons = append(buttons,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➕ 创建用户", EncodeCallback(ActionUserCreate)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", EncodeCallback(ActionMainMenu)),
+		),
+	)
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateUserAdminMenu 创建用户管理菜单
+// root 用户只能重置密码，其余设置 Audiobookshelf 不允许修改
+func CreateUserAdminMenu(user *models.UserInfo) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	if user.Type == models.UserTypeRoot {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔑 重置密码", EncodeCallback(ActionUserPassword, user.ID)),
+		))
+	} else {
+		activeButton := tgbotapi.NewInlineKeyboardButtonData("⛔ 停用", EncodeCallback(ActionUserEdit, user.ID, UserOpDeactivate))
+		if !user.IsActive {
+			activeButton = tgbotapi.NewInlineKeyboardButtonData("✅ 启用", EncodeCallback(ActionUserEdit, user.ID, UserOpActivate))
+		}
+		buttons = append(buttons,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🔑 重置密码", EncodeCallback(ActionUserPassword, user.ID)),
+				activeButton,
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("👤 修改类型", EncodeCallback(ActionUserTypes, user.ID)),
+				tgbotapi.NewInlineKeyboardButtonData("🛡 权限", EncodeCallback(ActionUserPermissions, user.ID)),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("📚 媒体库访问", EncodeCallback(ActionUserLibraries, user.ID)),
+				tgbotapi.NewInlineKeyboardButtonData("🏷 标签访问", EncodeCallback(ActionUserTags, user.ID)),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🗑 删除用户", EncodeCallback(ActionUserEdit, user.ID, UserOpDelete)),
+			),
+		)
+	}
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅ 返回用户列表", EncodeCallback(ActionUsers)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateUserTypeMenu 创建修改用户类型的菜单，列出当前类型以外的可选类型
+func CreateUserTypeMenu(user *models.UserInfo) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, userType := range []string{models.UserTypeAdmin, models.UserTypeUser, models.UserTypeGuest} {
+		if userType == user.Type {
+			continue
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(models.RoleBadge(userType), EncodeCallback(ActionUserEdit, user.ID, UserOpType, userType)))
 	}
 
-	config := tgbotapi.NewSetMyCommands(commands...)
-	_, err := bot.Request(config)
-	return err
+	buttons := [][]tgbotapi.InlineKeyboardButton{
+		row,
+		{
+			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回用户管理", EncodeCallback(ActionUserAdmin, user.ID)),
+		},
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateUserPermissionsMenu 创建用户权限菜单，点击按钮切换对应的权限
+func CreateUserPermissionsMenu(user *models.UserInfo) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	for i, name := range models.PermissionNames {
+		label := "❌ " + models.PermissionLabel(name)
+		if user.Permissions.Has(name) {
+			label = "✅ " + models.PermissionLabel(name)
+		}
+		button := tgbotapi.NewInlineKeyboardButtonData(label, EncodeCallback(ActionUserEdit, user.ID, UserOpPermission, name))
+		if i%2 == 0 {
+			buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(button))
+		} else {
+			buttons[len(buttons)-1] = append(buttons[len(buttons)-1], button)
+		}
+	}
+
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅ 返回用户管理", EncodeCallback(ActionUserAdmin, user.ID)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateUserLibrariesMenu 创建用户媒体库访问菜单
+// 用户可以访问全部媒体库时只显示切换按钮，否则列出每个媒体库供选择
+func CreateUserLibrariesMenu(user *models.UserInfo, libraries []models.LibraryInfo) tgbotapi.InlineKeyboardMarkup {
+	accessible := make(map[string]bool, len(user.LibrariesAccessible))
+	for _, id := range user.LibrariesAccessible {
+		accessible[id] = true
+	}
+
+	var options []checkOption
+	for _, library := range libraries {
+		options = append(options, checkOption{label: library.Name, checked: accessible[library.ID], args: []string{UserOpLibrary, library.ID}})
+	}
+	return createAccessMenu(user, models.PermissionAllLibraries, user.Permissions.AccessAllLibraries, options)
+}
+
+// CreateUserTagsMenu 创建用户标签访问菜单，最多列出 MaxUserTagButtons 个标签
+func CreateUserTagsMenu(user *models.UserInfo, tags []string) tgbotapi.InlineKeyboardMarkup {
+	selected := make(map[string]bool, len(user.ItemTagsSelected))
+	for _, tag := range user.ItemTagsSelected {
+		selected[tag] = true
+	}
+
+	var options []checkOption
+	for _, tag := range tags {
+		if len(options) >= MaxUserTagButtons {
+			break
+		}
+		options = append(options, checkOption{label: tag, checked: selected[tag], args: []string{UserOpTag, tag}})
+	}
+	return createAccessMenu(user, models.PermissionAllTags, user.Permissions.AccessAllTags, options)
+}
+
+// checkOption 访问菜单中可以勾选的一项
+type checkOption struct {
+	label   string
+	checked bool
+	// args ActionUserEdit 中用户 ID 之后的参数
+	args []string
 }
 
-// CreateMainMenu 创建主菜单
-func CreateMainMenu() tgbotapi.InlineKeyboardMarkup {
+// createAccessMenu 创建媒体库或标签访问菜单
+// 第一行切换是否允许访问全部，不允许时列出各个选项，每行两个
+func createAccessMenu(user *models.UserInfo, allPermission string, all bool, options []checkOption) tgbotapi.InlineKeyboardMarkup {
+	label := "❌ " + models.PermissionLabel(allPermission)
+	if all {
+		label = "✅ " + models.PermissionLabel(allPermission)
+	}
 	buttons := [][]tgbotapi.InlineKeyboardButton{
 		{
-			tgbotapi.NewInlineKeyboardButtonData("📊 服务器信息", "system_info"),
+			tgbotapi.NewInlineKeyboardButtonData(label, EncodeCallback(ActionUserEdit, user.ID, UserOpPermission, allPermission)),
 		},
+	}
+
+	if !all {
+		for i, option := range options {
+			label := "➕ " + truncateLabel(option.label)
+			if option.checked {
+				label = "✅ " + truncateLabel(option.label)
+			}
+			button := tgbotapi.NewInlineKeyboardButtonData(label, EncodeCallback(ActionUserEdit, append([]string{user.ID}, option.args...)...))
+			if i%2 == 0 {
+				buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(button))
+			} else {
+				buttons[len(buttons)-1] = append(buttons[len(buttons)-1], button)
+			}
+		}
+	}
+
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅ 返回用户管理", EncodeCallback(ActionUserAdmin, user.ID)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateConfirmMenu 创建确认操作的菜单，confirm 和 cancel 为两个按钮的回调数据
+func CreateConfirmMenu(confirm, cancel string) tgbotapi.InlineKeyboardMarkup {
+	buttons := [][]tgbotapi.InlineKeyboardButton{
 		{
-			tgbotapi.NewInlineKeyboardButtonData("👥 用户列表", "users_list"),
-			tgbotapi.NewInlineKeyboardButtonData("📚 媒体库", "libraries_list"),
+			tgbotapi.NewInlineKeyboardButtonData("✅ 确认", confirm),
+			tgbotapi.NewInlineKeyboardButtonData("✖️ 取消", cancel),
 		},
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateUserStatsMenu 创建用户收听统计菜单
+func CreateUserStatsMenu() tgbotapi.InlineKeyboardMarkup {
+	buttons := [][]tgbotapi.InlineKeyboardButton{
 		{
-			tgbotapi.NewInlineKeyboardButtonData("🔍 搜索图书", "search_books"),
-			tgbotapi.NewInlineKeyboardButtonData("📈 我的统计", "my_stats"),
+			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回用户列表", EncodeCallback(ActionUsers)),
 		},
 		{
-			tgbotapi.NewInlineKeyboardButtonData("❓ 帮助", "help"),
+			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", EncodeCallback(ActionMainMenu)),
 		},
 	}
 
 	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
 }
 
-// CreateServerInfoMenu 创建服务器信息菜单
-func CreateServerInfoMenu() tgbotapi.InlineKeyboardMarkup {
+// CreateLibrariesMenu 创建媒体库菜单，点击媒体库查看详情
+// canScan 为 true 时为每个媒体库添加扫描和强制扫描按钮，仅管理员可用
+func CreateLibrariesMenu(libraries []models.LibraryInfo, canScan bool) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	for _, library := range libraries {
+		row := tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📖 "+truncateLabel(library.Name), EncodeCallback(ActionLibraryDetail, library.ID)),
+		)
+		if canScan {
+			row = append(row,
+				tgbotapi.NewInlineKeyboardButtonData("🔄 扫描", EncodeCallback(ActionScanLibrary, library.ID)),
+				tgbotapi.NewInlineKeyboardButtonData("♻️ 强制", EncodeCallback(ActionScanLibrary, library.ID, ScanForce)),
+			)
+		}
+		buttons = append(buttons, row)
+	}
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", EncodeCallback(ActionMainMenu)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateLibraryDetailMenu 创建媒体库详情菜单
+// canScan 为 true 时添加扫描按钮，仅管理员可用
+func CreateLibraryDetailMenu(libraryID string, canScan bool) tgbotapi.InlineKeyboardMarkup {
 	buttons := [][]tgbotapi.InlineKeyboardButton{
 		{
-			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData("📚 按标题浏览", EncodeCallback(ActionLibraryItems, libraryID, LibraryItemsByTitle)),
+			tgbotapi.NewInlineKeyboardButtonData("🆕 最近添加", EncodeCallback(ActionLibraryItems, libraryID, LibraryItemsRecent)),
 		},
 	}
+	if canScan {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 扫描", EncodeCallback(ActionScanLibrary, libraryID)),
+			tgbotapi.NewInlineKeyboardButtonData("♻️ 强制扫描", EncodeCallback(ActionScanLibrary, libraryID, ScanForce)),
+		))
+	}
+	buttons = append(buttons,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回媒体库列表", EncodeCallback(ActionLibraries)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", EncodeCallback(ActionMainMenu)),
+		),
+	)
 
 	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
 }
 
-// CreateUsersInfoMenu 创建用户信息菜单
-func CreateUsersInfoMenu() tgbotapi.InlineKeyboardMarkup {
+// CreateScanMenu 创建扫描进度消息的菜单
+func CreateScanMenu(libraryID string) tgbotapi.InlineKeyboardMarkup {
 	buttons := [][]tgbotapi.InlineKeyboardButton{
 		{
-			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回媒体库详情", EncodeCallback(ActionLibraryDetail, libraryID)),
 		},
+		{
+			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", EncodeCallback(ActionMainMenu)),
+		},
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateNowPlayingMenu 创建正在播放页面的菜单
+// canClose 为 true 时为每个进行中的会话添加关闭按钮，仅管理员可用；autoRefresh 表示页面正在自动刷新
+func CreateNowPlayingMenu(report *models.NowPlayingReport, canClose, autoRefresh bool) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	if canClose {
+		for _, playing := range report.Active {
+			icon := "🛑 关闭 "
+			if playing.Stale {
+				icon = "⚠️ 关闭 "
+			}
+			label := truncateLabel(playing.Username + " · " + playing.Session.Title())
+			buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(icon+label, EncodeCallback(ActionCloseSession, playing.Session.ID)),
+			))
+		}
+	}
+
+	// 自动刷新时点击任意按钮都会停止，刷新按钮同时用作停止按钮
+	refresh := tgbotapi.NewInlineKeyboardButtonData("⏯ 自动刷新", EncodeCallback(ActionNowPlayingAuto))
+	if autoRefresh {
+		refresh = tgbotapi.NewInlineKeyboardButtonData("⏹ 停止自动刷新", EncodeCallback(ActionNowPlaying))
+	}
+	buttons = append(buttons,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 刷新", EncodeCallback(ActionNowPlaying)),
+			refresh,
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", EncodeCallback(ActionMainMenu)),
+		),
+	)
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateItemsInProgressMenu 创建正在收听的图书列表菜单，点击图书管理收听进度
+func CreateItemsInProgressMenu(items []models.ItemProgress) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	for _, p := range items {
+		label := "🎧 " + truncateLabel(p.Item.Title())
+		if duration := p.Duration(); duration > 0 {
+			label += fmt.Sprintf(" (%.0f%%)", p.CurrentTime()/duration*100)
+		}
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, EncodeCallback(ActionProgress, p.Item.ID)),
+		))
 	}
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", EncodeCallback(ActionMainMenu)),
+	))
 
 	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
 }
 
-// CreateLibrariesMenu 创建媒体库菜单
-func CreateLibrariesMenu() tgbotapi.InlineKeyboardMarkup {
+// CreateProgressMenu 创建单本图书的收听进度菜单
+func CreateProgressMenu(p *models.ItemProgress) tgbotapi.InlineKeyboardMarkup {
+	itemID := p.Item.ID
+	var seek []tgbotapi.InlineKeyboardButton
+	if len(p.Chapters()) > 0 {
+		seek = append(seek, tgbotapi.NewInlineKeyboardButtonData("📑 跳到章节", EncodeCallback(ActionProgressChapters, itemID, "0")))
+	}
+	seek = append(seek, tgbotapi.NewInlineKeyboardButtonData("⌨️ 输入位置", EncodeCallback(ActionProgressInput, itemID)))
+
+	status := []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("✅ 标记为已听完", EncodeCallback(ActionProgressEdit, itemID, ProgressOpFinish)),
+	}
+	if p.IsFinished() {
+		status[0] = tgbotapi.NewInlineKeyboardButtonData("↩️ 标记为未听完", EncodeCallback(ActionProgressEdit, itemID, ProgressOpUnfinish))
+	}
+	if p.Progress != nil {
+		status = append(status, tgbotapi.NewInlineKeyboardButtonData("🗑 重置进度", EncodeCallback(ActionProgressEdit, itemID, ProgressOpReset)))
+	}
+
 	buttons := [][]tgbotapi.InlineKeyboardButton{
+		seek,
+		status,
+		{
+			tgbotapi.NewInlineKeyboardButtonData("📖 图书详情", ItemDetailCallback(itemID)),
+			tgbotapi.NewInlineKeyboardButtonData("🎧 正在收听", EncodeCallback(ActionItemsInProgress)),
+		},
 		{
-			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", EncodeCallback(ActionMainMenu)),
 		},
 	}
 
 	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
 }
 
+// CreateProgressChaptersMenu 创建选择章节的菜单，每页 ProgressChaptersPerPage 个章节，当前章节以 ▶️ 标出
+func CreateProgressChaptersMenu(p *models.ItemProgress, page int) tgbotapi.InlineKeyboardMarkup {
+	itemID := p.Item.ID
+	chapters := p.Chapters()
+	current := p.CurrentChapter()
+	pageCount := (len(chapters) + ProgressChaptersPerPage - 1) / ProgressChaptersPerPage
+
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	start := page * ProgressChaptersPerPage
+	for i := start; i < len(chapters) && i < start+ProgressChaptersPerPage; i++ {
+		label := fmt.Sprintf("%d. %s", i+1, truncateLabel(chapters[i].Title))
+		if i == current {
+			label = "▶️ " + label
+		}
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, EncodeCallback(ActionProgressEdit, itemID, ProgressOpChapter, strconv.Itoa(i))),
+		))
+	}
+
+	if pageCount > 1 {
+		var nav []tgbotapi.InlineKeyboardButton
+		if page > 0 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀ 上一页", EncodeCallback(ActionProgressChapters, itemID, strconv.Itoa(page-1))))
+		}
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d / %d", page+1, pageCount), EncodeCallback(ActionNoop)))
+		if page < pageCount-1 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("下一页 ▶", EncodeCallback(ActionProgressChapters, itemID, strconv.Itoa(page+1))))
+		}
+		buttons = append(buttons, nav)
+	}
+
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅ 返回收听进度", EncodeCallback(ActionProgress, itemID)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
 // CreateSearchMenu 创建搜索菜单
 func CreateSearchMenu() tgbotapi.InlineKeyboardMarkup {
 	buttons := [][]tgbotapi.InlineKeyboardButton{
 		{
-			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", EncodeCallback(ActionMainMenu)),
 		},
 	}
 
@@ -91,9 +433,322 @@ func CreateSearchMenu() tgbotapi.InlineKeyboardMarkup {
 func CreateMyStatsMenu() tgbotapi.InlineKeyboardMarkup {
 	buttons := [][]tgbotapi.InlineKeyboardButton{
 		{
-			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", EncodeCallback(ActionMainMenu)),
+		},
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateLinkMenu 创建账户绑定菜单，已绑定时提供解除绑定的按钮
+func CreateLinkMenu(linked bool) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	if linked {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔓 解除绑定", EncodeCallback(ActionUnlink)),
+		))
+	}
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", EncodeCallback(ActionMainMenu)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateSubscriptionsMenu 创建新书通知订阅菜单，每个媒体库一个切换订阅状态的按钮
+func CreateSubscriptionsMenu(libraries []models.LibraryInfo, subscribed map[string]bool) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	for _, library := range libraries {
+		label := "➕ " + truncateLabel(library.Name)
+		if subscribed[library.ID] {
+			label = "✅ " + truncateLabel(library.Name)
+		}
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, EncodeCallback(ActionToggleSubscription, library.ID)),
+		))
+	}
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", EncodeCallback(ActionMainMenu)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateSearchOverviewMenu 创建按类别分组的搜索结果菜单
+// 第一行打开搜索到的图书和播客，其后每个作者、系列、标签和朗读者一个按钮
+// 按钮带有 searchID，旧消息上的按钮不会打开之后的搜索结果
+func CreateSearchOverviewMenu(searchID string, results *models.SearchResults) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	if count := len(results.Books) + len(results.Podcasts); count > 0 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("📚 图书和播客 (%d)", count), SearchCategoryCallback(searchID, SearchCategoryItems, 0)),
+		))
+	}
+
+	var labels []string
+	for _, author := range results.Authors {
+		labels = append(labels, "✍️ "+author.Name)
+	}
+	buttons = append(buttons, categoryButtons(searchID, SearchCategoryAuthors, labels)...)
+
+	labels = labels[:0]
+	for _, series := range results.Series {
+		labels = append(labels, "📖 "+series.Series.Name)
+	}
+	buttons = append(buttons, categoryButtons(searchID, SearchCategorySeries, labels)...)
+
+	labels = labels[:0]
+	for _, tag := range results.Tags {
+		labels = append(labels, "🏷 "+tag.Name)
+	}
+	buttons = append(buttons, categoryButtons(searchID, SearchCategoryTags, labels)...)
+
+	labels = labels[:0]
+	for _, narrator := range results.Narrators {
+		labels = append(labels, "🎙 "+narrator.Name)
+	}
+	buttons = append(buttons, categoryButtons(searchID, SearchCategoryNarrators, labels)...)
+
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", EncodeCallback(ActionMainMenu)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// categoryButtons 为同一类别的搜索结果生成按钮，每行两个，最多 MaxCategoryButtons 个
+func categoryButtons(searchID, category string, labels []string) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, label := range labels {
+		if i >= MaxCategoryButtons {
+			break
+		}
+		button := tgbotapi.NewInlineKeyboardButtonData(truncateLabel(label), SearchCategoryCallback(searchID, category, i))
+		if i%2 == 0 {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+		} else {
+			rows[len(rows)-1] = append(rows[len(rows)-1], button)
+		}
+	}
+	return rows
+}
+
+// CreateSearchResultsMenu 创建搜索结果某一页的菜单，每本书一个打开详情的按钮
+// offset 为本页第一本书在全部结果中的序号，page 从 0 开始，共 pageCount 页
+// withOverview 为 true 时显示返回分类概览的按钮，所有按钮都带有 searchID
+func CreateSearchResultsMenu(searchID string, items []models.LibraryItem, offset, page, pageCount int, withOverview bool) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	for i, item := range items {
+		title := item.Title()
+		if title == "" {
+			title = item.RelPath
+		}
+		label := fmt.Sprintf("📖 %d. %s", offset+i+1, truncateLabel(title))
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, SearchItemCallback(searchID, item.ID)),
+		))
+	}
+
+	// 只有一页时不显示翻页按钮
+	if pageCount > 1 {
+		var nav []tgbotapi.InlineKeyboardButton
+		if page > 0 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀ 上一页", SearchPageCallback(searchID, page-1)))
+		}
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d / %d", page+1, pageCount), EncodeCallback(ActionNoop)))
+		if page < pageCount-1 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("下一页 ▶", SearchPageCallback(searchID, page+1)))
+		}
+		buttons = append(buttons, nav)
+	}
+
+	if withOverview {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回搜索概览", EncodeCallback(ActionSearchOverview, searchID)),
+		))
+	}
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅ 返回主菜单", EncodeCallback(ActionMainMenu)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateItemDetailMenu 创建条目详情菜单
+// searchID 不为空时添加返回该次搜索结果的按钮
+// canScan 为 true 时添加重新扫描按钮，仅管理员可用
+func CreateItemDetailMenu(itemID, searchID string, canScan bool) tgbotapi.InlineKeyboardMarkup {
+	buttons := [][]tgbotapi.InlineKeyboardButton{
+		{
+			tgbotapi.NewInlineKeyboardButtonData("🎧 收听进度", EncodeCallback(ActionProgress, itemID)),
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载", EncodeCallback(ActionDownload, itemID)),
+		},
+	}
+	if canScan {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 重新扫描", EncodeCallback(ActionScanItem, itemID)),
+		))
+	}
+	if searchID != "" {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅ 返回搜索结果", EncodeCallback(ActionSearchResults, searchID)),
+		))
+	}
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", EncodeCallback(ActionMainMenu)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
+
+// CreateItemResultMenu 创建条目操作结果菜单，包含查看条目详情和返回主菜单的按钮
+func CreateItemResultMenu(itemID string) tgbotapi.InlineKeyboardMarkup {
+	buttons := [][]tgbotapi.InlineKeyboardButton{
+		{
+			tgbotapi.NewInlineKeyboardButtonData("📖 查看详情", ItemDetailCallback(itemID)),
+		},
+		{
+			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", EncodeCallback(ActionMainMenu)),
 		},
 	}
 
 	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
-}
\ No newline at end of file
+}
+
+// 按钮回调的动作名称，参数通过 EncodeCallback 附加在动作之后
+const (
+	ActionMainMenu       = "main_menu"
+	ActionServerInfo     = "system_info"
+	ActionUsers          = "users_list"
+	ActionLibraries      = "libraries_list"
+	ActionSearch         = "search_books"
+	ActionMyStats        = "my_stats"
+	ActionUserStats      = "user_stats"
+	ActionHelp           = "help"
+	ActionNoop           = "noop"
+	ActionItemDetail     = "item_detail"
+	ActionSearchOverview = "search_overview"
+	ActionSearchResults  = "search_results"
+	ActionSearchPage     = "search_page"
+	ActionSearchCategory = "search_cat"
+	ActionLink           = "link"
+	ActionUnlink         = "unlink"
+
+	ActionSubscriptions      = "subscriptions"
+	ActionToggleSubscription = "sub_toggle"
+
+	ActionScanLibrary = "scan_lib"
+	ActionScanItem    = "scan_item"
+
+	ActionLibraryDetail = "lib_detail"
+	ActionLibraryItems  = "lib_items"
+
+	ActionItemsInProgress  = "in_progress"
+	ActionProgress         = "progress"
+	ActionProgressChapters = "prog_chapters"
+	ActionProgressInput    = "prog_input"
+	// ActionProgressEdit 修改收听进度，参数为条目 ID、操作（ProgressOp*）和操作的参数
+	// 重置进度会先要求确认，确认后以 ActionProgressConfirm 执行
+	ActionProgressEdit    = "prog_edit"
+	ActionProgressConfirm = "prog_ok"
+
+	// ActionDownload 下载图书的音频文件，参数为条目 ID，确认后以 ActionDownloadConfirm 发送
+	ActionDownload        = "download"
+	ActionDownloadConfirm = "download_ok"
+
+	ActionNowPlaying     = "now_playing"
+	ActionNowPlayingAuto = "np_auto"
+	// ActionCloseSession 关闭播放会话，参数为会话 ID，确认后以 ActionCloseSessionConfirm 执行
+	ActionCloseSession        = "np_close"
+	ActionCloseSessionConfirm = "np_close_ok"
+
+	ActionUserAdmin       = "user_admin"
+	ActionUserCreate      = "user_create"
+	ActionUserPassword    = "user_pwd"
+	ActionUserTypes       = "user_types"
+	ActionUserPermissions = "user_perms"
+	ActionUserLibraries   = "user_libs"
+	ActionUserTags        = "user_tags"
+	// ActionUserEdit 修改用户，参数为用户 ID、操作（UserOp*）和操作的参数
+	// 停用、删除和修改类型会先要求确认，确认后以 ActionUserConfirm 执行
+	ActionUserEdit    = "user_edit"
+	ActionUserConfirm = "user_ok"
+)
+
+// 修改用户的操作，作为 ActionUserEdit 和 ActionUserConfirm 的第二个参数
+const (
+	UserOpActivate   = "activate"
+	UserOpDeactivate = "deactivate"
+	UserOpDelete     = "delete"
+	UserOpType       = "type"
+	UserOpPermission = "perm"
+	UserOpLibrary    = "lib"
+	UserOpTag        = "tag"
+)
+
+// 修改收听进度的操作，作为 ActionProgressEdit 和 ActionProgressConfirm 的第二个参数
+const (
+	// ProgressOpChapter 跳到章节的开头，第三个参数为章节下标
+	ProgressOpChapter  = "chapter"
+	ProgressOpFinish   = "finish"
+	ProgressOpUnfinish = "unfinish"
+	ProgressOpReset    = "reset"
+)
+
+// ProgressChaptersPerPage 选择章节的菜单中每页的章节数量
+const ProgressChaptersPerPage = 10
+
+// MaxUserTagButtons 标签访问菜单中最多列出的标签数量
+const MaxUserTagButtons = 30
+
+// ActionLibraryItems 的第二个参数，表示条目列表的排序方式
+const (
+	LibraryItemsByTitle = "title"
+	LibraryItemsRecent  = "recent"
+)
+
+// ScanForce ActionScanLibrary 的第二个参数，表示强制重新扫描所有文件
+const ScanForce = "force"
+
+// ItemDetailCallback 返回打开指定条目详情的回调数据
+func ItemDetailCallback(itemID string) string {
+	return EncodeCallback(ActionItemDetail, itemID)
+}
+
+// SearchItemCallback 返回从搜索结果中打开条目详情的回调数据
+// 第二个参数为搜索 ID，详情页据此显示返回搜索结果的按钮
+func SearchItemCallback(searchID, itemID string) string {
+	return EncodeCallback(ActionItemDetail, itemID, searchID)
+}
+
+// SearchPageCallback 返回跳转到 searchID 搜索结果指定页的回调数据
+func SearchPageCallback(searchID string, page int) string {
+	return EncodeCallback(ActionSearchPage, searchID, strconv.Itoa(page))
+}
+
+// 搜索结果的类别
+const (
+	SearchCategoryItems     = "items"
+	SearchCategoryAuthors   = "author"
+	SearchCategorySeries    = "series"
+	SearchCategoryTags      = "tag"
+	SearchCategoryNarrators = "narrator"
+)
+
+// MaxCategoryButtons 搜索概览中每个类别最多显示的按钮数量
+const MaxCategoryButtons = 6
+
+// SearchCategoryCallback 返回打开搜索结果中第 index 个 category 类别条目的回调数据
+// 回调数据只包含搜索 ID、类别和下标，具体内容从聊天的搜索结果缓存中取得
+func SearchCategoryCallback(searchID, category string, index int) string {
+	return EncodeCallback(ActionSearchCategory, searchID, category, strconv.Itoa(index))
+}
+
+// truncateLabel 截断过长的按钮文字
+func truncateLabel(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxButtonLabelLength {
+		return text
+	}
+	return string(runes[:maxButtonLabelLength-1]) + "…"
+}
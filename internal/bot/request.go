@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Request 一次待处理的用户请求，由命令、按钮点击或普通文本消息触发
+type Request struct {
+	ctx    context.Context
+	Bot    *tgbotapi.BotAPI
+	ChatID int64
+	// MessageID 可以编辑的消息 ID
+	// 按钮触发时为按钮所在的消息，命令触发时为 0，直到第一次 Reply 发送了新消息
+	MessageID int
+	UserID    int64
+	Role      Role
+	// Args 命令参数或文本消息内容
+	Args string
+	// Data 按钮触发时解码后的回调数据
+	Data CallbackData
+	// Message 触发请求的消息，按钮触发时为 nil
+	Message *tgbotapi.Message
+	// Callback 触发请求的按钮回调，命令触发时为 nil
+	Callback *tgbotapi.CallbackQuery
+
+	// editable MessageID 指向的消息是否可以编辑为文本
+	editable bool
+	router   *Router
+}
+
+// Context 返回请求的上下文，机器人关闭时会被取消
+func (r *Request) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// Reply 回复纯文本：有可编辑的消息时编辑该消息，否则发送新消息
+// 发送新消息后，之后的 Reply 会编辑这条新消息，因此可以先回复加载提示再回复结果
+func (r *Request) Reply(text string, markup *tgbotapi.InlineKeyboardMarkup) {
+	r.reply(text, "", markup)
+}
+
+// ReplyMarkdown 与 Reply 相同，但按 Markdown 格式解析文本
+func (r *Request) ReplyMarkdown(text string, markup *tgbotapi.InlineKeyboardMarkup) {
+	r.reply(text, tgbotapi.ModeMarkdown, markup)
+}
+
+func (r *Request) reply(text, parseMode string, markup *tgbotapi.InlineKeyboardMarkup) {
+	if r.MessageID > 0 && r.editable {
+		edit := tgbotapi.NewEditMessageText(r.ChatID, r.MessageID, text)
+		edit.ParseMode = parseMode
+		edit.ReplyMarkup = markup
+		r.Bot.Send(edit)
+		return
+	}
+
+	// 无法编辑的消息（如图片消息）删除后发送新消息代替
+	r.DeleteMessage()
+
+	msg := tgbotapi.NewMessage(r.ChatID, text)
+	msg.ParseMode = parseMode
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	sent, err := r.Bot.Send(msg)
+	if err == nil {
+		r.MessageID = sent.MessageID
+		r.editable = true
+	}
+}
+
+// DeleteMessage 删除 MessageID 指向的消息，之后的 Reply 会发送新消息
+func (r *Request) DeleteMessage() {
+	if r.MessageID > 0 {
+		r.Bot.Request(tgbotapi.NewDeleteMessage(r.ChatID, r.MessageID))
+	}
+	r.MessageID = 0
+	r.editable = false
+}
+
+// DeleteIncomingMessage 删除触发请求的用户消息，用于清除聊天中的密码和 Token
+func (r *Request) DeleteIncomingMessage() {
+	if r.Message != nil {
+		r.Bot.Request(tgbotapi.NewDeleteMessage(r.ChatID, r.Message.MessageID))
+	}
+}
+
+// MainMenu 返回当前用户可用的主菜单
+func (r *Request) MainMenu() *tgbotapi.InlineKeyboardMarkup {
+	menu := r.router.MainMenu(r.Role)
+	return &menu
+}
+
+// HelpText 返回当前用户可用的帮助信息
+func (r *Request) HelpText() string {
+	return r.router.HelpText(r.Role)
+}
@@ -0,0 +1,282 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Role 用户在机器人中的角色，角色越高可用的功能越多
+type Role int
+
+const (
+	// RoleListener 普通听众，只能查看和搜索
+	RoleListener Role = iota
+	// RoleOperator 运维人员，可以查看服务器状态和执行维护操作
+	RoleOperator
+	// RoleAdmin 管理员，可以使用全部功能
+	RoleAdmin
+)
+
+// String 返回角色名称
+func (r Role) String() string {
+	switch r {
+	case RoleListener:
+		return "listener"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	}
+	return "unknown"
+}
+
+// RoleResolver 根据 Telegram 用户 ID 确定用户的角色，用户无权使用机器人时第二个返回值为 false
+type RoleResolver func(userID int64) (Role, bool)
+
+// accessDeniedText 无权使用机器人的用户收到的提示
+const accessDeniedText = "🚫 抱歉，您没有权限使用此机器人。"
+
+// HandlerFunc 处理一次用户请求
+type HandlerFunc func(req *Request)
+
+// Command 机器人的一个功能，可以通过命令、主菜单按钮或两者触发
+type Command struct {
+	// Name 命令名称，不带斜杠；为空时只能通过按钮触发
+	Name string
+	// Description 命令说明，用于 Telegram 命令菜单和帮助信息
+	Description string
+	// Usage 帮助信息中显示的参数说明，例如 "<用户名>"
+	Usage string
+	// Role 使用该功能所需的最低角色
+	Role Role
+	// MenuLabel 主菜单按钮文字，为空时不在主菜单中显示
+	MenuLabel string
+	// MenuRow 按钮在主菜单中的行号，同一行的按钮按注册顺序排列
+	MenuRow int
+	// Action 按钮的回调动作，设置了 MenuLabel 时必须设置
+	Action  string
+	Handler HandlerFunc
+}
+
+// callbackRoute 按回调动作注册的处理函数
+type callbackRoute struct {
+	role    Role
+	handler HandlerFunc
+}
+
+// Router 将命令、按钮回调和普通文本消息分发到注册的处理函数
+// RegisterCommands、帮助信息和主菜单都根据注册的命令生成
+type Router struct {
+	commands  []*Command
+	byName    map[string]*Command
+	callbacks map[string]callbackRoute
+	text      HandlerFunc
+	resolve   RoleResolver
+	codec     *CallbackCodec
+}
+
+// NewRouter 创建路由器，resolve 用于确定发起请求的用户的角色
+func NewRouter(resolve RoleResolver) *Router {
+	return &Router{
+		byName:    make(map[string]*Command),
+		callbacks: make(map[string]callbackRoute),
+		resolve:   resolve,
+		codec:     defaultCodec,
+	}
+}
+
+// Handle 注册一个命令
+func (rt *Router) Handle(cmd Command) {
+	c := &cmd
+	rt.commands = append(rt.commands, c)
+	if c.Name != "" {
+		rt.byName[strings.ToLower(c.Name)] = c
+	}
+	if c.Action != "" {
+		rt.callbacks[c.Action] = callbackRoute{role: c.Role, handler: c.Handler}
+	}
+}
+
+// HandleCallback 注册回调动作为 action 的按钮的处理函数
+// 处理函数通过 Request.Data 取得按钮携带的参数
+func (rt *Router) HandleCallback(action string, role Role, handler HandlerFunc) {
+	rt.callbacks[action] = callbackRoute{role: role, handler: handler}
+}
+
+// HandleText 注册非命令文本消息的处理函数
+func (rt *Router) HandleText(handler HandlerFunc) {
+	rt.text = handler
+}
+
+// HandleMessage 分发一条消息
+func (rt *Router) HandleMessage(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	req, allowed := rt.newRequest(ctx, bot, message.Chat.ID, message.From)
+	req.Message = message
+	if !allowed {
+		log.Printf("拒绝用户 %s (ID: %d) 的访问", message.From.UserName, req.UserID)
+		req.Reply(accessDeniedText, nil)
+		return
+	}
+
+	if !message.IsCommand() {
+		if rt.text != nil {
+			req.Args = strings.TrimSpace(message.Text)
+			rt.text(req)
+		}
+		return
+	}
+
+	cmd, ok := rt.byName[strings.ToLower(message.Command())]
+	if !ok {
+		req.Reply("未知命令，发送 /help 查看可用命令", nil)
+		return
+	}
+	if req.Role < cmd.Role {
+		log.Printf("用户 %d (%s) 无权使用命令 /%s", req.UserID, req.Role, cmd.Name)
+		req.Reply("⛔ 您没有权限使用此功能", nil)
+		return
+	}
+
+	req.Args = strings.TrimSpace(message.CommandArguments())
+	cmd.Handler(req)
+}
+
+// HandleCallbackQuery 分发一次按钮点击
+func (rt *Router) HandleCallbackQuery(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+	if callback.Message == nil {
+		// 内联模式的按钮或过旧的消息不附带原消息，无法确定所在的聊天
+		log.Printf("回调 %q 缺少原消息，已忽略", callback.Data)
+		bot.Send(tgbotapi.NewCallbackWithAlert(callback.ID, "⌛ 此按钮已失效，请发送 /start 打开新的菜单"))
+		return
+	}
+
+	req, allowed := rt.newRequest(ctx, bot, callback.Message.Chat.ID, callback.From)
+	if !allowed {
+		log.Printf("拒绝用户 %s (ID: %d) 的访问", callback.From.UserName, req.UserID)
+		bot.Send(tgbotapi.NewCallbackWithAlert(callback.ID, accessDeniedText))
+		return
+	}
+	req.Callback = callback
+	req.MessageID = callback.Message.MessageID
+	// 图片消息（如图书详情）无法编辑为文本
+	req.editable = callback.Message.Photo == nil
+
+	route, data, err := rt.findCallback(callback.Data)
+	if err != nil {
+		// 旧版本机器人发送的按钮或已过期的按钮，提示用户并换成新的主菜单
+		log.Printf("无法处理回调数据 %q: %v", callback.Data, err)
+		bot.Send(tgbotapi.NewCallbackWithAlert(callback.ID, "⌛ 此按钮已失效，请使用新的菜单"))
+		req.Reply("🎧 菜单已更新，请重新选择您要执行的操作:", req.MainMenu())
+		return
+	}
+
+	if req.Role < route.role {
+		log.Printf("用户 %d (%s) 无权使用回调 %q", req.UserID, req.Role, callback.Data)
+		bot.Send(tgbotapi.NewCallbackWithAlert(callback.ID, "⛔ 您没有权限使用此功能"))
+		return
+	}
+
+	// 响应回调查询，避免按钮loading状态持续太久
+	bot.Send(tgbotapi.NewCallback(callback.ID, ""))
+
+	req.Data = data
+	route.handler(req)
+}
+
+// findCallback 解码回调数据并查找对应的处理函数
+// 无法解码或动作未注册时返回 ErrStaleCallback
+func (rt *Router) findCallback(raw string) (callbackRoute, CallbackData, error) {
+	data, err := rt.codec.Decode(raw)
+	if err != nil {
+		return callbackRoute{}, CallbackData{}, err
+	}
+	route, ok := rt.callbacks[data.Action]
+	if !ok {
+		return callbackRoute{}, CallbackData{}, ErrStaleCallback
+	}
+	return route, data, nil
+}
+
+// newRequest 创建请求并确定用户角色，用户无权使用机器人时第二个返回值为 false
+func (rt *Router) newRequest(ctx context.Context, bot *tgbotapi.BotAPI, chatID int64, from *tgbotapi.User) (*Request, bool) {
+	req := &Request{
+		ctx:    ctx,
+		Bot:    bot,
+		ChatID: chatID,
+		router: rt,
+	}
+	if from != nil {
+		req.UserID = from.ID
+	}
+	if rt.resolve == nil {
+		return req, true
+	}
+	role, allowed := rt.resolve(req.UserID)
+	req.Role = role
+	return req, allowed
+}
+
+// BotCommands 返回注册到 Telegram 命令菜单中的命令
+func (rt *Router) BotCommands() []tgbotapi.BotCommand {
+	var commands []tgbotapi.BotCommand
+	for _, cmd := range rt.commands {
+		if cmd.Name == "" {
+			continue
+		}
+		commands = append(commands, tgbotapi.BotCommand{Command: cmd.Name, Description: cmd.Description})
+	}
+	return commands
+}
+
+// RegisterCommands 注册 Telegram Bot 命令
+func (rt *Router) RegisterCommands(bot *tgbotapi.BotAPI) error {
+	config := tgbotapi.NewSetMyCommands(rt.BotCommands()...)
+	_, err := bot.Request(config)
+	return err
+}
+
+// HelpText 生成帮助信息，只列出 role 可以使用的命令
+func (rt *Router) HelpText(role Role) string {
+	var sb strings.Builder
+	sb.WriteString("🎧 *Audiobookshelf 管理机器人帮助*\n\n可用命令:\n")
+	for _, cmd := range rt.commands {
+		if cmd.Name == "" || role < cmd.Role {
+			continue
+		}
+		usage := ""
+		if cmd.Usage != "" {
+			usage = " " + cmd.Usage
+		}
+		sb.WriteString(fmt.Sprintf("• /%s%s - %s\n", cmd.Name, usage, cmd.Description))
+	}
+	sb.WriteString("\n或者使用下方的菜单按钮进行操作。\n")
+	return sb.String()
+}
+
+// MainMenu 生成主菜单，只包含 role 可以使用的功能
+func (rt *Router) MainMenu(role Role) tgbotapi.InlineKeyboardMarkup {
+	rows := make(map[int][]tgbotapi.InlineKeyboardButton)
+	for _, cmd := range rt.commands {
+		if cmd.MenuLabel == "" || role < cmd.Role {
+			continue
+		}
+		rows[cmd.MenuRow] = append(rows[cmd.MenuRow], tgbotapi.NewInlineKeyboardButtonData(cmd.MenuLabel, rt.codec.Encode(cmd.Action)))
+	}
+
+	indexes := make([]int, 0, len(rows))
+	for index := range rows {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	buttons := make([][]tgbotapi.InlineKeyboardButton, 0, len(indexes))
+	for _, index := range indexes {
+		buttons = append(buttons, rows[index])
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(buttons...)
+}
@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func newTestRouter() *Router {
+	router := NewRouter(nil)
+	noop := func(req *Request) {}
+	router.Handle(Command{Name: "start", Description: "显示主菜单", Action: ActionMainMenu, Handler: noop})
+	router.Handle(Command{Name: "serverinfo", Description: "获取服务器信息", Role: RoleOperator, MenuLabel: "服务器", MenuRow: 0, Action: ActionServerInfo, Handler: noop})
+	router.Handle(Command{Name: "search", Description: "搜索图书", Usage: "[关键词]", MenuLabel: "搜索", MenuRow: 1, Action: ActionSearch, Handler: noop})
+	router.Handle(Command{Name: "users", Description: "获取用户列表", Role: RoleAdmin, MenuLabel: "用户", MenuRow: 1, Action: ActionUsers, Handler: noop})
+	router.HandleCallback(ActionItemDetail, RoleListener, noop)
+	return router
+}
+
+func TestRouterMainMenu(t *testing.T) {
+	router := newTestRouter()
+
+	admin := router.MainMenu(RoleAdmin).InlineKeyboard
+	if len(admin) != 2 || len(admin[0]) != 1 || len(admin[1]) != 2 {
+		t.Fatalf("管理员菜单布局不正确: %+v", admin)
+	}
+	if admin[1][0].Text != "搜索" || admin[1][1].Text != "用户" {
+		t.Errorf("同一行的按钮应按注册顺序排列: %+v", admin[1])
+	}
+
+	listener := router.MainMenu(RoleListener).InlineKeyboard
+	if len(listener) != 1 || len(listener[0]) != 1 || listener[0][0].Text != "搜索" {
+		t.Errorf("听众菜单只应包含可用的功能: %+v", listener)
+	}
+}
+
+func TestRouterHelpText(t *testing.T) {
+	router := newTestRouter()
+
+	help := router.HelpText(RoleOperator)
+	for _, want := range []string{"/start - 显示主菜单", "/serverinfo - 获取服务器信息", "/search [关键词] - 搜索图书"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("帮助信息缺少 %q:\n%s", want, help)
+		}
+	}
+	if strings.Contains(help, "/users") {
+		t.Errorf("帮助信息不应包含无权使用的命令:\n%s", help)
+	}
+
+	if commands := router.BotCommands(); len(commands) != 4 {
+		t.Errorf("期望注册 4 个命令，实际 %d 个", len(commands))
+	}
+}
+
+func TestRouterFindCallback(t *testing.T) {
+	router := newTestRouter()
+
+	route, _, err := router.findCallback(EncodeCallback(ActionUsers))
+	if err != nil || route.role != RoleAdmin {
+		t.Errorf("菜单按钮应继承命令的角色: %+v %v", route, err)
+	}
+
+	if _, data, err := router.findCallback(ItemDetailCallback("li_1")); err != nil || data.Arg(0) != "li_1" {
+		t.Errorf("按钮应携带条目 ID，实际为 %+v %v", data, err)
+	}
+
+	// 主菜单中的按钮都能找到对应的处理函数
+	for _, row := range router.MainMenu(RoleAdmin).InlineKeyboard {
+		for _, button := range row {
+			if _, _, err := router.findCallback(*button.CallbackData); err != nil {
+				t.Errorf("按钮 %q 的回调数据 %q 无法处理: %v", button.Text, *button.CallbackData, err)
+			}
+		}
+	}
+
+	for _, data := range []string{"users_list", EncodeCallback("unknown")} {
+		if _, _, err := router.findCallback(data); !errors.Is(err, ErrStaleCallback) {
+			t.Errorf("%q 应被视为失效的按钮，实际为 %v", data, err)
+		}
+	}
+}
+
+func TestRouterCallbackWithoutMessage(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		mu.Unlock()
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer server.Close()
+
+	bot := &tgbotapi.BotAPI{Token: "test", Client: server.Client()}
+	bot.SetAPIEndpoint(server.URL + "/bot%s/%s")
+
+	handled := false
+	router := NewRouter(nil)
+	router.HandleCallback(ActionItemDetail, RoleListener, func(req *Request) { handled = true })
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:              "1",
+		From:            &tgbotapi.User{ID: 42},
+		InlineMessageID: "inline",
+		Data:            router.codec.Encode(ActionItemDetail, "item"),
+	}
+	router.HandleCallbackQuery(context.Background(), bot, callback)
+
+	if handled {
+		t.Error("缺少原消息的回调不应交给处理函数")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(methods) != 1 || methods[0] != "answerCallbackQuery" {
+		t.Errorf("缺少原消息的回调应只被应答一次，实际请求: %v", methods)
+	}
+}
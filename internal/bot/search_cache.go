@@ -0,0 +1,169 @@
+package bot
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// SearchResult 某个聊天的一次搜索结果
+type SearchResult struct {
+	// ID 区分同一聊天的不同搜索，附加在结果消息的按钮上，用于识别旧消息上的按钮
+	ID   string
+	Term string
+	// Results 按类别分组的完整搜索结果
+	Results *models.SearchResults
+	// ListTitle 当前浏览的条目列表的标题，为空时表示浏览的是搜索到的图书和播客
+	ListTitle string
+	// Items 当前浏览的条目列表，点开作者、系列等分类后为该分类下的条目
+	Items []models.LibraryItem
+	// CurrentPage 用户最后浏览的页码，从详情页返回时回到该页
+	CurrentPage int
+	createdAt   time.Time
+}
+
+// PageCount 返回按 pageSize 分页后的总页数，至少为 1
+func (r *SearchResult) PageCount(pageSize int) int {
+	if len(r.Items) == 0 || pageSize <= 0 {
+		return 1
+	}
+	return (len(r.Items) + pageSize - 1) / pageSize
+}
+
+// Page 返回第 page 页（从 0 开始）的条目及其在全部结果中的起始序号
+// 页码超出范围时会被修正到有效范围内
+func (r *SearchResult) Page(page, pageSize int) (items []models.LibraryItem, offset int, current int) {
+	current = ClampPage(page, r.PageCount(pageSize))
+	offset = current * pageSize
+	end := offset + pageSize
+	if end > len(r.Items) {
+		end = len(r.Items)
+	}
+	if offset > end {
+		offset = end
+	}
+	return r.Items[offset:end], offset, current
+}
+
+// ClampPage 将页码修正到 [0, pageCount) 范围内
+func ClampPage(page, pageCount int) int {
+	if page >= pageCount {
+		page = pageCount - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	return page
+}
+
+// SearchCache 按聊天保存最近一次的搜索结果，超过有效期后自动失效
+// 每次保存都会分配新的 ID，取结果时 ID 不一致说明按钮来自之前的搜索
+type SearchCache struct {
+	mu      sync.Mutex
+	entries map[int64]*SearchResult
+	ttl     time.Duration
+	now     func() time.Time
+	// lastID 最近分配的 ID，以纳秒时间戳为基础，重启后也不会与旧消息上的 ID 重复
+	lastID int64
+}
+
+// NewSearchCache 创建搜索结果缓存
+func NewSearchCache(ttl time.Duration) *SearchCache {
+	return &SearchCache{
+		entries: make(map[int64]*SearchResult),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Put 保存聊天的搜索结果，覆盖之前的结果
+func (c *SearchCache) Put(chatID int64, term string, results *models.SearchResults) *SearchResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.put(chatID, &SearchResult{Term: term, Results: results, Items: results.Items()})
+}
+
+// PutList 保存一个不来自搜索的条目列表（例如浏览媒体库），覆盖之前的结果
+// 列表没有分类概览，翻页和从详情页返回与搜索结果相同
+func (c *SearchCache) PutList(chatID int64, title string, items []models.LibraryItem) *SearchResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.put(chatID, &SearchResult{Results: &models.SearchResults{}, ListTitle: title, Items: items})
+}
+
+// put 保存聊天的结果，调用方需持有锁
+func (c *SearchCache) put(chatID int64, result *SearchResult) *SearchResult {
+	now := c.now()
+	// 顺便清理过期的结果，避免长期运行时占用内存
+	for id, entry := range c.entries {
+		if now.Sub(entry.createdAt) >= c.ttl {
+			delete(c.entries, id)
+		}
+	}
+
+	id := now.UnixNano()
+	if id <= c.lastID {
+		id = c.lastID + 1
+	}
+	c.lastID = id
+
+	result.ID = strconv.FormatInt(id, 36)
+	result.createdAt = now
+	c.entries[chatID] = result
+	return result
+}
+
+// Get 获取聊天 ID 为 searchID 的搜索结果
+// 不存在、已过期或已被之后的搜索覆盖时返回 false
+func (c *SearchCache) Get(chatID int64, searchID string) (*SearchResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, ok := c.entries[chatID]
+	if !ok || result.ID != searchID {
+		return nil, false
+	}
+	if c.now().Sub(result.createdAt) >= c.ttl {
+		delete(c.entries, chatID)
+		return nil, false
+	}
+	return result, true
+}
+
+// ShowList 将聊天 ID 为 searchID 的搜索结果当前浏览的条目列表切换为 items，并回到第一页
+// 搜索结果不存在、已过期或已被覆盖时返回 false
+func (c *SearchCache) ShowList(chatID int64, searchID string, title string, items []models.LibraryItem) (*SearchResult, bool) {
+	return c.update(chatID, searchID, func(result *SearchResult) {
+		result.ListTitle = title
+		result.Items = items
+		result.CurrentPage = 0
+	})
+}
+
+// SetPage 记录聊天 ID 为 searchID 的搜索结果当前浏览的页码
+func (c *SearchCache) SetPage(chatID int64, searchID string, page int) {
+	c.update(chatID, searchID, func(result *SearchResult) {
+		result.CurrentPage = page
+	})
+}
+
+// update 修改聊天的搜索结果
+// 修改的是副本，其他协程已经取得的结果不会被改动
+func (c *SearchCache) update(chatID int64, searchID string, fn func(result *SearchResult)) (*SearchResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, ok := c.entries[chatID]
+	if !ok || result.ID != searchID || c.now().Sub(result.createdAt) >= c.ttl {
+		return nil, false
+	}
+
+	updated := *result
+	fn(&updated)
+	c.entries[chatID] = &updated
+	return &updated, true
+}
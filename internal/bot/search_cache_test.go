@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+func makeItems(n int) []models.LibraryItem {
+	items := make([]models.LibraryItem, n)
+	for i := range items {
+		items[i].ID = fmt.Sprintf("li_%d", i)
+	}
+	return items
+}
+
+func TestSearchCacheExpiry(t *testing.T) {
+	cache := NewSearchCache(time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	put := cache.Put(1, "三体", &models.SearchResults{Books: makeItems(3)})
+
+	if result, ok := cache.Get(1, put.ID); !ok || result.Term != "三体" {
+		t.Fatalf("期望获取到搜索结果，实际得到 %+v %v", result, ok)
+	}
+	if _, ok := cache.Get(2, put.ID); ok {
+		t.Error("其他聊天不应获取到搜索结果")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get(1, put.ID); ok {
+		t.Error("期望搜索结果过期")
+	}
+}
+
+func TestSearchCacheShowList(t *testing.T) {
+	cache := NewSearchCache(time.Minute)
+	original := cache.Put(1, "刘慈欣", &models.SearchResults{Books: makeItems(12)})
+	cache.SetPage(1, original.ID, 2)
+
+	result, ok := cache.ShowList(1, original.ID, "作者: 刘慈欣", makeItems(3))
+	if !ok {
+		t.Fatal("期望切换条目列表成功")
+	}
+	if result.ListTitle != "作者: 刘慈欣" || len(result.Items) != 3 || result.CurrentPage != 0 {
+		t.Errorf("切换后的列表不正确: %+v", result)
+	}
+	// 之前取得的结果不受影响
+	if len(original.Items) != 12 || original.CurrentPage != 0 {
+		t.Errorf("原来的结果不应被修改: %+v", original)
+	}
+
+	if _, ok := cache.ShowList(2, original.ID, "作者: 刘慈欣", nil); ok {
+		t.Error("没有搜索结果的聊天不应切换成功")
+	}
+}
+
+func TestSearchCachePutList(t *testing.T) {
+	cache := NewSearchCache(time.Minute)
+	search := cache.Put(1, "三体", &models.SearchResults{Books: makeItems(3)})
+
+	result := cache.PutList(1, "媒体库: 有声书", makeItems(7))
+	if result.ListTitle != "媒体库: 有声书" || len(result.Items) != 7 {
+		t.Errorf("保存的列表不正确: %+v", result)
+	}
+	if result.Results == nil || result.Results.HasCategories() {
+		t.Error("浏览列表不应有分类概览")
+	}
+
+	got, ok := cache.Get(1, result.ID)
+	if !ok || got != result {
+		t.Error("期望列表覆盖之前的搜索结果")
+	}
+	if _, ok := cache.Get(1, search.ID); ok {
+		t.Error("被覆盖的搜索结果不应再能取得")
+	}
+}
+
+func TestSearchCacheRejectsStaleID(t *testing.T) {
+	cache := NewSearchCache(time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	first := cache.Put(1, "三体", &models.SearchResults{Books: makeItems(3)})
+	// 时间相同时也要分配不同的 ID
+	second := cache.Put(1, "球状闪电", &models.SearchResults{Books: makeItems(8)})
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("期望两次搜索的 ID 不同，实际为 %q 和 %q", first.ID, second.ID)
+	}
+
+	if _, ok := cache.Get(1, first.ID); ok {
+		t.Error("旧消息上的按钮不应取得之后的搜索结果")
+	}
+	if _, ok := cache.ShowList(1, first.ID, "作者: 刘慈欣", makeItems(2)); ok {
+		t.Error("旧消息上的按钮不应修改之后的搜索结果")
+	}
+	cache.SetPage(1, first.ID, 1)
+
+	got, ok := cache.Get(1, second.ID)
+	if !ok || got.Term != "球状闪电" || got.CurrentPage != 0 || len(got.Items) != 8 {
+		t.Errorf("最近一次的搜索结果不应被改动: %+v %v", got, ok)
+	}
+}
+
+func TestSearchResultPage(t *testing.T) {
+	result := &SearchResult{Items: makeItems(12)}
+
+	tests := []struct {
+		page        int
+		wantOffset  int
+		wantLen     int
+		wantCurrent int
+	}{
+		{0, 0, 5, 0},
+		{1, 5, 5, 1},
+		{2, 10, 2, 2},
+		{5, 10, 2, 2}, // 超出范围时停留在最后一页
+		{-1, 0, 5, 0}, // 负数页码回到第一页
+	}
+
+	for _, tt := range tests {
+		items, offset, current := result.Page(tt.page, 5)
+		if offset != tt.wantOffset || len(items) != tt.wantLen || current != tt.wantCurrent {
+			t.Errorf("Page(%d) = (%d 条, 偏移 %d, 第 %d 页)，期望 (%d 条, 偏移 %d, 第 %d 页)",
+				tt.page, len(items), offset, current, tt.wantLen, tt.wantOffset, tt.wantCurrent)
+		}
+	}
+
+	if pages := result.PageCount(5); pages != 3 {
+		t.Errorf("期望共 3 页，实际 %d 页", pages)
+	}
+	if pages := (&SearchResult{}).PageCount(5); pages != 1 {
+		t.Errorf("空结果期望 1 页，实际 %d 页", pages)
+	}
+}
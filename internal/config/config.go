@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,6 +21,30 @@ type Config struct {
 	Debug               bool
 	ProxyAddress        string
 	AllowedUserIDs      []int64
+	// UserRoles 按 Telegram 用户 ID 配置的角色名称（admin、operator、listener）
+	UserRoles map[int64]string
+	// RequestTimeout 单个 Audiobookshelf API 请求的超时时间
+	RequestTimeout time.Duration
+	// ShutdownTimeout 收到退出信号后等待进行中请求结束的最长时间
+	ShutdownTimeout time.Duration
+	// RetryMaxAttempts GET 请求的最大尝试次数（包含首次请求）
+	RetryMaxAttempts int
+	// RetryInitialBackoff 第一次重试前的等待时间
+	RetryInitialBackoff time.Duration
+	// RetryMaxBackoff 重试等待时间的上限
+	RetryMaxBackoff time.Duration
+	// BreakerThreshold 连续失败多少次后打开熔断器，0 表示不启用
+	BreakerThreshold int
+	// BreakerCooldown 熔断器打开后的冷却时间
+	BreakerCooldown time.Duration
+	// DataDir 保存账户绑定等持久化数据的目录
+	DataDir string
+	// CredentialsKey 加密已保存凭据的密钥，为空时使用数据目录中随机生成的密钥文件
+	CredentialsKey string
+	// NotifyInterval 检查订阅媒体库新条目的间隔，0 表示不检查
+	NotifyInterval time.Duration
+	// EventsEnabled 是否通过 socket.io 接收 Audiobookshelf 的实时事件
+	EventsEnabled bool
 }
 
 // LoadConfig loads configuration from environment variables
@@ -37,6 +62,18 @@ func LoadConfig() *Config {
 		Debug:               getEnvWithDefault("DEBUG", "false") == "true",
 		ProxyAddress:        getEnvWithDefault("PROXY_ADDRESS", ""),
 		AllowedUserIDs:      allowedUserIDs,
+		UserRoles:           parseUserRoles(getEnvWithDefault("USER_ROLES", "")),
+		RequestTimeout:      parseSeconds(getEnvWithDefault("AUDIOBOOKSHELF_TIMEOUT", ""), 15*time.Second),
+		ShutdownTimeout:     parseSeconds(getEnvWithDefault("SHUTDOWN_TIMEOUT", ""), 10*time.Second),
+		RetryMaxAttempts:    parseInt(getEnvWithDefault("AUDIOBOOKSHELF_RETRY_ATTEMPTS", ""), 3),
+		RetryInitialBackoff: parseSeconds(getEnvWithDefault("AUDIOBOOKSHELF_RETRY_BACKOFF", ""), 500*time.Millisecond),
+		RetryMaxBackoff:     parseSeconds(getEnvWithDefault("AUDIOBOOKSHELF_RETRY_MAX_BACKOFF", ""), 5*time.Second),
+		BreakerThreshold:    parseInt(getEnvWithDefault("AUDIOBOOKSHELF_BREAKER_THRESHOLD", ""), 5),
+		BreakerCooldown:     parseSeconds(getEnvWithDefault("AUDIOBOOKSHELF_BREAKER_COOLDOWN", ""), 30*time.Second),
+		DataDir:             getEnvWithDefault("DATA_DIR", "data"),
+		CredentialsKey:      getEnvWithDefault("CREDENTIALS_KEY", ""),
+		NotifyInterval:      parseSeconds(getEnvWithDefault("NOTIFY_INTERVAL", ""), 5*time.Minute),
+		EventsEnabled:       getEnvWithDefault("AUDIOBOOKSHELF_EVENTS", "true") != "false",
 	}
 
 	portStr := getEnvWithDefault("AUDIOBOOKSHELF_PORT", "")
@@ -130,3 +167,56 @@ func parseAllowedUserIDs(idsStr string) []int64 {
 	}
 	return ids
 }
+
+// parseUserRoles 解析用户角色配置，格式为 "ID:角色,ID:角色"
+// 角色名称统一转为小写，是否有效由使用方检查
+func parseUserRoles(value string) map[int64]string {
+	roles := make(map[int64]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		idStr, role, ok := strings.Cut(entry, ":")
+		id, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+		if !ok || err != nil || strings.TrimSpace(role) == "" {
+			log.Printf("无效的用户角色配置 %q，已忽略", entry)
+			continue
+		}
+		roles[id] = strings.ToLower(strings.TrimSpace(role))
+	}
+	return roles
+}
+
+// parseSeconds 将以秒为单位的字符串解析为时间间隔，解析失败或为空时返回默认值
+func parseSeconds(value string, defaultValue time.Duration) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultValue
+	}
+
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		log.Printf("无效的时间配置 %q，使用默认值 %s", value, defaultValue)
+		return defaultValue
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// parseInt 解析非负整数配置，解析失败或为空时返回默认值
+func parseInt(value string, defaultValue int) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("无效的整数配置 %q，使用默认值 %d", value, defaultValue)
+		return defaultValue
+	}
+
+	return n
+}
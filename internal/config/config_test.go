@@ -52,4 +52,18 @@ func TestLoadConfig(t *testing.T) {
 	if cfg.AudiobookshelfPort != 13378 {
 		t.Errorf("期望 AudiobookshelfPort 为 13378，实际得到 %d", cfg.AudiobookshelfPort)
 	}
-}
\ No newline at end of file
+}
+
+func TestParseUserRoles(t *testing.T) {
+	roles := parseUserRoles(" 123:Admin, 456:operator,bad,789:, abc:listener,1001:listener")
+
+	expected := map[int64]string{123: "admin", 456: "operator", 1001: "listener"}
+	if len(roles) != len(expected) {
+		t.Fatalf("期望解析出 %d 个角色，实际得到 %v", len(expected), roles)
+	}
+	for id, role := range expected {
+		if roles[id] != role {
+			t.Errorf("用户 %d 期望角色为 %q，实际得到 %q", id, role, roles[id])
+		}
+	}
+}
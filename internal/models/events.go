@@ -0,0 +1,61 @@
+package models
+
+// LibraryScan scan_start 和 scan_complete 事件中的扫描信息
+type LibraryScan struct {
+	// ID 被扫描的媒体库 ID
+	ID string `json:"id"`
+	// Type 扫描类型，例如 "scan"
+	Type string `json:"type"`
+	// Name 被扫描的媒体库名称
+	Name    string      `json:"name"`
+	Results ScanResults `json:"results"`
+}
+
+// ScanResults 扫描结果统计，扫描开始时均为 0
+type ScanResults struct {
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+	Missing int `json:"missing"`
+}
+
+// Task 服务器后台任务，例如媒体库扫描
+// 时间字段为毫秒时间戳
+type Task struct {
+	ID          string                 `json:"id"`
+	Action      string                 `json:"action"`
+	Data        map[string]interface{} `json:"data"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Error       string                 `json:"error"`
+	IsFailed    bool                   `json:"isFailed"`
+	IsFinished  bool                   `json:"isFinished"`
+	StartedAt   int64                  `json:"startedAt"`
+	FinishedAt  int64                  `json:"finishedAt"`
+}
+
+// OnlineUser user_online 和 user_offline 事件中的用户信息
+type OnlineUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Type     string `json:"type"`
+	// Session 用户正在进行的播放会话，没有播放时为 nil
+	Session  *PlaybackSession `json:"session"`
+	LastSeen int64            `json:"lastSeen"`
+}
+
+// OnlineUsers /api/users/online 的响应
+type OnlineUsers struct {
+	// UsersOnline 当前连接着 Audiobookshelf 的用户
+	UsersOnline []OnlineUser `json:"usersOnline"`
+	// OpenSessions 所有进行中的播放会话
+	OpenSessions []PlaybackSession `json:"openSessions"`
+}
+
+// ProgressUpdate user_item_progress_updated 事件的数据
+type ProgressUpdate struct {
+	// ID 收听进度 ID
+	ID                string        `json:"id"`
+	SessionID         string        `json:"sessionId"`
+	DeviceDescription string        `json:"deviceDescription"`
+	Data              MediaProgress `json:"data"`
+}
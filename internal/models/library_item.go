@@ -0,0 +1,152 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 条目的媒体类型
+const (
+	MediaTypeBook    = "book"
+	MediaTypePodcast = "podcast"
+)
+
+// LibraryItem 媒体库中的条目（一本书或一个播客）
+// 媒体信息根据 MediaType 解析到 Book 或 Podcast 中
+type LibraryItem struct {
+	ID           string        `json:"id"`
+	Ino          string        `json:"ino"`
+	LibraryID    string        `json:"libraryId"`
+	FolderID     string        `json:"folderId"`
+	Path         string        `json:"path"`
+	RelPath      string        `json:"relPath"`
+	IsFile       bool          `json:"isFile"`
+	MtimeMs      int64         `json:"mtimeMs"`
+	AddedAt      int64         `json:"addedAt"`
+	UpdatedAt    int64         `json:"updatedAt"`
+	IsMissing    bool          `json:"isMissing"`
+	IsInvalid    bool          `json:"isInvalid"`
+	MediaType    string        `json:"mediaType"`
+	NumFiles     int           `json:"numFiles"`
+	Size         int64         `json:"size"`
+	LibraryFiles []LibraryFile `json:"libraryFiles,omitempty"`
+
+	Book    *BookMedia    `json:"-"`
+	Podcast *PodcastMedia `json:"-"`
+}
+
+// libraryItemJSON 用于 LibraryItem 的 JSON 编解码，避免递归调用自定义方法
+type libraryItemJSON LibraryItem
+
+// UnmarshalJSON 根据 mediaType 将 media 字段解析为对应的类型
+func (item *LibraryItem) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		libraryItemJSON
+		Media json.RawMessage `json:"media"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*item = LibraryItem(raw.libraryItemJSON)
+	if len(raw.Media) == 0 || string(raw.Media) == "null" {
+		return nil
+	}
+
+	switch item.MediaType {
+	case MediaTypePodcast:
+		item.Podcast = &PodcastMedia{}
+		if err := json.Unmarshal(raw.Media, item.Podcast); err != nil {
+			return fmt.Errorf("error unmarshaling podcast media: %w", err)
+		}
+	default:
+		// 旧版本的接口在部分场景下不返回 mediaType，此时按图书处理
+		item.Book = &BookMedia{}
+		if err := json.Unmarshal(raw.Media, item.Book); err != nil {
+			return fmt.Errorf("error unmarshaling book media: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON 将媒体信息重新编码到 media 字段
+func (item LibraryItem) MarshalJSON() ([]byte, error) {
+	var media interface{}
+	if item.Podcast != nil {
+		media = item.Podcast
+	} else if item.Book != nil {
+		media = item.Book
+	}
+
+	return json.Marshal(struct {
+		libraryItemJSON
+		Media interface{} `json:"media,omitempty"`
+	}{libraryItemJSON(item), media})
+}
+
+// Title 返回条目标题
+func (item *LibraryItem) Title() string {
+	switch {
+	case item.Book != nil:
+		return item.Book.Metadata.Title
+	case item.Podcast != nil:
+		return item.Podcast.Metadata.Title
+	}
+	return ""
+}
+
+// AuthorNames 返回以逗号分隔的作者名称
+func (item *LibraryItem) AuthorNames() string {
+	switch {
+	case item.Book != nil:
+		return item.Book.Metadata.AuthorNames()
+	case item.Podcast != nil:
+		return item.Podcast.Metadata.Author
+	}
+	return ""
+}
+
+// Duration 返回条目总时长（秒），播客返回所有剧集时长之和
+func (item *LibraryItem) Duration() float64 {
+	switch {
+	case item.Book != nil:
+		return item.Book.Duration
+	case item.Podcast != nil:
+		var total float64
+		for _, episode := range item.Podcast.Episodes {
+			total += episode.Duration()
+		}
+		return total
+	}
+	return 0
+}
+
+// LibraryFile 条目目录中的文件
+type LibraryFile struct {
+	Ino       string       `json:"ino"`
+	Metadata  FileMetadata `json:"metadata"`
+	AddedAt   int64        `json:"addedAt"`
+	UpdatedAt int64        `json:"updatedAt"`
+	FileType  string       `json:"fileType"`
+}
+
+// FileMetadata 文件的基本信息
+type FileMetadata struct {
+	Filename    string `json:"filename"`
+	Ext         string `json:"ext"`
+	Path        string `json:"path"`
+	RelPath     string `json:"relPath"`
+	Size        int64  `json:"size"`
+	MtimeMs     int64  `json:"mtimeMs"`
+	CtimeMs     int64  `json:"ctimeMs"`
+	BirthtimeMs int64  `json:"birthtimeMs"`
+}
+
+// LibraryItemsPage 分页获取的媒体库条目
+type LibraryItemsPage struct {
+	Results []LibraryItem `json:"results"`
+	Total   int           `json:"total"`
+	Limit   int           `json:"limit"`
+	Page    int           `json:"page"`
+}
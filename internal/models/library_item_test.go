@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLibraryItemUnmarshalBook(t *testing.T) {
+	data := `{
+		"id": "li_1",
+		"libraryId": "lib_1",
+		"mediaType": "book",
+		"media": {
+			"metadata": {
+				"title": "三体",
+				"authors": [{"id": "aut_1", "name": "刘慈欣"}],
+				"narrators": ["冯雪松"],
+				"series": [{"id": "ser_1", "name": "地球往事", "sequence": "1"}],
+				"isbn": "9787536692930"
+			},
+			"chapters": [{"id": 0, "start": 0, "end": 60, "title": "第一章"}],
+			"duration": 3600.5
+		}
+	}`
+
+	var item LibraryItem
+	if err := json.Unmarshal([]byte(data), &item); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if item.Book == nil || item.Podcast != nil {
+		t.Fatalf("期望解析为图书，实际得到 Book=%v Podcast=%v", item.Book, item.Podcast)
+	}
+	if item.Title() != "三体" || item.AuthorNames() != "刘慈欣" {
+		t.Errorf("标题或作者不正确: %q %q", item.Title(), item.AuthorNames())
+	}
+	if series := item.Book.Metadata.SeriesNames(); series != "地球往事 #1" {
+		t.Errorf("期望系列为 '地球往事 #1'，实际得到 %q", series)
+	}
+	if item.Book.ChapterCount() != 1 || item.Duration() != 3600.5 {
+		t.Errorf("章节数或时长不正确: %d %f", item.Book.ChapterCount(), item.Duration())
+	}
+
+	// 重新编码后应能得到相同的结果
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+	var decoded LibraryItem
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("重新解析失败: %v", err)
+	}
+	if decoded.Book == nil || decoded.Book.Metadata.ISBN != "9787536692930" {
+		t.Errorf("重新编码后媒体信息丢失: %s", encoded)
+	}
+}
+
+func TestLibraryItemUnmarshalMinifiedBook(t *testing.T) {
+	data := `{"id": "li_2", "mediaType": "book", "media": {"metadata": {"title": "球状闪电", "authorName": "刘慈欣", "seriesName": ""}, "numChapters": 12}}`
+
+	var item LibraryItem
+	if err := json.Unmarshal([]byte(data), &item); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if item.AuthorNames() != "刘慈欣" {
+		t.Errorf("期望作者为 '刘慈欣'，实际得到 %q", item.AuthorNames())
+	}
+	if item.Book.ChapterCount() != 12 {
+		t.Errorf("期望章节数为 12，实际得到 %d", item.Book.ChapterCount())
+	}
+}
+
+func TestLibraryItemUnmarshalPodcast(t *testing.T) {
+	data := `{
+		"id": "li_3",
+		"mediaType": "podcast",
+		"media": {
+			"metadata": {"title": "故事FM", "author": "爱哭鬼"},
+			"episodes": [
+				{"id": "ep_1", "title": "第一期", "audioFile": {"duration": 100}},
+				{"id": "ep_2", "title": "第二期", "audioFile": {"duration": 200}}
+			]
+		}
+	}`
+
+	var item LibraryItem
+	if err := json.Unmarshal([]byte(data), &item); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if item.Podcast == nil || item.Book != nil {
+		t.Fatalf("期望解析为播客，实际得到 Book=%v Podcast=%v", item.Book, item.Podcast)
+	}
+	if item.Title() != "故事FM" || item.AuthorNames() != "爱哭鬼" {
+		t.Errorf("标题或作者不正确: %q %q", item.Title(), item.AuthorNames())
+	}
+	if item.Duration() != 300 {
+		t.Errorf("期望总时长为 300，实际得到 %f", item.Duration())
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+// LibraryStats 媒体库的统计信息，来自 /api/libraries/{id}/stats
+// 时长以秒为单位，大小以字节为单位
+type LibraryStats struct {
+	TotalItems     int     `json:"totalItems"`
+	TotalAuthors   int     `json:"totalAuthors"`
+	TotalGenres    int     `json:"totalGenres"`
+	TotalDuration  float64 `json:"totalDuration"`
+	TotalSize      int64   `json:"totalSize"`
+	NumAudioTracks int     `json:"numAudioTracks"`
+	// LongestItems 时长最长的条目，从长到短排列
+	LongestItems []LibraryStatsItem `json:"longestItems"`
+	// LargestItems 占用空间最大的条目，从大到小排列
+	LargestItems []LibraryStatsItem `json:"largestItems"`
+	// AuthorsWithCount 条目最多的作者，从多到少排列
+	AuthorsWithCount []AuthorCount `json:"authorsWithCount"`
+	// GenresWithCount 条目最多的流派，从多到少排列
+	GenresWithCount []GenreCount `json:"genresWithCount"`
+}
+
+// LibraryStatsItem 统计中列出的条目，LongestItems 只有 Duration，LargestItems 只有 Size
+type LibraryStatsItem struct {
+	ID       string  `json:"id"`
+	Title    string  `json:"title"`
+	Duration float64 `json:"duration"`
+	Size     int64   `json:"size"`
+}
+
+// AuthorCount 作者及其条目数量
+type AuthorCount struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// GenreCount 流派及其条目数量
+type GenreCount struct {
+	Genre string `json:"genre"`
+	Count int    `json:"count"`
+}
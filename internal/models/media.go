@@ -0,0 +1,207 @@
+package models
+
+import "strings"
+
+// BookMedia 图书的媒体信息
+type BookMedia struct {
+	ID            string       `json:"id"`
+	LibraryItemID string       `json:"libraryItemId"`
+	Metadata      BookMetadata `json:"metadata"`
+	CoverPath     string       `json:"coverPath"`
+	Tags          []string     `json:"tags"`
+	AudioFiles    []AudioFile  `json:"audioFiles,omitempty"`
+	Chapters      []Chapter    `json:"chapters,omitempty"`
+	Tracks        []AudioTrack `json:"tracks,omitempty"`
+	Duration      float64      `json:"duration"`
+	Size          int64        `json:"size"`
+	// 精简格式下只返回数量
+	NumTracks     int `json:"numTracks,omitempty"`
+	NumAudioFiles int `json:"numAudioFiles,omitempty"`
+	NumChapters   int `json:"numChapters,omitempty"`
+}
+
+// ChapterCount 返回章节数量，兼容完整格式和精简格式
+func (m *BookMedia) ChapterCount() int {
+	if len(m.Chapters) > 0 {
+		return len(m.Chapters)
+	}
+	return m.NumChapters
+}
+
+// BookMetadata 图书元数据
+// 完整格式下作者、朗读者和系列以数组形式返回，精简格式下以 AuthorName 等字符串返回
+type BookMetadata struct {
+	Title             string           `json:"title"`
+	TitleIgnorePrefix string           `json:"titleIgnorePrefix,omitempty"`
+	Subtitle          string           `json:"subtitle"`
+	Authors           []AuthorRef      `json:"authors,omitempty"`
+	Narrators         []string         `json:"narrators,omitempty"`
+	Series            []SeriesSequence `json:"series,omitempty"`
+	Genres            []string         `json:"genres"`
+	PublishedYear     string           `json:"publishedYear"`
+	PublishedDate     string           `json:"publishedDate"`
+	Publisher         string           `json:"publisher"`
+	Description       string           `json:"description"`
+	ISBN              string           `json:"isbn"`
+	ASIN              string           `json:"asin"`
+	Language          string           `json:"language"`
+	Explicit          bool             `json:"explicit"`
+	Abridged          bool             `json:"abridged"`
+
+	AuthorName   string `json:"authorName,omitempty"`
+	NarratorName string `json:"narratorName,omitempty"`
+	SeriesName   string `json:"seriesName,omitempty"`
+}
+
+// AuthorNames 返回以逗号分隔的作者名称
+func (m *BookMetadata) AuthorNames() string {
+	if len(m.Authors) == 0 {
+		return m.AuthorName
+	}
+	names := make([]string, 0, len(m.Authors))
+	for _, author := range m.Authors {
+		names = append(names, author.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// NarratorNames 返回以逗号分隔的朗读者名称
+func (m *BookMetadata) NarratorNames() string {
+	if len(m.Narrators) == 0 {
+		return m.NarratorName
+	}
+	return strings.Join(m.Narrators, ", ")
+}
+
+// SeriesNames 返回带序号的系列名称，例如 "三体 #1"
+func (m *BookMetadata) SeriesNames() string {
+	if len(m.Series) == 0 {
+		return m.SeriesName
+	}
+	names := make([]string, 0, len(m.Series))
+	for _, series := range m.Series {
+		names = append(names, series.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// AuthorRef 图书关联的作者
+type AuthorRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// SeriesSequence 图书所属的系列及其在系列中的序号
+type SeriesSequence struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Sequence string `json:"sequence"`
+}
+
+// String 返回带序号的系列名称
+func (s SeriesSequence) String() string {
+	if s.Sequence == "" {
+		return s.Name
+	}
+	return s.Name + " #" + s.Sequence
+}
+
+// AudioFile 条目中的音频文件
+type AudioFile struct {
+	Index            int          `json:"index"`
+	Ino              string       `json:"ino"`
+	Metadata         FileMetadata `json:"metadata"`
+	AddedAt          int64        `json:"addedAt"`
+	UpdatedAt        int64        `json:"updatedAt"`
+	TrackNumFromMeta int          `json:"trackNumFromMeta,omitempty"`
+	DiscNumFromMeta  int          `json:"discNumFromMeta,omitempty"`
+	Format           string       `json:"format"`
+	Duration         float64      `json:"duration"`
+	BitRate          int64        `json:"bitRate"`
+	Language         string       `json:"language"`
+	Codec            string       `json:"codec"`
+	Channels         int          `json:"channels"`
+	MimeType         string       `json:"mimeType"`
+	Exclude          bool         `json:"exclude"`
+	Chapters         []Chapter    `json:"chapters,omitempty"`
+}
+
+// Chapter 章节
+type Chapter struct {
+	ID    int     `json:"id"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Title string  `json:"title"`
+}
+
+// AudioTrack 播放时使用的音轨
+type AudioTrack struct {
+	Index       int          `json:"index"`
+	StartOffset float64      `json:"startOffset"`
+	Duration    float64      `json:"duration"`
+	Title       string       `json:"title"`
+	ContentURL  string       `json:"contentUrl"`
+	MimeType    string       `json:"mimeType"`
+	Codec       string       `json:"codec"`
+	Metadata    FileMetadata `json:"metadata"`
+}
+
+// PodcastMedia 播客的媒体信息
+type PodcastMedia struct {
+	ID                   string           `json:"id"`
+	LibraryItemID        string           `json:"libraryItemId"`
+	Metadata             PodcastMetadata  `json:"metadata"`
+	CoverPath            string           `json:"coverPath"`
+	Tags                 []string         `json:"tags"`
+	Episodes             []PodcastEpisode `json:"episodes,omitempty"`
+	AutoDownloadEpisodes bool             `json:"autoDownloadEpisodes"`
+	Size                 int64            `json:"size"`
+	// 精简格式下只返回剧集数量
+	NumEpisodes int `json:"numEpisodes,omitempty"`
+}
+
+// PodcastMetadata 播客元数据
+type PodcastMetadata struct {
+	Title         string   `json:"title"`
+	Author        string   `json:"author"`
+	Description   string   `json:"description"`
+	ReleaseDate   string   `json:"releaseDate"`
+	Genres        []string `json:"genres"`
+	FeedURL       string   `json:"feedUrl"`
+	ImageURL      string   `json:"imageUrl"`
+	ItunesPageURL string   `json:"itunesPageUrl"`
+	ItunesID      string   `json:"itunesId"`
+	Language      string   `json:"language"`
+	Explicit      bool     `json:"explicit"`
+	Type          string   `json:"type"`
+}
+
+// PodcastEpisode 播客剧集
+type PodcastEpisode struct {
+	ID            string      `json:"id"`
+	LibraryItemID string      `json:"libraryItemId"`
+	Index         int         `json:"index"`
+	Season        string      `json:"season"`
+	Episode       string      `json:"episode"`
+	EpisodeType   string      `json:"episodeType"`
+	Title         string      `json:"title"`
+	Subtitle      string      `json:"subtitle"`
+	Description   string      `json:"description"`
+	PubDate       string      `json:"pubDate"`
+	AudioFile     *AudioFile  `json:"audioFile,omitempty"`
+	AudioTrack    *AudioTrack `json:"audioTrack,omitempty"`
+	PublishedAt   int64       `json:"publishedAt"`
+	AddedAt       int64       `json:"addedAt"`
+	UpdatedAt     int64       `json:"updatedAt"`
+}
+
+// Duration 返回剧集时长（秒）
+func (e *PodcastEpisode) Duration() float64 {
+	if e.AudioFile != nil {
+		return e.AudioFile.Duration
+	}
+	if e.AudioTrack != nil {
+		return e.AudioTrack.Duration
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package models
+
+// 可以单独切换的用户权限，用于按钮回调等需要以名称指代权限的场合
+const (
+	PermissionDownload     = "download"
+	PermissionUpdate       = "update"
+	PermissionDelete       = "delete"
+	PermissionUpload       = "upload"
+	PermissionExplicit     = "explicit"
+	PermissionAllLibraries = "libraries"
+	PermissionAllTags      = "tags"
+)
+
+// Has 返回名称对应的权限是否开启，名称未知时返回 false
+func (p *Permissions) Has(name string) bool {
+	field := p.field(name)
+	return field != nil && *field
+}
+
+// Toggle 切换名称对应的权限，名称未知时返回 false
+func (p *Permissions) Toggle(name string) bool {
+	field := p.field(name)
+	if field == nil {
+		return false
+	}
+	*field = !*field
+	return true
+}
+
+// field 返回名称对应的权限字段
+func (p *Permissions) field(name string) *bool {
+	switch name {
+	case PermissionDownload:
+		return &p.Download
+	case PermissionUpdate:
+		return &p.Update
+	case PermissionDelete:
+		return &p.Delete
+	case PermissionUpload:
+		return &p.Upload
+	case PermissionExplicit:
+		return &p.AccessExplicitContent
+	case PermissionAllLibraries:
+		return &p.AccessAllLibraries
+	case PermissionAllTags:
+		return &p.AccessAllTags
+	}
+	return nil
+}
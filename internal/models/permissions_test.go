@@ -0,0 +1,21 @@
+package models
+
+import "testing"
+
+func TestPermissionsToggle(t *testing.T) {
+	var permissions Permissions
+
+	if !permissions.Toggle(PermissionDownload) || !permissions.Download || !permissions.Has(PermissionDownload) {
+		t.Errorf("期望开启下载权限: %+v", permissions)
+	}
+	if !permissions.Toggle(PermissionExplicit) || !permissions.AccessExplicitContent {
+		t.Errorf("期望开启访问成人内容的权限: %+v", permissions)
+	}
+	if !permissions.Toggle(PermissionDownload) || permissions.Download {
+		t.Errorf("再次切换应关闭下载权限: %+v", permissions)
+	}
+
+	if permissions.Toggle("unknown") || permissions.Has("unknown") {
+		t.Error("未知的权限名称应返回 false")
+	}
+}
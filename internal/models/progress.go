@@ -0,0 +1,69 @@
+package models
+
+// MediaProgress 用户在某个条目（或播客剧集）上的播放进度
+// Progress 为 0 到 1 之间的比例，CurrentTime 和 Duration 以秒为单位
+type MediaProgress struct {
+	ID                        string  `json:"id"`
+	UserID                    string  `json:"userId"`
+	LibraryItemID             string  `json:"libraryItemId"`
+	EpisodeID                 string  `json:"episodeId"`
+	MediaItemID               string  `json:"mediaItemId"`
+	MediaItemType             string  `json:"mediaItemType"`
+	Duration                  float64 `json:"duration"`
+	Progress                  float64 `json:"progress"`
+	CurrentTime               float64 `json:"currentTime"`
+	IsFinished                bool    `json:"isFinished"`
+	HideFromContinueListening bool    `json:"hideFromContinueListening"`
+	EbookLocation             string  `json:"ebookLocation"`
+	EbookProgress             float64 `json:"ebookProgress"`
+	LastUpdate                int64   `json:"lastUpdate"`
+	StartedAt                 int64   `json:"startedAt"`
+	FinishedAt                int64   `json:"finishedAt"`
+}
+
+// ItemProgress 条目及当前用户在该条目上的播放进度
+type ItemProgress struct {
+	Item *LibraryItem
+	// Progress 从未播放过或进度已被重置时为 nil
+	Progress *MediaProgress
+}
+
+// CurrentTime 返回当前播放位置（秒）
+func (p *ItemProgress) CurrentTime() float64 {
+	if p.Progress == nil {
+		return 0
+	}
+	return p.Progress.CurrentTime
+}
+
+// Duration 返回条目总时长（秒），进度中没有记录时使用条目的时长
+func (p *ItemProgress) Duration() float64 {
+	if p.Progress != nil && p.Progress.Duration > 0 {
+		return p.Progress.Duration
+	}
+	return p.Item.Duration()
+}
+
+// IsFinished 返回条目是否已听完
+func (p *ItemProgress) IsFinished() bool {
+	return p.Progress != nil && p.Progress.IsFinished
+}
+
+// Chapters 返回图书的章节，播客或没有章节信息时为 nil
+func (p *ItemProgress) Chapters() []Chapter {
+	if p.Item.Book == nil {
+		return nil
+	}
+	return p.Item.Book.Chapters
+}
+
+// CurrentChapter 返回当前播放位置所在章节的下标，没有章节信息时返回 -1
+func (p *ItemProgress) CurrentChapter() int {
+	current := p.CurrentTime()
+	for i, chapter := range p.Chapters() {
+		if current >= chapter.Start && current < chapter.End {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestItemProgressCurrentChapter(t *testing.T) {
+	book := &LibraryItem{
+		MediaType: MediaTypeBook,
+		Book: &BookMedia{
+			Duration: 1200,
+			Chapters: []Chapter{
+				{ID: 0, Start: 0, End: 600, Title: "第一章"},
+				{ID: 1, Start: 600, End: 1200, Title: "第二章"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		progress *MediaProgress
+		chapter  int
+		duration float64
+	}{
+		{"未开始", nil, 0, 1200},
+		{"第二章", &MediaProgress{CurrentTime: 700}, 1, 1200},
+		{"使用进度记录的时长", &MediaProgress{CurrentTime: 100, Duration: 1250}, 0, 1250},
+		{"超出章节范围", &MediaProgress{CurrentTime: 1200}, -1, 1200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ItemProgress{Item: book, Progress: tt.progress}
+			if chapter := p.CurrentChapter(); chapter != tt.chapter {
+				t.Errorf("期望章节下标为 %d，实际为 %d", tt.chapter, chapter)
+			}
+			if duration := p.Duration(); duration != tt.duration {
+				t.Errorf("期望时长为 %v，实际为 %v", tt.duration, duration)
+			}
+		})
+	}
+}
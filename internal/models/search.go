@@ -0,0 +1,73 @@
+package models
+
+// SearchResults 媒体库搜索结果，按类别分组
+type SearchResults struct {
+	Books     []LibraryItem
+	Podcasts  []LibraryItem
+	Authors   []Author
+	Series    []SeriesResult
+	Tags      []TagResult
+	Narrators []NarratorResult
+}
+
+// Items 返回搜索到的全部条目，图书在前，播客在后
+func (r *SearchResults) Items() []LibraryItem {
+	items := make([]LibraryItem, 0, len(r.Books)+len(r.Podcasts))
+	items = append(items, r.Books...)
+	return append(items, r.Podcasts...)
+}
+
+// HasCategories 是否搜索到了作者、系列、标签或朗读者
+func (r *SearchResults) HasCategories() bool {
+	return len(r.Authors) > 0 || len(r.Series) > 0 || len(r.Tags) > 0 || len(r.Narrators) > 0
+}
+
+// IsEmpty 是否没有任何搜索结果
+func (r *SearchResults) IsEmpty() bool {
+	return len(r.Books) == 0 && len(r.Podcasts) == 0 && !r.HasCategories()
+}
+
+// Author 作者，作者属于某个媒体库
+type Author struct {
+	ID          string `json:"id"`
+	ASIN        string `json:"asin"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ImagePath   string `json:"imagePath"`
+	LibraryID   string `json:"libraryId"`
+	AddedAt     int64  `json:"addedAt"`
+	UpdatedAt   int64  `json:"updatedAt"`
+	NumBooks    int    `json:"numBooks"`
+}
+
+// Series 系列，系列属于某个媒体库
+type Series struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	LibraryID   string `json:"libraryId"`
+	AddedAt     int64  `json:"addedAt"`
+	UpdatedAt   int64  `json:"updatedAt"`
+}
+
+// SeriesResult 搜索到的系列及其中匹配的图书
+type SeriesResult struct {
+	Series Series        `json:"series"`
+	Books  []LibraryItem `json:"books"`
+}
+
+// TagResult 搜索到的标签
+// 同名标签可能出现在多个媒体库中，LibraryIDs 记录出现过的媒体库
+type TagResult struct {
+	Name       string   `json:"name"`
+	NumItems   int      `json:"numItems"`
+	LibraryIDs []string `json:"-"`
+}
+
+// NarratorResult 搜索到的朗读者
+// 同名朗读者可能出现在多个媒体库中，LibraryIDs 记录出现过的媒体库
+type NarratorResult struct {
+	Name       string   `json:"name"`
+	NumBooks   int      `json:"numBooks"`
+	LibraryIDs []string `json:"-"`
+}
@@ -28,17 +28,6 @@ type LibraryInfo struct {
 	Settings     interface{} `json:"settings"`
 }
 
-// Book 书籍信息
-// 根据API响应，我们只需要用到path、size、addedAt字段
-// 这些字段来自libraryItem对象
-// 现在添加libraryId字段以显示对应的媒体库，并使用relPath代替path以提高安全性
-type Book struct {
-	LibraryID string `json:"libraryId"`
-	RelPath   string `json:"relPath"`
-	Size      int64  `json:"size"`
-	AddedAt   int64  `json:"addedAt"`
-}
-
 // ServerInfo 服务器基本信息
 type ServerInfo struct {
 	ID            string `json:"id"`
@@ -70,11 +59,29 @@ type UserInfo struct {
 	IsActive      bool   `json:"isActive"`
 	LastSeen      int64  `json:"lastSeen"`
 	Permissions   Permissions `json:"permissions"`
-	MediaProgress []interface{} `json:"mediaProgress"` // 根据实际情况调整类型
+	// LibrariesAccessible 权限中没有 AccessAllLibraries 时可以访问的媒体库 ID
+	LibrariesAccessible []string `json:"librariesAccessible"`
+	// ItemTagsSelected 权限中没有 AccessAllTags 时可以（或不可以）访问的标签
+	ItemTagsSelected []string `json:"itemTagsSelected"`
+	MediaProgress []MediaProgress `json:"mediaProgress"`
 	CreatedAt     int64  `json:"createdAt"`
 	UpdatedAt     int64  `json:"updatedAt"`
 }
 
+// LoginResponse 登录接口的响应
+type LoginResponse struct {
+	User                 UserInfo `json:"user"`
+	UserDefaultLibraryID string   `json:"userDefaultLibraryId"`
+}
+
+// Audiobookshelf 的用户类型
+const (
+	UserTypeRoot  = "root"
+	UserTypeAdmin = "admin"
+	UserTypeUser  = "user"
+	UserTypeGuest = "guest"
+)
+
 // Permissions 用户权限
 type Permissions struct {
 	Download bool `json:"download"`
@@ -84,6 +91,8 @@ type Permissions struct {
 	AccessAllLibraries bool `json:"accessAllLibraries"`
 	AccessAllTags      bool `json:"accessAllTags"`
 	AccessExplicitContent bool `json:"accessExplicitContent"`
+	// SelectedTagsNotAccessible 为 true 时 ItemTagsSelected 是禁止访问的标签，否则是允许访问的标签
+	SelectedTagsNotAccessible bool `json:"selectedTagsNotAccessible"`
 }
 
 // Settings 服务器设置
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PlaybackSession 播放会话
+// 时间字段（startedAt、updatedAt）为毫秒时间戳，其余时长以秒为单位
+type PlaybackSession struct {
+	ID            string       `json:"id"`
+	UserID        string       `json:"userId"`
+	LibraryID     string       `json:"libraryId"`
+	LibraryItemID string       `json:"libraryItemId"`
+	BookID        string       `json:"bookId"`
+	EpisodeID     string       `json:"episodeId"`
+	MediaType     string       `json:"mediaType"`
+	MediaMetadata BookMetadata `json:"mediaMetadata"`
+	Chapters      []Chapter    `json:"chapters"`
+	DisplayTitle  string       `json:"displayTitle"`
+	DisplayAuthor string       `json:"displayAuthor"`
+	CoverPath     string       `json:"coverPath"`
+	Duration      float64      `json:"duration"`
+	PlayMethod    int          `json:"playMethod"`
+	MediaPlayer   string       `json:"mediaPlayer"`
+	DeviceInfo    *DeviceInfo  `json:"deviceInfo"`
+	ServerVersion string       `json:"serverVersion"`
+	// Date 会话日期（YYYY-MM-DD）
+	Date          string  `json:"date"`
+	DayOfWeek     string  `json:"dayOfWeek"`
+	TimeListening float64 `json:"timeListening"`
+	StartTime     float64 `json:"startTime"`
+	CurrentTime   float64 `json:"currentTime"`
+	StartedAt     int64   `json:"startedAt"`
+	UpdatedAt     int64   `json:"updatedAt"`
+	// User 会话所属的用户，只有 /api/sessions 的响应中包含
+	User *SessionUser `json:"user,omitempty"`
+}
+
+// SessionUser 会话所属用户的简要信息
+type SessionUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// CurrentChapter 返回当前播放位置所在的章节，没有章节信息时返回 nil
+func (s *PlaybackSession) CurrentChapter() *Chapter {
+	for i := range s.Chapters {
+		chapter := &s.Chapters[i]
+		if s.CurrentTime >= chapter.Start && s.CurrentTime < chapter.End {
+			return chapter
+		}
+	}
+	return nil
+}
+
+// Title 返回会话对应的书名，缺失时使用显示标题
+func (s *PlaybackSession) Title() string {
+	if s.MediaMetadata.Title != "" {
+		return s.MediaMetadata.Title
+	}
+	return s.DisplayTitle
+}
+
+// DeviceInfo 播放设备信息
+type DeviceInfo struct {
+	ID             string `json:"id"`
+	UserID         string `json:"userId"`
+	DeviceID       string `json:"deviceId"`
+	IPAddress      string `json:"ipAddress"`
+	BrowserName    string `json:"browserName"`
+	BrowserVersion string `json:"browserVersion"`
+	OSName         string `json:"osName"`
+	OSVersion      string `json:"osVersion"`
+	DeviceType     string `json:"deviceType"`
+	Manufacturer   string `json:"manufacturer"`
+	Model          string `json:"model"`
+	ClientName     string `json:"clientName"`
+	ClientVersion  string `json:"clientVersion"`
+}
+
+// Device 返回设备的描述，例如 "Apple iPhone (iOS 17.2)" 或 "Chrome (Windows 10)"
+func (d *DeviceInfo) Device() string {
+	name := strings.TrimSpace(d.Manufacturer + " " + d.Model)
+	if name == "" {
+		name = d.BrowserName
+	}
+	os := strings.TrimSpace(d.OSName + " " + d.OSVersion)
+	switch {
+	case name != "" && os != "":
+		return fmt.Sprintf("%s (%s)", name, os)
+	case name != "":
+		return name
+	}
+	return os
+}
+
+// Client 返回客户端的名称和版本，例如 "Abs Android 0.9.72"
+func (d *DeviceInfo) Client() string {
+	return strings.TrimSpace(d.ClientName + " " + d.ClientVersion)
+}
+
+// ListeningSessionsPage 分页获取的收听会话
+type ListeningSessionsPage struct {
+	Total        int               `json:"total"`
+	NumPages     int               `json:"numPages"`
+	Page         int               `json:"page"`
+	ItemsPerPage int               `json:"itemsPerPage"`
+	Sessions     []PlaybackSession `json:"sessions"`
+}
+
+// NowPlaying 一个播放会话的展示信息
+type NowPlaying struct {
+	Session PlaybackSession
+	// Username 会话所属用户的用户名，未知时为用户 ID
+	Username string
+	// Chapter 当前播放位置所在的章节，没有章节信息时为 nil
+	Chapter *Chapter
+	// Stale 会话长时间没有同步进度，可能已经没有客户端在播放
+	Stale bool
+}
+
+// NowPlayingReport 正在播放页面的内容
+type NowPlayingReport struct {
+	// Active 进行中的播放会话，按最后更新时间倒序排列
+	Active []NowPlaying
+	// Recent 最近结束的会话，不包括进行中的会话
+	Recent []NowPlaying
+}
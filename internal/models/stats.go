@@ -0,0 +1,44 @@
+package models
+
+import "sort"
+
+// ListeningStats 用户的收听统计
+// 时间均以秒为单位
+type ListeningStats struct {
+	TotalTime float64 `json:"totalTime"`
+	Today     float64 `json:"today"`
+	// Days 以日期（YYYY-MM-DD）为键的每日收听时间
+	Days map[string]float64 `json:"days"`
+	// DayOfWeek 以星期名称（Monday 等）为键的收听时间
+	DayOfWeek map[string]float64 `json:"dayOfWeek"`
+	// Items 以条目 ID 为键的收听记录
+	Items          map[string]ListeningStatsItem `json:"items"`
+	RecentSessions []PlaybackSession             `json:"recentSessions"`
+}
+
+// ListeningStatsItem 单个条目的收听时间
+type ListeningStatsItem struct {
+	ID            string       `json:"id"`
+	TimeListening float64      `json:"timeListening"`
+	MediaMetadata BookMetadata `json:"mediaMetadata"`
+}
+
+// TopItems 返回收听时间最长的 n 个条目
+func (s *ListeningStats) TopItems(n int) []ListeningStatsItem {
+	items := make([]ListeningStatsItem, 0, len(s.Items))
+	for id, item := range s.Items {
+		if item.ID == "" {
+			item.ID = id
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].TimeListening > items[j].TimeListening
+	})
+
+	if n >= 0 && len(items) > n {
+		items = items[:n]
+	}
+	return items
+}
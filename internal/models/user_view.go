@@ -0,0 +1,154 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PermissionNames 可以单独切换的权限，按显示顺序排列
+// 访问全部媒体库和全部标签的权限由 LibraryAccess 和 TagAccess 单独描述
+var PermissionNames = []string{
+	PermissionDownload,
+	PermissionUpdate,
+	PermissionDelete,
+	PermissionUpload,
+	PermissionExplicit,
+}
+
+// UserView 用于展示的用户信息，由完整的 UserInfo 推导而来
+type UserView struct {
+	User *UserInfo
+	// Role 用户类型对应的角色标识，例如 "👑 超级管理员"
+	Role    string
+	IsAdmin bool
+	// Online 用户是否在线，只有 OnlineKnown 为 true 时才有意义
+	Online      bool
+	OnlineKnown bool
+	// AllLibraries 为 true 时用户可以访问全部媒体库，否则只能访问 Libraries 中的媒体库
+	AllLibraries bool
+	Libraries    []string
+	// Permissions 已开启的权限名称
+	Permissions []string
+	// Tags 用户可以访问的标签的描述
+	Tags string
+}
+
+// NewUserView 创建用户的展示信息
+// online 为 nil 表示在线状态未知；libraries 用于把媒体库 ID 转换为名称
+func NewUserView(user *UserInfo, online map[string]bool, libraries []LibraryInfo) *UserView {
+	view := &UserView{
+		User:        user,
+		Role:        RoleBadge(user.Type),
+		IsAdmin:     IsAdminUser(user),
+		Permissions: PermissionSummary(user.Permissions),
+		Tags:        TagAccess(user),
+	}
+	if online != nil {
+		view.Online = online[user.ID]
+		view.OnlineKnown = true
+	}
+	view.Libraries, view.AllLibraries = LibraryAccess(user, libraries)
+	return view
+}
+
+// Presence 返回在线状态的标识，状态未知时返回空字符串
+func (v *UserView) Presence() string {
+	switch {
+	case !v.OnlineKnown:
+		return ""
+	case v.Online:
+		return "🟢 在线"
+	default:
+		return "⚪ 离线"
+	}
+}
+
+// RoleBadge 返回用户类型对应的角色标识
+func RoleBadge(userType string) string {
+	switch userType {
+	case UserTypeRoot:
+		return "👑 超级管理员"
+	case UserTypeAdmin:
+		return "🛡 管理员"
+	case UserTypeUser:
+		return "👤 普通用户"
+	case UserTypeGuest:
+		return "🚶 访客"
+	case "":
+		return "❔ 未知类型"
+	}
+	return "❔ " + userType
+}
+
+// IsAdminUser 判断用户是否为 Audiobookshelf 管理员（root 或 admin 类型）
+// 较新版本的 Audiobookshelf 中 root 用户的 ID 也是 UUID，只能根据类型判断
+func IsAdminUser(user *UserInfo) bool {
+	return user.Type == UserTypeRoot || user.Type == UserTypeAdmin
+}
+
+// PermissionLabel 返回权限的显示名称
+func PermissionLabel(name string) string {
+	switch name {
+	case PermissionDownload:
+		return "下载"
+	case PermissionUpdate:
+		return "修改"
+	case PermissionDelete:
+		return "删除"
+	case PermissionUpload:
+		return "上传"
+	case PermissionExplicit:
+		return "成人内容"
+	case PermissionAllLibraries:
+		return "所有媒体库"
+	case PermissionAllTags:
+		return "所有标签"
+	}
+	return name
+}
+
+// PermissionSummary 返回已开启的权限的显示名称，顺序与 PermissionNames 相同
+func PermissionSummary(permissions Permissions) []string {
+	var granted []string
+	for _, name := range PermissionNames {
+		if permissions.Has(name) {
+			granted = append(granted, PermissionLabel(name))
+		}
+	}
+	return granted
+}
+
+// LibraryAccess 返回用户可以访问的媒体库名称，顺序与 libraries 相同
+// 用户可以访问全部媒体库时 all 为 true；libraries 中没有的媒体库 ID 会被忽略
+func LibraryAccess(user *UserInfo, libraries []LibraryInfo) (names []string, all bool) {
+	if user.Permissions.AccessAllLibraries {
+		return nil, true
+	}
+
+	accessible := make(map[string]bool, len(user.LibrariesAccessible))
+	for _, id := range user.LibrariesAccessible {
+		accessible[id] = true
+	}
+	for _, library := range libraries {
+		if accessible[library.ID] {
+			names = append(names, library.Name)
+		}
+	}
+	return names, false
+}
+
+// TagAccess 描述用户可以访问的标签
+func TagAccess(user *UserInfo) string {
+	tags := strings.Join(user.ItemTagsSelected, "、")
+	switch {
+	case user.Permissions.AccessAllTags:
+		return "全部"
+	case user.Permissions.SelectedTagsNotAccessible && tags != "":
+		return fmt.Sprintf("除 %s 以外", tags)
+	case user.Permissions.SelectedTagsNotAccessible:
+		return "全部"
+	case tags != "":
+		return "仅 " + tags
+	}
+	return "无"
+}
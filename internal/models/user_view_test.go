@@ -0,0 +1,145 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoleBadge(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    UserInfo
+		badge   string
+		isAdmin bool
+	}{
+		{"UUID 的 root 用户", UserInfo{ID: "8c9f3b5e-1d2a-4f6b-9e7c-0a1b2c3d4e5f", Type: UserTypeRoot}, "👑 超级管理员", true},
+		{"旧版本 ID 为 root", UserInfo{ID: "root", Type: UserTypeRoot}, "👑 超级管理员", true},
+		{"管理员", UserInfo{ID: "usr_1", Type: UserTypeAdmin}, "🛡 管理员", true},
+		{"普通用户", UserInfo{ID: "usr_2", Type: UserTypeUser}, "👤 普通用户", false},
+		{"访客", UserInfo{ID: "usr_3", Type: UserTypeGuest}, "🚶 访客", false},
+		{"ID 为 root 但类型为普通用户", UserInfo{ID: "root", Type: UserTypeUser}, "👤 普通用户", false},
+		{"未知类型", UserInfo{ID: "usr_4", Type: "editor"}, "❔ editor", false},
+		{"缺少类型", UserInfo{ID: "usr_5"}, "❔ 未知类型", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if badge := RoleBadge(tt.user.Type); badge != tt.badge {
+				t.Errorf("期望角色标识为 %q，实际为 %q", tt.badge, badge)
+			}
+			if isAdmin := IsAdminUser(&tt.user); isAdmin != tt.isAdmin {
+				t.Errorf("期望 IsAdminUser 为 %v，实际为 %v", tt.isAdmin, isAdmin)
+			}
+		})
+	}
+}
+
+func TestPermissionSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions Permissions
+		want        []string
+	}{
+		{"没有权限", Permissions{}, nil},
+		{"只有下载", Permissions{Download: true}, []string{"下载"}},
+		{"全部权限", Permissions{Download: true, Update: true, Delete: true, Upload: true, AccessExplicitContent: true}, []string{"下载", "修改", "删除", "上传", "成人内容"}},
+		{"访问全部媒体库不计入", Permissions{Upload: true, AccessAllLibraries: true, AccessAllTags: true}, []string{"上传"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PermissionSummary(tt.permissions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("期望 %v，实际为 %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLibraryAccess(t *testing.T) {
+	libraries := []LibraryInfo{
+		{ID: "lib_1", Name: "有声书"},
+		{ID: "lib_2", Name: "播客"},
+		{ID: "lib_3", Name: "儿童"},
+	}
+
+	tests := []struct {
+		name  string
+		user  UserInfo
+		names []string
+		all   bool
+	}{
+		{"全部媒体库", UserInfo{Permissions: Permissions{AccessAllLibraries: true}, LibrariesAccessible: []string{"lib_1"}}, nil, true},
+		{"按媒体库顺序列出", UserInfo{LibrariesAccessible: []string{"lib_3", "lib_1"}}, []string{"有声书", "儿童"}, false},
+		{"忽略未知的媒体库", UserInfo{LibrariesAccessible: []string{"lib_9", "lib_2"}}, []string{"播客"}, false},
+		{"没有可以访问的媒体库", UserInfo{}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, all := LibraryAccess(&tt.user, libraries)
+			if !reflect.DeepEqual(names, tt.names) || all != tt.all {
+				t.Errorf("期望 %v (all=%v)，实际为 %v (all=%v)", tt.names, tt.all, names, all)
+			}
+		})
+	}
+}
+
+func TestTagAccess(t *testing.T) {
+	tests := []struct {
+		name string
+		user UserInfo
+		want string
+	}{
+		{"全部标签", UserInfo{Permissions: Permissions{AccessAllTags: true}, ItemTagsSelected: []string{"科幻"}}, "全部"},
+		{"只允许选中的标签", UserInfo{ItemTagsSelected: []string{"科幻", "历史"}}, "仅 科幻、历史"},
+		{"禁止选中的标签", UserInfo{Permissions: Permissions{SelectedTagsNotAccessible: true}, ItemTagsSelected: []string{"恐怖"}}, "除 恐怖 以外"},
+		{"禁止的标签为空", UserInfo{Permissions: Permissions{SelectedTagsNotAccessible: true}}, "全部"},
+		{"没有选中标签", UserInfo{}, "无"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TagAccess(&tt.user); got != tt.want {
+				t.Errorf("期望 %q，实际为 %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewUserView(t *testing.T) {
+	libraries := []LibraryInfo{{ID: "lib_1", Name: "有声书"}}
+	user := &UserInfo{
+		ID:                  "usr_1",
+		Type:                UserTypeAdmin,
+		Permissions:         Permissions{Download: true},
+		LibrariesAccessible: []string{"lib_1"},
+	}
+
+	tests := []struct {
+		name     string
+		online   map[string]bool
+		presence string
+	}{
+		{"在线", map[string]bool{"usr_1": true}, "🟢 在线"},
+		{"离线", map[string]bool{"usr_2": true}, "⚪ 离线"},
+		{"在线状态未知", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := NewUserView(user, tt.online, libraries)
+			if presence := view.Presence(); presence != tt.presence {
+				t.Errorf("期望在线状态为 %q，实际为 %q", tt.presence, presence)
+			}
+			if !view.IsAdmin || view.Role != "🛡 管理员" {
+				t.Errorf("角色不正确: %+v", view)
+			}
+			if view.AllLibraries || !reflect.DeepEqual(view.Libraries, []string{"有声书"}) {
+				t.Errorf("媒体库访问不正确: %+v", view)
+			}
+			if !reflect.DeepEqual(view.Permissions, []string{"下载"}) || view.Tags != "无" {
+				t.Errorf("权限不正确: %+v", view)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/accounts"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+)
+
+// AccountService 管理 Telegram 用户与 Audiobookshelf 账户的绑定
+// 已绑定的用户以自己的账户访问 Audiobookshelf，未绑定的用户使用 AUDIOBOOKSHELF_TOKEN
+type AccountService struct {
+	client         *api.Client
+	defaultService *ServerService
+	links          *accounts.Store
+
+	mu       sync.Mutex
+	services map[int64]*ServerService
+}
+
+// NewAccountService 创建账户绑定服务，defaultService 用于未绑定的用户
+func NewAccountService(client *api.Client, defaultService *ServerService, links *accounts.Store) *AccountService {
+	return &AccountService{
+		client:         client,
+		defaultService: defaultService,
+		links:          links,
+		services:       make(map[int64]*ServerService),
+	}
+}
+
+// ServiceFor 返回以 Telegram 用户绑定的账户访问 Audiobookshelf 的服务，未绑定时返回默认服务
+// 每个用户的服务会被复用，以便保留各自的媒体库缓存
+func (a *AccountService) ServiceFor(telegramUserID int64) *ServerService {
+	link, ok := a.links.Get(telegramUserID)
+	if !ok {
+		return a.defaultService
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	service, ok := a.services[telegramUserID]
+	if !ok {
+		service = NewServerService(a.client.WithToken(link.Token))
+		a.services[telegramUserID] = service
+	}
+	return service
+}
+
+// GetLink 返回 Telegram 用户的账户绑定
+func (a *AccountService) GetLink(telegramUserID int64) (accounts.Link, bool) {
+	return a.links.Get(telegramUserID)
+}
+
+// LinkWithPassword 使用用户名和密码登录 Audiobookshelf 并绑定得到的账户
+// 密码只用于登录，不会被保存
+func (a *AccountService) LinkWithPassword(ctx context.Context, telegramUserID int64, username, password string) (accounts.Link, error) {
+	user, err := a.client.Login(ctx, username, password)
+	if err != nil {
+		return accounts.Link{}, err
+	}
+
+	return a.save(telegramUserID, user.ID, user.Username, user.Token)
+}
+
+// LinkWithToken 验证 API Token 并绑定其所属的账户
+func (a *AccountService) LinkWithToken(ctx context.Context, telegramUserID int64, token string) (accounts.Link, error) {
+	user, err := a.client.WithToken(token).GetCurrentUser(ctx)
+	if err != nil {
+		return accounts.Link{}, err
+	}
+
+	return a.save(telegramUserID, user.ID, user.Username, token)
+}
+
+// Unlink 解除绑定，返回解除前是否已绑定
+func (a *AccountService) Unlink(telegramUserID int64) (bool, error) {
+	deleted, err := a.links.Delete(telegramUserID)
+	if err != nil {
+		return false, fmt.Errorf("解除账户绑定失败: %w", err)
+	}
+
+	a.forget(telegramUserID)
+	return deleted, nil
+}
+
+// save 保存绑定并丢弃该用户旧的服务实例
+func (a *AccountService) save(telegramUserID int64, userID, username, token string) (accounts.Link, error) {
+	if token == "" {
+		return accounts.Link{}, errors.New("Audiobookshelf 未返回该账户的 API Token")
+	}
+
+	link := accounts.Link{
+		TelegramUserID: telegramUserID,
+		UserID:         userID,
+		Username:       username,
+		Token:          token,
+		LinkedAt:       time.Now(),
+	}
+	if err := a.links.Save(link); err != nil {
+		return accounts.Link{}, fmt.Errorf("保存账户绑定失败: %w", err)
+	}
+
+	a.forget(telegramUserID)
+	return link, nil
+}
+
+// forget 丢弃缓存的用户服务实例，下次使用时按新的绑定重新创建
+func (a *AccountService) forget(telegramUserID int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.services, telegramUserID)
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// 准备下载时的错误
+var (
+	// ErrDownloadNotAllowed 当前账户没有下载权限
+	ErrDownloadNotAllowed = errors.New("您的 Audiobookshelf 账户没有下载权限，请联系管理员开启")
+	// ErrNothingToDownload 条目不是图书或没有音频文件
+	ErrNothingToDownload = errors.New("该条目没有可以下载的音频文件")
+)
+
+// ItemDownload 要下载的图书及其音频文件
+type ItemDownload struct {
+	Item *models.LibraryItem
+	// Files 按播放顺序排列的音频文件，不包括被排除的文件
+	Files []models.AudioFile
+}
+
+// Size 返回所有音频文件的总大小（字节）
+func (d *ItemDownload) Size() int64 {
+	var size int64
+	for _, file := range d.Files {
+		size += file.Metadata.Size
+	}
+	return size
+}
+
+// PrepareDownload 检查当前用户的下载权限并获取图书的音频文件
+func (s *ServerService) PrepareDownload(ctx context.Context, itemID string) (*ItemDownload, error) {
+	user, err := s.client.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取当前用户信息失败: %w", err)
+	}
+	if !user.Permissions.Download {
+		return nil, ErrDownloadNotAllowed
+	}
+
+	item, err := s.client.GetLibraryItem(ctx, itemID, true)
+	if err != nil {
+		return nil, fmt.Errorf("获取条目详情失败: %w", err)
+	}
+
+	files := downloadableFiles(item)
+	if len(files) == 0 {
+		return nil, ErrNothingToDownload
+	}
+	return &ItemDownload{Item: item, Files: files}, nil
+}
+
+// OpenAudioFile 打开图书的音频文件，调用方读取完毕后必须关闭
+func (s *ServerService) OpenAudioFile(ctx context.Context, itemID string, file models.AudioFile) (io.ReadCloser, error) {
+	return s.client.OpenItemFile(ctx, itemID, file.Ino)
+}
+
+// downloadableFiles 返回图书中按播放顺序排列的音频文件，播客返回 nil
+func downloadableFiles(item *models.LibraryItem) []models.AudioFile {
+	if item.Book == nil {
+		return nil
+	}
+
+	var files []models.AudioFile
+	for _, file := range item.Book.AudioFiles {
+		if !file.Exclude {
+			files = append(files, file)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Index < files[j].Index
+	})
+	return files
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+func TestDownloadableFiles(t *testing.T) {
+	item := &models.LibraryItem{
+		MediaType: models.MediaTypeBook,
+		Book: &models.BookMedia{
+			AudioFiles: []models.AudioFile{
+				{Index: 2, Ino: "b", Metadata: models.FileMetadata{Size: 200}},
+				{Index: 1, Ino: "a", Metadata: models.FileMetadata{Size: 100}},
+				{Index: 3, Ino: "c", Exclude: true, Metadata: models.FileMetadata{Size: 300}},
+			},
+		},
+	}
+
+	download := &ItemDownload{Item: item, Files: downloadableFiles(item)}
+	if len(download.Files) != 2 || download.Files[0].Ino != "a" || download.Files[1].Ino != "b" {
+		t.Errorf("音频文件的顺序或过滤不正确: %+v", download.Files)
+	}
+	if size := download.Size(); size != 300 {
+		t.Errorf("期望总大小为 300，实际为 %d", size)
+	}
+
+	podcast := &models.LibraryItem{MediaType: models.MediaTypePodcast, Podcast: &models.PodcastMedia{}}
+	if files := downloadableFiles(podcast); files != nil {
+		t.Errorf("播客不应返回音频文件: %+v", files)
+	}
+}
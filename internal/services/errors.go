@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+)
+
+// DescribeError 将错误转换为可以直接展示给用户的提示信息
+// 不同的错误类别给出不同的处理建议，避免把服务器原始响应直接发到聊天中
+func DescribeError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	switch {
+	case api.IsUnauthorized(err):
+		return "🔑 Audiobookshelf 拒绝了当前 Token，可能已失效或被撤销，请联系管理员更新 Token"
+	case api.IsForbidden(err):
+		return "⛔ 当前账户没有执行此操作的权限"
+	case api.IsNotFound(err):
+		return "🔍 请求的内容不存在或已被删除"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "⏱ Audiobookshelf 服务器响应超时，请稍后重试"
+	case errors.Is(err, context.Canceled):
+		return "🛑 请求已取消"
+	case errors.Is(err, api.ErrCircuitOpen):
+		return "🛡 Audiobookshelf 服务器连续多次请求失败，已暂停访问，请稍后重试"
+	case api.IsServerUnavailable(err):
+		return "🔌 无法连接到 Audiobookshelf 服务器，请检查服务器是否在线"
+	}
+
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Message != "" {
+			return fmt.Sprintf("⚠️ 服务器返回错误 (%d): %s", apiErr.StatusCode, apiErr.Message)
+		}
+		return fmt.Sprintf("⚠️ 服务器返回错误 (%d)", apiErr.StatusCode)
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// ItemDetail 条目详情页所需的信息
+type ItemDetail struct {
+	Item        *models.LibraryItem
+	LibraryName string
+	// ShowProgress 是否查询了当前用户的播放进度，使用共享 Token 浏览时为 false
+	ShowProgress bool
+	// Progress 当前用户的播放进度，从未播放过时为 nil
+	Progress *models.MediaProgress
+	// Cover 封面图片数据，没有封面时为 nil
+	Cover []byte
+}
+
+// GetItemDetail 获取条目详情、当前用户的播放进度及封面
+// withProgress 为 false 时不查询进度；进度和封面获取失败不影响详情展示
+func (s *ServerService) GetItemDetail(ctx context.Context, itemID string, withProgress bool) (*ItemDetail, error) {
+	item, err := s.client.GetLibraryItem(ctx, itemID, true)
+	if err != nil {
+		return nil, fmt.Errorf("获取条目详情失败: %w", err)
+	}
+
+	detail := &ItemDetail{Item: item, ShowProgress: withProgress}
+
+	if name, err := s.GetLibraryName(ctx, item.LibraryID); err == nil {
+		detail.LibraryName = name
+	}
+
+	if withProgress {
+		progress, err := s.client.GetMediaProgress(ctx, itemID)
+		switch {
+		case err == nil:
+			detail.Progress = progress
+		case !api.IsNotFound(err):
+			log.Printf("获取条目 %s 的播放进度失败: %v", itemID, err)
+		}
+	}
+
+	cover, err := s.client.GetItemCover(ctx, itemID)
+	switch {
+	case err == nil:
+		detail.Cover = cover
+	case !api.IsNotFound(err):
+		log.Printf("获取条目 %s 的封面失败: %v", itemID, err)
+	}
+
+	return detail, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// LibraryDetail 媒体库详情
+type LibraryDetail struct {
+	Library *models.LibraryInfo
+	Stats   *models.LibraryStats
+}
+
+// GetLibraryDetail 获取媒体库的信息和统计
+func (s *ServerService) GetLibraryDetail(ctx context.Context, libraryID string) (*LibraryDetail, error) {
+	library, err := s.client.GetLibrary(ctx, libraryID)
+	if err != nil {
+		return nil, fmt.Errorf("获取媒体库信息失败: %w", err)
+	}
+
+	stats, err := s.client.GetLibraryStats(ctx, libraryID)
+	if err != nil {
+		return nil, fmt.Errorf("获取媒体库统计失败: %w", err)
+	}
+
+	return &LibraryDetail{Library: library, Stats: stats}, nil
+}
+
+// ListLibraryItems 列出媒体库中的条目，按 sort 排序，最多返回 maxFilteredItems 个
+func (s *ServerService) ListLibraryItems(ctx context.Context, libraryID, sort string, desc bool) (*FilteredItems, error) {
+	page, err := s.client.ListLibraryItems(ctx, libraryID, api.LibraryItemsOptions{
+		Limit:    maxFilteredItems,
+		Sort:     sort,
+		Desc:     desc,
+		Minified: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取条目列表失败: %w", err)
+	}
+
+	result := &FilteredItems{Items: page.Results, Total: page.Total}
+	for i := range result.Items {
+		if result.Items[i].LibraryID == "" {
+			result.Items[i].LibraryID = libraryID
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/store"
+)
+
+// maxNewItemsPerCheck 每次检查每个媒体库最多获取的新条目数量
+const maxNewItemsPerCheck = 50
+
+// NewItemsBatch 某个媒体库在一次检查中发现的新条目
+type NewItemsBatch struct {
+	Library models.LibraryInfo
+	// Items 新条目，按添加时间从新到旧排列
+	Items []models.LibraryItem
+	// Truncated 新条目超过了一次检查能获取的数量，Items 只包含最新的一部分
+	Truncated bool
+	// ChatIDs 订阅了该媒体库的聊天
+	ChatIDs []int64
+}
+
+// NotificationService 检查订阅的媒体库中新加入的条目
+// 每个媒体库记录已通知过的最新添加时间，之后添加的条目视为新条目
+type NotificationService struct {
+	client        *api.Client
+	subscriptions *store.SubscriptionRepository
+	watermarks    *store.WatermarkRepository
+}
+
+// NewNotificationService 创建新书通知服务，client 使用机器人自己的 token
+func NewNotificationService(client *api.Client, subscriptions *store.SubscriptionRepository, watermarks *store.WatermarkRepository) *NotificationService {
+	return &NotificationService{
+		client:        client,
+		subscriptions: subscriptions,
+		watermarks:    watermarks,
+	}
+}
+
+// Subscriptions 返回聊天订阅的媒体库 ID
+func (n *NotificationService) Subscriptions(chatID int64) ([]string, error) {
+	return n.subscriptions.ForChat(chatID)
+}
+
+// Subscribe 为聊天订阅媒体库的新书通知，返回之前是否未订阅
+// 媒体库第一次被订阅时同时清除旧的检查进度，避免把订阅之前加入的条目当作新条目
+func (n *NotificationService) Subscribe(chatID int64, libraryID string) (bool, error) {
+	return n.subscriptions.Subscribe(chatID, libraryID)
+}
+
+// Unsubscribe 取消聊天对媒体库的订阅，返回之前是否已订阅
+func (n *NotificationService) Unsubscribe(chatID int64, libraryID string) (bool, error) {
+	return n.subscriptions.Unsubscribe(chatID, libraryID)
+}
+
+// CheckNewItems 检查所有被订阅的媒体库，返回自上次检查以来新加入的条目
+// 媒体库第一次检查时只记录当前最新的条目，不返回任何通知
+func (n *NotificationService) CheckNewItems(ctx context.Context) ([]NewItemsBatch, error) {
+	subscribers, err := n.subscribers()
+	if err != nil || len(subscribers) == 0 {
+		return nil, err
+	}
+
+	libraries, err := n.client.GetLibrariesInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取媒体库列表失败: %w", err)
+	}
+
+	var batches []NewItemsBatch
+	for _, library := range libraries {
+		chatIDs := subscribers[library.ID]
+		if len(chatIDs) == 0 {
+			continue
+		}
+
+		items, truncated, err := n.newItems(ctx, library.ID)
+		if err != nil {
+			// 单个媒体库失败不影响其他媒体库，下次检查时重试
+			log.Printf("检查媒体库 %s 的新条目失败: %v", library.Name, err)
+			continue
+		}
+		if len(items) > 0 {
+			batches = append(batches, NewItemsBatch{
+				Library:   library,
+				Items:     items,
+				Truncated: truncated,
+				ChatIDs:   chatIDs,
+			})
+		}
+	}
+
+	return batches, nil
+}
+
+// newItems 获取媒体库中添加时间晚于检查进度的条目，并更新检查进度
+func (n *NotificationService) newItems(ctx context.Context, libraryID string) ([]models.LibraryItem, bool, error) {
+	page, err := n.client.ListLibraryItems(ctx, libraryID, api.LibraryItemsOptions{
+		Limit:    maxNewItemsPerCheck,
+		Sort:     api.SortByAddedAt,
+		Desc:     true,
+		Minified: true,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(page.Results) == 0 {
+		return nil, false, nil
+	}
+
+	watermark, found, err := n.watermarks.Get(libraryID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var items []models.LibraryItem
+	if found {
+		for _, item := range page.Results {
+			if item.AddedAt <= watermark {
+				break
+			}
+			items = append(items, item)
+		}
+	}
+
+	if newest := page.Results[0].AddedAt; !found || newest > watermark {
+		if err := n.watermarks.Set(libraryID, newest); err != nil {
+			return nil, false, err
+		}
+	}
+
+	truncated := len(items) == len(page.Results) && page.Total > len(page.Results)
+	return items, truncated, nil
+}
+
+// subscribers 返回每个媒体库的订阅聊天
+func (n *NotificationService) subscribers() (map[string][]int64, error) {
+	records, err := n.subscriptions.All()
+	if err != nil {
+		return nil, err
+	}
+
+	subscribers := make(map[string][]int64)
+	for _, record := range records {
+		for _, libraryID := range record.LibraryIDs {
+			subscribers[libraryID] = append(subscribers[libraryID], record.ChatID)
+		}
+	}
+	return subscribers, nil
+}
+
+// GetCover 获取条目封面，没有封面时返回 nil
+func (n *NotificationService) GetCover(ctx context.Context, itemID string) []byte {
+	cover, err := n.client.GetItemCover(ctx, itemID)
+	if err != nil {
+		if !api.IsNotFound(err) {
+			log.Printf("获取条目 %s 的封面失败: %v", itemID, err)
+		}
+		return nil
+	}
+	return cover
+}
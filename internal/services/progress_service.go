@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// maxItemsInProgress 正在收听列表中最多列出的条目数量
+const maxItemsInProgress = 20
+
+// ErrPodcastProgress 播客的进度按剧集记录，暂不支持通过机器人修改
+var ErrPodcastProgress = errors.New("播客的播放进度按剧集记录，暂不支持修改")
+
+// GetItemProgress 获取条目的章节信息和当前用户的播放进度
+func (s *ServerService) GetItemProgress(ctx context.Context, itemID string) (*models.ItemProgress, error) {
+	item, err := s.client.GetLibraryItem(ctx, itemID, true)
+	if err != nil {
+		return nil, fmt.Errorf("获取条目详情失败: %w", err)
+	}
+
+	progress, err := s.client.GetMediaProgress(ctx, itemID)
+	if err != nil && !api.IsNotFound(err) {
+		return nil, fmt.Errorf("获取播放进度失败: %w", err)
+	}
+	return &models.ItemProgress{Item: item, Progress: progress}, nil
+}
+
+// ListItemsInProgress 获取当前用户正在收听的图书及进度，按最后收听时间倒序排列
+func (s *ServerService) ListItemsInProgress(ctx context.Context) ([]models.ItemProgress, error) {
+	items, err := s.client.GetItemsInProgress(ctx, maxItemsInProgress)
+	if err != nil {
+		return nil, fmt.Errorf("获取正在收听的条目失败: %w", err)
+	}
+	user, err := s.client.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取当前用户信息失败: %w", err)
+	}
+
+	progress := make(map[string]*models.MediaProgress, len(user.MediaProgress))
+	for i := range user.MediaProgress {
+		p := &user.MediaProgress[i]
+		if p.EpisodeID == "" {
+			progress[p.LibraryItemID] = p
+		}
+	}
+
+	var result []models.ItemProgress
+	for i := range items {
+		if items[i].MediaType != models.MediaTypeBook {
+			continue
+		}
+		result = append(result, models.ItemProgress{Item: &items[i], Progress: progress[items[i].ID]})
+	}
+	return result, nil
+}
+
+// SeekProgress 将播放位置设置到 seconds 秒，超出范围的位置会被限制在条目时长以内
+// 修改位置后条目会被标记为未听完
+func (s *ServerService) SeekProgress(ctx context.Context, p *models.ItemProgress, seconds float64) error {
+	if p.Item.MediaType != models.MediaTypeBook {
+		return ErrPodcastProgress
+	}
+
+	duration := p.Duration()
+	if seconds < 0 {
+		seconds = 0
+	}
+	if duration > 0 && seconds > duration {
+		seconds = duration
+	}
+
+	update := api.MediaProgressUpdate{CurrentTime: &seconds, IsFinished: new(bool)}
+	if duration > 0 {
+		ratio := seconds / duration
+		update.Duration = &duration
+		update.Progress = &ratio
+	}
+	return s.client.UpdateMediaProgress(ctx, p.Item.ID, update)
+}
+
+// SetProgressFinished 将条目标记为已听完或未听完
+func (s *ServerService) SetProgressFinished(ctx context.Context, p *models.ItemProgress, finished bool) error {
+	if p.Item.MediaType != models.MediaTypeBook {
+		return ErrPodcastProgress
+	}
+	return s.client.UpdateMediaProgress(ctx, p.Item.ID, api.MediaProgressUpdate{IsFinished: &finished})
+}
+
+// ResetProgress 删除条目的播放进度，条目回到未开始的状态；没有进度时不做任何操作
+func (s *ServerService) ResetProgress(ctx context.Context, p *models.ItemProgress) error {
+	if p.Progress == nil {
+		return nil
+	}
+	return s.client.RemoveMediaProgress(ctx, p.Progress.ID)
+}
+
+// ParseTimestamp 解析用户输入的播放位置，返回秒数
+// 支持 "1:02:03"、"62:03"、"3723" 这样的时间戳，以及 "1h2m3s" 这样的时长
+func ParseTimestamp(text string) (float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, errors.New("请输入播放位置")
+	}
+
+	if d, err := time.ParseDuration(text); err == nil {
+		if d < 0 {
+			return 0, fmt.Errorf("无效的播放位置: %s", text)
+		}
+		return d.Seconds(), nil
+	}
+
+	parts := strings.Split(text, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("无效的播放位置: %s", text)
+	}
+	var seconds float64
+	for i, part := range parts {
+		// 只接受数字和小数点，ParseFloat 还会接受 NaN、Inf 和 1e9 这样的写法
+		if !isDecimal(part) {
+			return 0, fmt.Errorf("无效的播放位置: %s", text)
+		}
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, fmt.Errorf("无效的播放位置: %s", text)
+		}
+		// 除第一段外，分钟和秒不能超过 59
+		if i > 0 && value >= 60 {
+			return 0, fmt.Errorf("无效的播放位置: %s", text)
+		}
+		seconds = seconds*60 + value
+	}
+	return seconds, nil
+}
+
+// isDecimal 判断 text 是否为不带符号和指数的十进制数，例如 "12" 或 "3.5"
+func isDecimal(text string) bool {
+	digits, dot := 0, false
+	for _, r := range text {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '.' && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{"3723", 3723, false},
+		{"62:03", 3723, false},
+		{"1:02:03", 3723, false},
+		{" 0:30 ", 30, false},
+		{"1h2m3s", 3723, false},
+		{"90m", 5400, false},
+		{"", 0, true},
+		{"1:60", 0, true},
+		{"1:2:3:4", 0, true},
+		{"abc", 0, true},
+		{"-5", 0, true},
+		{"-1h", 0, true},
+		{"90.5", 90.5, false},
+		{"NaN", 0, true},
+		{"Inf", 0, true},
+		{"1:Inf", 0, true},
+		{"1e9", 0, true},
+		{"0x10", 0, true},
+		{".", 0, true},
+		{"1:", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("期望错误为 %v，实际为 %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("期望 %v 秒，实际为 %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// ScanEvents 跟踪扫描进度需要订阅的实时事件
+var ScanEvents = []string{
+	api.EventScanStart,
+	api.EventScanComplete,
+	api.EventTaskFinished,
+	api.EventItemAdded,
+	api.EventItemsAdded,
+	api.EventItemUpdated,
+	api.EventItemsUpdated,
+	api.EventDisconnected,
+}
+
+// ScanLibrary 开始扫描媒体库
+func (s *ServerService) ScanLibrary(ctx context.Context, libraryID string, force bool) error {
+	return s.client.ScanLibrary(ctx, libraryID, force)
+}
+
+// ScanItem 重新扫描单个条目，返回扫描结果
+func (s *ServerService) ScanItem(ctx context.Context, itemID string) (string, error) {
+	return s.client.ScanItem(ctx, itemID)
+}
+
+// ScanProgress 根据实时事件统计的媒体库扫描进度
+type ScanProgress struct {
+	LibraryID   string
+	LibraryName string
+	// Started 是否已收到扫描开始的事件
+	Started bool
+	// Added、Updated 扫描过程中收到的新增和更新事件数量
+	Added   int
+	Updated int
+	// Missing 扫描过程中被标记为缺失的条目数量，缺失的条目通过更新事件报告
+	Missing int
+	// Finished 扫描是否已结束
+	Finished bool
+	// Results 服务器在扫描结束时给出的统计，可能为 nil
+	Results *models.ScanResults
+	// Summary 扫描任务结束时的描述
+	Summary string
+	// Error 扫描失败时的错误信息
+	Error string
+}
+
+// NewScanProgress 创建媒体库的扫描进度
+func NewScanProgress(libraryID, libraryName string) *ScanProgress {
+	return &ScanProgress{LibraryID: libraryID, LibraryName: libraryName}
+}
+
+// Apply 根据事件更新进度，返回进度是否有变化；与该媒体库无关的事件会被忽略
+// 较早版本的 Audiobookshelf 通过 scan_complete 报告结果，较新的版本通过 task_finished
+func (p *ScanProgress) Apply(event api.Event) bool {
+	switch event.Name {
+	case api.EventScanStart, api.EventScanComplete:
+		var scan models.LibraryScan
+		if err := event.Decode(&scan); err != nil || scan.ID != p.LibraryID {
+			return false
+		}
+		if event.Name == api.EventScanStart {
+			p.Started = true
+			return true
+		}
+		p.Finished = true
+		p.Results = &scan.Results
+		return true
+	case api.EventTaskFinished:
+		var task models.Task
+		if err := event.Decode(&task); err != nil || task.Action != "library-scan" {
+			return false
+		}
+		if libraryID, _ := task.Data["libraryId"].(string); libraryID != p.LibraryID {
+			return false
+		}
+		p.Finished = true
+		p.Summary = task.Description
+		if task.IsFailed {
+			p.Error = task.Error
+		}
+		return true
+	case api.EventItemAdded, api.EventItemUpdated:
+		var item models.LibraryItem
+		if err := event.Decode(&item); err != nil || item.LibraryID != p.LibraryID {
+			return false
+		}
+		p.count(event.Name == api.EventItemAdded, item)
+		return true
+	case api.EventItemsAdded, api.EventItemsUpdated:
+		var items []models.LibraryItem
+		if err := event.Decode(&items); err != nil {
+			log.Printf("解析 %s 事件失败: %v", event.Name, err)
+			return false
+		}
+		matched := false
+		for _, item := range items {
+			if item.LibraryID == p.LibraryID {
+				p.count(event.Name == api.EventItemsAdded, item)
+				matched = true
+			}
+		}
+		return matched
+	}
+	return false
+}
+
+// count 累计新增、更新或缺失的条目数量
+func (p *ScanProgress) count(added bool, item models.LibraryItem) {
+	p.Started = true
+	switch {
+	case added:
+		p.Added++
+	case item.IsMissing:
+		p.Missing++
+	default:
+		p.Updated++
+	}
+}
+
+// InvalidateLibrariesCache 清除媒体库统计的缓存，扫描结束后条目数量可能已经变化
+func (s *ServerService) InvalidateLibrariesCache() {
+	s.librariesCacheMutex.Lock()
+	defer s.librariesCacheMutex.Unlock()
+
+	s.librariesCache = nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+)
+
+func TestScanProgressApply(t *testing.T) {
+	progress := NewScanProgress("lib_1", "有声书")
+	events := []api.Event{
+		{Name: api.EventScanStart, Data: json.RawMessage(`{"id":"lib_1"}`)},
+		{Name: api.EventItemsAdded, Data: json.RawMessage(`[{"id":"li_1","libraryId":"lib_1"},{"id":"li_2","libraryId":"lib_2"}]`)},
+		{Name: api.EventItemUpdated, Data: json.RawMessage(`{"id":"li_3","libraryId":"lib_1"}`)},
+		{Name: api.EventItemsUpdated, Data: json.RawMessage(`[{"id":"li_4","libraryId":"lib_1","isMissing":true},{"id":"li_5","libraryId":"lib_1","isMissing":true}]`)},
+		{Name: api.EventTaskFinished, Data: json.RawMessage(`{"action":"library-scan","data":{"libraryId":"lib_1"},"description":"扫描完成"}`)},
+	}
+	for _, event := range events {
+		if !progress.Apply(event) {
+			t.Errorf("事件 %s 应更新进度", event.Name)
+		}
+	}
+
+	if progress.Added != 1 || progress.Updated != 1 || progress.Missing != 2 {
+		t.Errorf("期望新增 1、更新 1、缺失 2，实际为 %d、%d、%d", progress.Added, progress.Updated, progress.Missing)
+	}
+	if !progress.Finished || progress.Results != nil || progress.Summary != "扫描完成" {
+		t.Errorf("task_finished 后的进度不正确: %+v", progress)
+	}
+
+	other := api.Event{Name: api.EventItemUpdated, Data: json.RawMessage(`{"id":"li_6","libraryId":"lib_2","isMissing":true}`)}
+	if progress.Apply(other) || progress.Missing != 2 {
+		t.Error("其他媒体库的事件应被忽略")
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// maxFilteredItems 按分类列出条目时每个媒体库最多返回的数量
+const maxFilteredItems = 100
+
+// Search 在所有媒体库中搜索图书、播客、作者、系列、标签和朗读者
+func (s *ServerService) Search(ctx context.Context, term string) (*models.SearchResults, error) {
+	if term == "" {
+		return nil, fmt.Errorf("搜索词不能为空")
+	}
+
+	results, err := s.client.Search(ctx, term, "")
+	if err != nil {
+		return nil, fmt.Errorf("搜索失败: %w", err)
+	}
+
+	return results, nil
+}
+
+// FilteredItems 按分类列出的条目
+type FilteredItems struct {
+	Items []models.LibraryItem
+	// Total 服务器报告的条目总数，可能多于 Items 的数量
+	Total int
+}
+
+// ListItemsByFilter 在指定的媒体库中列出符合过滤条件的条目，按标题排序
+func (s *ServerService) ListItemsByFilter(ctx context.Context, libraryIDs []string, filter api.ItemFilter) (*FilteredItems, error) {
+	result := &FilteredItems{}
+	for _, libraryID := range libraryIDs {
+		page, err := s.client.ListLibraryItems(ctx, libraryID, api.LibraryItemsOptions{
+			Limit:    maxFilteredItems,
+			Sort:     api.SortByTitle,
+			Filter:   &filter,
+			Minified: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取条目列表失败: %w", err)
+		}
+
+		for _, item := range page.Results {
+			if item.LibraryID == "" {
+				item.LibraryID = libraryID
+			}
+			result.Items = append(result.Items, item)
+		}
+		result.Total += page.Total
+	}
+
+	return result, nil
+}
+
+// ListAuthorItems 列出作者的全部图书
+func (s *ServerService) ListAuthorItems(ctx context.Context, author models.Author) (*FilteredItems, error) {
+	return s.ListItemsByFilter(ctx, []string{author.LibraryID}, api.ItemFilter{Group: api.FilterAuthors, Value: author.ID})
+}
+
+// ListSeriesItems 列出系列中的全部图书
+func (s *ServerService) ListSeriesItems(ctx context.Context, series models.Series) (*FilteredItems, error) {
+	return s.ListItemsByFilter(ctx, []string{series.LibraryID}, api.ItemFilter{Group: api.FilterSeries, Value: series.ID})
+}
+
+// ListTagItems 列出带有该标签的全部条目
+func (s *ServerService) ListTagItems(ctx context.Context, tag models.TagResult) (*FilteredItems, error) {
+	return s.ListItemsByFilter(ctx, tag.LibraryIDs, api.ItemFilter{Group: api.FilterTags, Value: tag.Name})
+}
+
+// ListNarratorItems 列出朗读者的全部图书
+func (s *ServerService) ListNarratorItems(ctx context.Context, narrator models.NarratorResult) (*FilteredItems, error) {
+	return s.ListItemsByFilter(ctx, narrator.LibraryIDs, api.ItemFilter{Group: api.FilterNarrators, Value: narrator.Name})
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
 	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -29,8 +30,8 @@ func NewServerService(client *api.Client) *ServerService {
 }
 
 // GetFormattedServerInfo 获取格式化的服务器信息
-func (s *ServerService) GetFormattedServerInfo() (string, error) {
-	status, err := s.client.GetServerStatus()
+func (s *ServerService) GetFormattedServerInfo(ctx context.Context) (string, error) {
+	status, err := s.client.GetServerStatus(ctx)
 	if err != nil {
 		return "", fmt.Errorf("获取服务器状态失败: %w", err)
 	}
@@ -43,13 +44,16 @@ func (s *ServerService) GetFormattedServerInfo() (string, error) {
 	// 注意：ServerStatus 模型中没有 App 字段，使用 ServerVersion 替代
 	sb.WriteString(fmt.Sprintf("🖥 *版本*: `%s`\n", status.ServerVersion))
 	sb.WriteString(fmt.Sprintf("🔤 *语言*: `%s`\n", status.Language))
+	if connection := s.ConnectionStatus(); connection != "" {
+		sb.WriteString(fmt.Sprintf("🛡 *连接状态*: %s\n", connection))
+	}
 
 	sb.WriteString("\n📚 *媒体库信息*\n")
 
 	// 获取媒体库信息
-	libraries, err := s.GetLibrariesWithStats()
+	libraries, err := s.GetLibrariesWithStats(ctx)
 	if err != nil {
-		sb.WriteString("⚠️ 获取媒体库信息失败\n")
+		sb.WriteString(fmt.Sprintf("⚠️ 获取媒体库信息失败: %s\n", DescribeError(err)))
 	} else {
 		if len(libraries) == 0 {
 			sb.WriteString("📭 暂无媒体库\n")
@@ -64,6 +68,30 @@ func (s *ServerService) GetFormattedServerInfo() (string, error) {
 	return sb.String(), nil
 }
 
+// ConnectionStatus 返回 API 客户端熔断器的状态描述，未启用熔断器时返回空字符串
+func (s *ServerService) ConnectionStatus() string {
+	status := s.client.BreakerStatus()
+	if !status.Enabled {
+		return ""
+	}
+
+	switch status.State {
+	case api.BreakerOpen:
+		wait := time.Until(status.RetryAt)
+		if wait < time.Second {
+			wait = time.Second
+		}
+		return fmt.Sprintf("🔴 已熔断（连续失败 %d 次），%s 后重试", status.ConsecutiveFailures, FormatDuration(wait))
+	case api.BreakerHalfOpen:
+		return "🟡 恢复中，正在试探服务器"
+	}
+
+	if status.ConsecutiveFailures > 0 {
+		return fmt.Sprintf("🟢 正常（最近连续失败 %d 次）", status.ConsecutiveFailures)
+	}
+	return "🟢 正常"
+}
+
 // FormatDuration 格式化持续时间
 func FormatDuration(d time.Duration) string {
 	days := int(d.Hours()) / 24
@@ -116,7 +144,7 @@ type LibraryWithStats struct {
 }
 
 // GetLibrariesWithStats 获取带有统计信息的媒体库列表，带缓存功能
-func (s *ServerService) GetLibrariesWithStats() ([]LibraryWithStats, error) {
+func (s *ServerService) GetLibrariesWithStats(ctx context.Context) ([]LibraryWithStats, error) {
 	// 检查缓存
 	s.librariesCacheMutex.RLock()
 	if time.Since(s.librariesCacheTime) < s.cacheExpiry && s.librariesCache != nil {
@@ -127,7 +155,7 @@ func (s *ServerService) GetLibrariesWithStats() ([]LibraryWithStats, error) {
 	s.librariesCacheMutex.RUnlock()
 
 	// 缓存失效，获取新数据
-	libraries, err := s.client.GetLibrariesInfo()
+	libraries, err := s.client.GetLibrariesInfo(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -148,12 +176,16 @@ func (s *ServerService) GetLibrariesWithStats() ([]LibraryWithStats, error) {
 		go func(index int, lib models.LibraryInfo) {
 			defer wg.Done()
 
-			// 控制并发数
-			semaphore <- struct{}{}
+			// 控制并发数，等待期间如果请求被取消则直接放弃
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
 			defer func() { <-semaphore }()
 
 			// 获取库中媒体项的数量
-			count, err := s.client.GetLibraryItemsCount(lib.ID)
+			count, err := s.client.GetLibraryItemsCount(ctx, lib.ID)
 			if err != nil {
 				// 如果获取失败，设置为0
 				mu.Lock()
@@ -172,6 +204,11 @@ func (s *ServerService) GetLibrariesWithStats() ([]LibraryWithStats, error) {
 	// 等待所有goroutine完成
 	wg.Wait()
 
+	// 请求被取消时统计数据不完整，不写入缓存
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// 更新缓存
 	s.librariesCacheMutex.Lock()
 	s.librariesCache = librariesWithStats
@@ -182,9 +219,9 @@ func (s *ServerService) GetLibrariesWithStats() ([]LibraryWithStats, error) {
 }
 
 // GetLibraryName 根据libraryId获取媒体库名称
-func (s *ServerService) GetLibraryName(libraryId string) (string, error) {
+func (s *ServerService) GetLibraryName(ctx context.Context, libraryId string) (string, error) {
 	// 使用轻量级方法获取媒体库名称，避免获取统计信息
-	libraries, err := s.getLibrariesBasicInfo()
+	libraries, err := s.getLibrariesBasicInfo(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -200,16 +237,21 @@ func (s *ServerService) GetLibraryName(libraryId string) (string, error) {
 	return "", fmt.Errorf("未找到ID为%s的媒体库", libraryId)
 }
 
+// ListLibraries 获取当前账户可以访问的媒体库，不包含统计信息
+func (s *ServerService) ListLibraries(ctx context.Context) ([]models.LibraryInfo, error) {
+	return s.getLibrariesBasicInfo(ctx)
+}
+
 // getLibrariesBasicInfo 获取媒体库基本信息（ID和名称），不包含统计信息
-func (s *ServerService) getLibrariesBasicInfo() ([]models.LibraryInfo, error) {
+func (s *ServerService) getLibrariesBasicInfo(ctx context.Context) ([]models.LibraryInfo, error) {
 	// 直接调用API获取媒体库信息，不计算统计信息
-	return s.client.GetLibrariesInfo()
+	return s.client.GetLibrariesInfo(ctx)
 }
 
 // GetUsersWithProgress 获取用户列表及播放统计信息
-func (s *ServerService) GetUsersWithProgress() ([]models.UserInfo, error) {
+func (s *ServerService) GetUsersWithProgress(ctx context.Context) ([]models.UserInfo, error) {
 	// 获取用户列表
-	users, err := s.client.GetUsers()
+	users, err := s.client.GetUsers(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("获取用户列表失败: %w", err)
 	}
@@ -227,12 +269,16 @@ func (s *ServerService) GetUsersWithProgress() ([]models.UserInfo, error) {
 		go func(index int, user models.UserInfo) {
 			defer wg.Done()
 
-			// 控制并发数
-			semaphore <- struct{}{}
+			// 控制并发数，等待期间如果请求被取消则直接放弃
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
 			defer func() { <-semaphore }()
 
 			// 获取用户的播放进度信息
-			progress, err := s.client.GetUserMediaProgress(user.ID)
+			progress, err := s.client.GetUserMediaProgress(ctx, user.ID)
 			if err != nil {
 				// 如果获取失败，记录错误但不中断其他用户的信息获取
 				log.Printf("获取用户 %s 的播放进度信息失败: %v", user.Username, err)
@@ -249,48 +295,81 @@ func (s *ServerService) GetUsersWithProgress() ([]models.UserInfo, error) {
 	// 等待所有goroutine完成
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return users, nil
 }
 
-// SearchBooks 搜索图书，使用并行处理提高性能
-func (s *ServerService) SearchBooks(term string, libraryID string) ([]models.Book, error) {
-	if term == "" {
-		return nil, fmt.Errorf("搜索词不能为空")
+// GetCurrentUserWithProgress 获取当前用户信息及播放统计
+// /api/me 的响应中已经包含当前用户的播放进度，无需额外请求
+func (s *ServerService) GetCurrentUserWithProgress(ctx context.Context) (*models.UserInfo, error) {
+	user, err := s.client.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取当前用户信息失败: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetListeningStats 获取当前用户的收听统计信息
+func (s *ServerService) GetListeningStats(ctx context.Context) (*models.ListeningStats, error) {
+	stats, err := s.client.GetListeningStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取收听统计信息失败: %w", err)
 	}
+	return stats, nil
+}
+
+// UserStats 指定用户的收听统计
+type UserStats struct {
+	User           *models.UserInfo
+	Stats          *models.ListeningStats
+	RecentSessions []models.PlaybackSession
+}
 
-	books, err := s.client.SearchBooks(term, libraryID)
+// FindUserByName 根据用户名查找用户（不区分大小写）
+func (s *ServerService) FindUserByName(ctx context.Context, username string) (*models.UserInfo, error) {
+	users, err := s.client.GetUsers(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("搜索失败: %w", err)
+		return nil, fmt.Errorf("获取用户列表失败: %w", err)
+	}
+
+	for i := range users {
+		if strings.EqualFold(users[i].Username, username) {
+			return &users[i], nil
+		}
 	}
 
-	return books, nil
+	return nil, fmt.Errorf("未找到用户名为 %s 的用户", username)
 }
 
-// GetCurrentUserWithProgress 获取当前用户信息及播放统计
-func (s *ServerService) GetCurrentUserWithProgress() (*models.UserInfo, error) {
-	// 获取当前用户信息
-	user, err := s.client.GetCurrentUser()
+// GetUserStats 获取指定用户的收听统计及最近的收听会话
+func (s *ServerService) GetUserStats(ctx context.Context, username string) (*UserStats, error) {
+	user, err := s.FindUserByName(ctx, username)
 	if err != nil {
-		return nil, fmt.Errorf("获取当前用户信息失败: %w", err)
+		return nil, err
 	}
 
-	// 获取用户的播放进度信息
-	progress, err := s.client.GetUserMediaProgress(user.ID)
+	stats, err := s.client.GetUserListeningStats(ctx, user.ID)
 	if err != nil {
-		log.Printf("获取用户 %s 的播放进度信息失败: %v", user.Username, err)
-		// 即使获取播放进度失败，也返回用户基本信息
-		return user, nil
+		return nil, fmt.Errorf("获取用户 %s 的收听统计失败: %w", user.Username, err)
 	}
 
-	user.MediaProgress = progress
-	return user, nil
-}
+	result := &UserStats{
+		User:           user,
+		Stats:          stats,
+		RecentSessions: stats.RecentSessions,
+	}
 
-// GetListeningStats 获取当前用户的收听统计信息
-func (s *ServerService) GetListeningStats() (map[string]interface{}, error) {
-	stats, err := s.client.GetListeningStats()
+	// 会话接口按时间倒序返回，比统计中附带的最近会话更完整
+	sessions, err := s.client.GetUserListeningSessions(ctx, user.ID, 0, 5)
 	if err != nil {
-		return nil, fmt.Errorf("获取收听统计信息失败: %w", err)
+		log.Printf("获取用户 %s 的收听会话失败: %v", user.Username, err)
+	} else {
+		result.RecentSessions = sessions.Sessions
 	}
-	return stats, nil
+
+	return result, nil
 }
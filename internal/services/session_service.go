@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+const (
+	// staleSessionAge 进行中的会话超过该时间没有同步进度时视为遗留会话
+	// 客户端播放时每隔十几秒同步一次，长时间没有同步通常是客户端异常退出
+	staleSessionAge = 10 * time.Minute
+	// recentSessionsLimit 正在播放页面中最多列出的最近结束的会话数量
+	recentSessionsLimit = 5
+)
+
+// GetNowPlaying 获取进行中的播放会话和最近结束的会话，需要管理员权限
+// 最近的会话获取失败时只记录日志，Recent 留空
+func (s *ServerService) GetNowPlaying(ctx context.Context) (*models.NowPlayingReport, error) {
+	online, err := s.client.GetOnlineUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取播放会话失败: %w", err)
+	}
+
+	usernames := make(map[string]string, len(online.UsersOnline))
+	for _, user := range online.UsersOnline {
+		usernames[user.ID] = user.Username
+	}
+
+	var recent []models.PlaybackSession
+	if page, err := s.client.GetSessions(ctx, 0, recentSessionsLimit+len(online.OpenSessions)); err != nil {
+		log.Printf("获取最近的收听会话失败: %v", err)
+	} else {
+		recent = page.Sessions
+	}
+	for _, session := range recent {
+		if session.User != nil && usernames[session.User.ID] == "" {
+			usernames[session.User.ID] = session.User.Username
+		}
+	}
+
+	now := time.Now()
+	report := &models.NowPlayingReport{}
+	active := make(map[string]bool, len(online.OpenSessions))
+	for _, session := range online.OpenSessions {
+		active[session.ID] = true
+		report.Active = append(report.Active, newNowPlaying(session, usernames, now, true))
+	}
+	sort.SliceStable(report.Active, func(i, j int) bool {
+		return report.Active[i].Session.UpdatedAt > report.Active[j].Session.UpdatedAt
+	})
+
+	for _, session := range recent {
+		if active[session.ID] || len(report.Recent) >= recentSessionsLimit {
+			continue
+		}
+		report.Recent = append(report.Recent, newNowPlaying(session, usernames, now, false))
+	}
+
+	return report, nil
+}
+
+// newNowPlaying 创建会话的展示信息，open 表示会话仍在进行
+func newNowPlaying(session models.PlaybackSession, usernames map[string]string, now time.Time, open bool) models.NowPlaying {
+	playing := models.NowPlaying{
+		Session:  session,
+		Username: usernames[session.UserID],
+		Chapter:  session.CurrentChapter(),
+	}
+	if playing.Username == "" {
+		playing.Username = session.UserID
+	}
+	if open && session.UpdatedAt > 0 {
+		playing.Stale = now.Sub(time.UnixMilli(session.UpdatedAt)) > staleSessionAge
+	}
+	return playing
+}
+
+// CloseSession 关闭并删除播放会话，需要管理员权限
+func (s *ServerService) CloseSession(ctx context.Context, sessionID string) error {
+	return s.client.DeleteSession(ctx, sessionID)
+}
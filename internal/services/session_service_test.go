@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+func TestNewNowPlaying(t *testing.T) {
+	now := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	chapters := []models.Chapter{
+		{ID: 0, Start: 0, End: 600, Title: "第一章"},
+		{ID: 1, Start: 600, End: 1200, Title: "第二章"},
+	}
+	usernames := map[string]string{"usr_1": "alice"}
+
+	tests := []struct {
+		name     string
+		session  models.PlaybackSession
+		open     bool
+		username string
+		chapter  string
+		stale    bool
+	}{
+		{
+			name:     "进行中的会话",
+			session:  models.PlaybackSession{UserID: "usr_1", Chapters: chapters, CurrentTime: 650, UpdatedAt: now.Add(-time.Minute).UnixMilli()},
+			open:     true,
+			username: "alice",
+			chapter:  "第二章",
+		},
+		{
+			name:     "长时间没有同步",
+			session:  models.PlaybackSession{UserID: "usr_1", Chapters: chapters, CurrentTime: 10, UpdatedAt: now.Add(-time.Hour).UnixMilli()},
+			open:     true,
+			username: "alice",
+			chapter:  "第一章",
+			stale:    true,
+		},
+		{
+			name:     "已结束的会话不算遗留",
+			session:  models.PlaybackSession{UserID: "usr_1", UpdatedAt: now.Add(-time.Hour).UnixMilli()},
+			username: "alice",
+		},
+		{
+			name:     "未知用户使用 ID",
+			session:  models.PlaybackSession{UserID: "usr_2", Chapters: chapters, CurrentTime: 1300},
+			open:     true,
+			username: "usr_2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playing := newNowPlaying(tt.session, usernames, now, tt.open)
+			if playing.Username != tt.username {
+				t.Errorf("期望用户名为 %q，实际为 %q", tt.username, playing.Username)
+			}
+			chapter := ""
+			if playing.Chapter != nil {
+				chapter = playing.Chapter.Title
+			}
+			if chapter != tt.chapter {
+				t.Errorf("期望章节为 %q，实际为 %q", tt.chapter, chapter)
+			}
+			if playing.Stale != tt.stale {
+				t.Errorf("期望 Stale 为 %v，实际为 %v", tt.stale, playing.Stale)
+			}
+		})
+	}
+}
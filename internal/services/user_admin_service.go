@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// IsAssignableUserType 判断是否可以在 Telegram 中把用户设置为该类型，root 用户只有一个且不能被指定
+func IsAssignableUserType(userType string) bool {
+	switch userType {
+	case models.UserTypeAdmin, models.UserTypeUser, models.UserTypeGuest:
+		return true
+	}
+	return false
+}
+
+// GetUser 获取用户信息
+func (s *ServerService) GetUser(ctx context.Context, userID string) (*models.UserInfo, error) {
+	user, err := s.client.GetUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户信息失败: %w", err)
+	}
+	return user, nil
+}
+
+// CreateUser 创建启用状态的普通用户，权限使用服务器的默认值
+func (s *ServerService) CreateUser(ctx context.Context, username, password string) (*models.UserInfo, error) {
+	user, err := s.client.CreateUser(ctx, api.NewUser{
+		Username: username,
+		Password: password,
+		Type:     models.UserTypeUser,
+		IsActive: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建用户失败: %w", err)
+	}
+	return user, nil
+}
+
+// SetUserActive 启用或停用用户，停用的用户无法登录
+func (s *ServerService) SetUserActive(ctx context.Context, userID string, active bool) (*models.UserInfo, error) {
+	return s.updateUser(ctx, userID, api.UserUpdate{IsActive: &active})
+}
+
+// ResetUserPassword 重置用户的密码
+func (s *ServerService) ResetUserPassword(ctx context.Context, userID, password string) error {
+	if password == "" {
+		return fmt.Errorf("密码不能为空")
+	}
+	_, err := s.updateUser(ctx, userID, api.UserUpdate{Password: &password})
+	return err
+}
+
+// SetUserType 修改用户类型
+func (s *ServerService) SetUserType(ctx context.Context, userID, userType string) (*models.UserInfo, error) {
+	if !IsAssignableUserType(userType) {
+		return nil, fmt.Errorf("无效的用户类型: %s", userType)
+	}
+	return s.updateUser(ctx, userID, api.UserUpdate{Type: &userType})
+}
+
+// ToggleUserPermission 切换用户的一项权限，返回修改后的用户
+// Audiobookshelf 按提交的字段逐项修改权限，因此提交的是在当前权限基础上切换后的完整权限
+func (s *ServerService) ToggleUserPermission(ctx context.Context, userID, key string) (*models.UserInfo, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := user.Permissions
+	if !permissions.Toggle(key) {
+		return nil, fmt.Errorf("未知的权限: %s", key)
+	}
+	return s.updateUser(ctx, userID, api.UserUpdate{Permissions: &permissions})
+}
+
+// ToggleUserLibrary 允许或禁止用户访问某个媒体库，只在用户没有访问全部媒体库的权限时生效
+func (s *ServerService) ToggleUserLibrary(ctx context.Context, userID, libraryID string) (*models.UserInfo, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	libraries := toggleString(user.LibrariesAccessible, libraryID)
+	return s.updateUser(ctx, userID, api.UserUpdate{LibrariesAccessible: &libraries})
+}
+
+// ToggleUserTag 选择或取消选择用户的某个标签，只在用户没有访问全部标签的权限时生效
+func (s *ServerService) ToggleUserTag(ctx context.Context, userID, tag string) (*models.UserInfo, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := toggleString(user.ItemTagsSelected, tag)
+	return s.updateUser(ctx, userID, api.UserUpdate{ItemTagsSelected: &tags})
+}
+
+// DeleteUser 删除用户
+func (s *ServerService) DeleteUser(ctx context.Context, userID string) error {
+	if err := s.client.DeleteUser(ctx, userID); err != nil {
+		return fmt.Errorf("删除用户失败: %w", err)
+	}
+	return nil
+}
+
+// ListTags 获取所有媒体库中使用的标签
+func (s *ServerService) ListTags(ctx context.Context) ([]string, error) {
+	tags, err := s.client.GetTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签列表失败: %w", err)
+	}
+	return tags, nil
+}
+
+// updateUser 修改用户
+func (s *ServerService) updateUser(ctx context.Context, userID string, update api.UserUpdate) (*models.UserInfo, error) {
+	user, err := s.client.UpdateUser(ctx, userID, update)
+	if err != nil {
+		return nil, fmt.Errorf("修改用户失败: %w", err)
+	}
+	return user, nil
+}
+
+// toggleString 在列表中添加或移除 value，返回新的列表
+func toggleString(list []string, value string) []string {
+	result := make([]string, 0, len(list)+1)
+	found := false
+	for _, item := range list {
+		if item == value {
+			found = true
+			continue
+		}
+		result = append(result, item)
+	}
+	if !found {
+		result = append(result, value)
+	}
+	return result
+}
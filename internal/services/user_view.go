@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+// GetOnlineUserIDs 返回在线用户的 ID 集合，需要管理员权限
+func (s *ServerService) GetOnlineUserIDs(ctx context.Context) (map[string]bool, error) {
+	online, err := s.client.GetOnlineUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取在线用户失败: %w", err)
+	}
+
+	ids := make(map[string]bool, len(online.UsersOnline))
+	for _, user := range online.UsersOnline {
+		ids[user.ID] = true
+	}
+	return ids, nil
+}
+
+// GetUserViews 获取全部用户的展示信息，包括播放进度、在线状态和可以访问的媒体库
+// 在线状态或媒体库获取失败时只记录日志，相应的信息留空
+func (s *ServerService) GetUserViews(ctx context.Context) ([]*models.UserView, error) {
+	users, err := s.GetUsersWithProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	online := s.onlineUserIDs(ctx, nil)
+	libraries, err := s.ListLibraries(ctx)
+	if err != nil {
+		log.Printf("获取媒体库列表失败: %v", err)
+	}
+
+	views := make([]*models.UserView, len(users))
+	for i := range users {
+		views[i] = models.NewUserView(&users[i], online, libraries)
+	}
+	return views, nil
+}
+
+// GetUserView 获取单个用户的展示信息
+func (s *ServerService) GetUserView(ctx context.Context, user *models.UserInfo) *models.UserView {
+	online := s.onlineUserIDs(ctx, nil)
+	libraries, err := s.ListLibraries(ctx)
+	if err != nil {
+		log.Printf("获取媒体库列表失败: %v", err)
+	}
+	return models.NewUserView(user, online, libraries)
+}
+
+// GetCurrentUserView 获取当前用户的展示信息
+func (s *ServerService) GetCurrentUserView(ctx context.Context) (*models.UserView, error) {
+	user, err := s.GetCurrentUserWithProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	libraries, err := s.ListLibraries(ctx)
+	if err != nil {
+		log.Printf("获取媒体库列表失败: %v", err)
+	}
+
+	return models.NewUserView(user, s.onlineUserIDs(ctx, user), libraries), nil
+}
+
+// onlineUserIDs 返回在线用户的 ID 集合，获取失败时只记录日志并返回 nil，表示在线状态未知
+// 查询在线用户需要管理员权限，current 为当前 Token 对应的用户，为 nil 时先查询当前用户；
+// 当前用户不是管理员时不发送请求，避免服务器返回 403
+func (s *ServerService) onlineUserIDs(ctx context.Context, current *models.UserInfo) map[string]bool {
+	if current == nil {
+		var err error
+		if current, err = s.GetCurrentUserWithProgress(ctx); err != nil {
+			log.Printf("获取当前用户信息失败: %v", err)
+			return nil
+		}
+	}
+	if !models.IsAdminUser(current) {
+		return nil
+	}
+
+	online, err := s.GetOnlineUserIDs(ctx)
+	if err != nil {
+		log.Printf("获取在线用户失败: %v", err)
+	}
+	return online
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/api"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/config"
+	"github.com/Heathcliff-third-space/AudiobookshelfManager/internal/models"
+)
+
+func TestGetUserViewOnlineRequiresAdmin(t *testing.T) {
+	tests := []struct {
+		name        string
+		userType    string
+		onlineKnown bool
+	}{
+		{"管理员查询在线状态", models.UserTypeAdmin, true},
+		{"普通用户不查询在线状态", models.UserTypeUser, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var onlineRequests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/me":
+					w.Write([]byte(`{"id":"usr_me","username":"me","type":"` + tt.userType + `"}`))
+				case "/api/libraries":
+					w.Write([]byte(`{"libraries":[]}`))
+				case "/api/users/online":
+					atomic.AddInt32(&onlineRequests, 1)
+					w.Write([]byte(`{"usersOnline":[{"id":"usr_1"}]}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			service := NewServerService(api.NewClient(&config.Config{AudiobookshelfURL: server.URL}))
+			view := service.GetUserView(context.Background(), &models.UserInfo{ID: "usr_1", Type: models.UserTypeUser})
+
+			if view.OnlineKnown != tt.onlineKnown || view.Online != tt.onlineKnown {
+				t.Errorf("在线状态不正确: known=%v online=%v", view.OnlineKnown, view.Online)
+			}
+			if requested := atomic.LoadInt32(&onlineRequests) > 0; requested != tt.onlineKnown {
+				t.Errorf("是否请求在线用户期望为 %v，实际为 %v", tt.onlineKnown, requested)
+			}
+		})
+	}
+}
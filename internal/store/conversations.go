@@ -0,0 +1,83 @@
+package store
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// ConversationRecord 保存的对话状态
+type ConversationRecord struct {
+	State     int       `json:"state"`
+	Data      string    `json:"data,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ConversationRepository 按聊天 ID 保存多步操作的对话状态，使机器人重启后可以继续进行中的操作
+type ConversationRepository struct {
+	db *DB
+}
+
+// NewConversationRepository 创建对话状态仓库
+func NewConversationRepository(db *DB) *ConversationRepository {
+	return &ConversationRepository{db: db}
+}
+
+// All 返回全部对话状态，键为聊天 ID
+func (r *ConversationRepository) All() (map[int64]ConversationRecord, error) {
+	records := make(map[int64]ConversationRecord)
+	err := r.db.View(func(tx *Tx) error {
+		return tx.ForEach(bucketConversations, func(key string, raw json.RawMessage) error {
+			chatID, err := strconv.ParseInt(key, 10, 64)
+			if err != nil {
+				return err
+			}
+			var record ConversationRecord
+			if err := json.Unmarshal(raw, &record); err != nil {
+				return err
+			}
+			records[chatID] = record
+			return nil
+		})
+	})
+	return records, err
+}
+
+// Save 保存聊天的对话状态
+func (r *ConversationRepository) Save(chatID int64, record ConversationRecord) error {
+	return r.db.Update(func(tx *Tx) error {
+		return tx.Put(bucketConversations, int64Key(chatID), record)
+	})
+}
+
+// Delete 删除聊天的对话状态
+func (r *ConversationRepository) Delete(chatID int64) error {
+	return r.db.Update(func(tx *Tx) error {
+		_, err := tx.Delete(bucketConversations, int64Key(chatID))
+		return err
+	})
+}
+
+// DeleteExpired 删除在 now 之前已超时的对话状态
+func (r *ConversationRepository) DeleteExpired(now time.Time) error {
+	return r.db.Update(func(tx *Tx) error {
+		var expired []string
+		err := tx.ForEach(bucketConversations, func(key string, raw json.RawMessage) error {
+			var record ConversationRecord
+			if err := json.Unmarshal(raw, &record); err != nil || !now.Before(record.ExpiresAt) {
+				expired = append(expired, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if _, err := tx.Delete(bucketConversations, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
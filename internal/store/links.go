@@ -0,0 +1,77 @@
+package store
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// LinkRecord 保存的账户绑定，Token 由调用方加密后存入
+type LinkRecord struct {
+	TelegramUserID int64     `json:"telegramUserId"`
+	UserID         string    `json:"userId"`
+	Username       string    `json:"username"`
+	EncryptedToken string    `json:"encryptedToken"`
+	LinkedAt       time.Time `json:"linkedAt"`
+}
+
+// LinkRepository 按 Telegram 用户 ID 保存账户绑定
+type LinkRepository struct {
+	db *DB
+}
+
+// NewLinkRepository 创建账户绑定仓库
+func NewLinkRepository(db *DB) *LinkRepository {
+	return &LinkRepository{db: db}
+}
+
+// Get 返回 Telegram 用户的绑定
+func (r *LinkRepository) Get(telegramUserID int64) (LinkRecord, bool, error) {
+	var record LinkRecord
+	var found bool
+	err := r.db.View(func(tx *Tx) error {
+		var err error
+		found, err = tx.Get(bucketLinks, int64Key(telegramUserID), &record)
+		return err
+	})
+	return record, found, err
+}
+
+// All 返回全部绑定
+func (r *LinkRepository) All() ([]LinkRecord, error) {
+	var records []LinkRecord
+	err := r.db.View(func(tx *Tx) error {
+		return tx.ForEach(bucketLinks, func(key string, raw json.RawMessage) error {
+			var record LinkRecord
+			if err := json.Unmarshal(raw, &record); err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	return records, err
+}
+
+// Save 保存或替换绑定
+func (r *LinkRepository) Save(record LinkRecord) error {
+	return r.db.Update(func(tx *Tx) error {
+		return tx.Put(bucketLinks, int64Key(record.TelegramUserID), record)
+	})
+}
+
+// Delete 删除绑定，返回删除前是否存在
+func (r *LinkRepository) Delete(telegramUserID int64) (bool, error) {
+	var deleted bool
+	err := r.db.Update(func(tx *Tx) error {
+		var err error
+		deleted, err = tx.Delete(bucketLinks, int64Key(telegramUserID))
+		return err
+	})
+	return deleted, err
+}
+
+// int64Key 将 Telegram 用户或聊天 ID 转换为键
+func int64Key(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
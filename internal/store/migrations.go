@@ -0,0 +1,75 @@
+package store
+
+import (
+	"fmt"
+	"log"
+)
+
+// 各个仓库使用的桶
+const (
+	bucketLinks         = "links"
+	bucketConversations = "conversations"
+	bucketSubscriptions = "subscriptions"
+	bucketWatermarks    = "watermarks"
+)
+
+// migration 将数据从上一个版本升级到 version
+type migration struct {
+	version     int
+	description string
+	apply       func(tx *Tx) error
+}
+
+// migrations 按版本顺序排列的全部迁移，新的迁移只能追加到末尾
+var migrations = []migration{
+	{
+		version:     1,
+		description: "创建账户绑定和对话状态的桶",
+		apply: func(tx *Tx) error {
+			if err := tx.CreateBucket(bucketLinks); err != nil {
+				return err
+			}
+			return tx.CreateBucket(bucketConversations)
+		},
+	},
+	{
+		version:     2,
+		description: "创建新书通知订阅和媒体库检查进度的桶",
+		apply: func(tx *Tx) error {
+			if err := tx.CreateBucket(bucketSubscriptions); err != nil {
+				return err
+			}
+			return tx.CreateBucket(bucketWatermarks)
+		},
+	},
+}
+
+// SchemaVersion 当前程序支持的数据结构版本
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// migrate 依次执行尚未执行的迁移，每个迁移在独立的事务中提交
+func (db *DB) migrate() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.doc.Version > SchemaVersion() {
+		return fmt.Errorf("store: data version %d is newer than supported version %d", db.doc.Version, SchemaVersion())
+	}
+
+	for _, m := range migrations {
+		if m.version <= db.doc.Version {
+			continue
+		}
+
+		log.Printf("执行数据迁移 %d: %s", m.version, m.description)
+		err := db.update(m.version, func(tx *Tx) error {
+			return m.apply(tx)
+		})
+		if err != nil {
+			return fmt.Errorf("error applying migration %d: %w", m.version, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+// Package store 提供机器人的本地持久化存储
+// 数据按桶（bucket）组织为键值对，整体以 JSON 文件保存在数据目录中，
+// 每次写事务提交时原子地替换文件，不依赖 CGO
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// fileName 数据目录中的存储文件名
+const fileName = "state.json"
+
+// ErrBucketNotFound 访问了不存在的桶，桶需要在迁移中创建
+var ErrBucketNotFound = errors.New("store: bucket not found")
+
+// ErrReadOnly 在只读事务中执行了写操作
+var ErrReadOnly = errors.New("store: read-only transaction")
+
+// document 存储文件的内容
+type document struct {
+	// Version 数据结构版本，用于判断需要执行哪些迁移
+	Version int                                   `json:"version"`
+	Buckets map[string]map[string]json.RawMessage `json:"buckets"`
+}
+
+// DB 基于单个 JSON 文件的键值存储，所有方法都可以并发调用
+type DB struct {
+	dir  string
+	path string
+
+	mu  sync.RWMutex
+	doc document
+}
+
+// Open 打开 dir 中的存储，目录或文件不存在时自动创建，并执行尚未执行的迁移
+func Open(dir string) (*DB, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating data directory: %w", err)
+	}
+
+	db := &DB{
+		dir:  dir,
+		path: filepath.Join(dir, fileName),
+		doc:  document{Buckets: make(map[string]map[string]json.RawMessage)},
+	}
+
+	data, err := os.ReadFile(db.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("error reading store: %w", err)
+	default:
+		if err := json.Unmarshal(data, &db.doc); err != nil {
+			return nil, fmt.Errorf("error unmarshaling store: %w", err)
+		}
+		if db.doc.Buckets == nil {
+			db.doc.Buckets = make(map[string]map[string]json.RawMessage)
+		}
+	}
+
+	if err := db.migrate(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Dir 返回数据目录
+func (db *DB) Dir() string {
+	return db.dir
+}
+
+// Version 返回当前的数据结构版本
+func (db *DB) Version() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.doc.Version
+}
+
+// View 执行只读事务
+func (db *DB) View(fn func(tx *Tx) error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return fn(&Tx{buckets: db.doc.Buckets})
+}
+
+// Update 执行写事务：fn 返回 nil 且写入文件成功后修改才会生效，否则全部丢弃
+func (db *DB) Update(fn func(tx *Tx) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.update(db.doc.Version, fn)
+}
+
+// update 在副本上执行写事务并以 version 作为新版本写入文件，调用方需持有写锁
+func (db *DB) update(version int, fn func(tx *Tx) error) error {
+	tx := &Tx{buckets: cloneBuckets(db.doc.Buckets), writable: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if !tx.dirty && version == db.doc.Version {
+		return nil
+	}
+
+	doc := document{Version: version, Buckets: tx.buckets}
+	if err := db.write(doc); err != nil {
+		return err
+	}
+	db.doc = doc
+	return nil
+}
+
+// write 先写入临时文件再重命名，避免写到一半时退出导致文件损坏
+func (db *DB) write(doc document) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling store: %w", err)
+	}
+
+	tmp := db.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("error writing store: %w", err)
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing store: %w", err)
+	}
+
+	if err := os.Rename(tmp, db.path); err != nil {
+		return fmt.Errorf("error writing store: %w", err)
+	}
+	return nil
+}
+
+// cloneBuckets 复制桶的映射，值本身不会被修改，因此无需深拷贝
+func cloneBuckets(buckets map[string]map[string]json.RawMessage) map[string]map[string]json.RawMessage {
+	clone := make(map[string]map[string]json.RawMessage, len(buckets))
+	for name, bucket := range buckets {
+		copied := make(map[string]json.RawMessage, len(bucket))
+		for key, value := range bucket {
+			copied[key] = value
+		}
+		clone[name] = copied
+	}
+	return clone
+}
+
+// Tx 一次读或写事务
+type Tx struct {
+	buckets  map[string]map[string]json.RawMessage
+	writable bool
+	dirty    bool
+}
+
+// CreateBucket 创建桶，桶已存在时不做任何操作
+func (tx *Tx) CreateBucket(name string) error {
+	if !tx.writable {
+		return ErrReadOnly
+	}
+	if _, ok := tx.buckets[name]; !ok {
+		tx.buckets[name] = make(map[string]json.RawMessage)
+		tx.dirty = true
+	}
+	return nil
+}
+
+// Get 读取 key 对应的值并解析到 v 中，返回值是否存在
+func (tx *Tx) Get(bucket, key string, v interface{}) (bool, error) {
+	b, ok := tx.buckets[bucket]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrBucketNotFound, bucket)
+	}
+
+	raw, ok := b[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("error unmarshaling %s/%s: %w", bucket, key, err)
+	}
+	return true, nil
+}
+
+// Put 保存 v 的 JSON 形式
+func (tx *Tx) Put(bucket, key string, v interface{}) error {
+	if !tx.writable {
+		return ErrReadOnly
+	}
+	b, ok := tx.buckets[bucket]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrBucketNotFound, bucket)
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshaling %s/%s: %w", bucket, key, err)
+	}
+	b[key] = raw
+	tx.dirty = true
+	return nil
+}
+
+// Delete 删除 key，返回删除前是否存在
+func (tx *Tx) Delete(bucket, key string) (bool, error) {
+	if !tx.writable {
+		return false, ErrReadOnly
+	}
+	b, ok := tx.buckets[bucket]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrBucketNotFound, bucket)
+	}
+
+	if _, ok := b[key]; !ok {
+		return false, nil
+	}
+	delete(b, key)
+	tx.dirty = true
+	return true, nil
+}
+
+// ForEach 按 key 的顺序遍历桶中的值，fn 返回错误时停止遍历
+func (tx *Tx) ForEach(bucket string, fn func(key string, raw json.RawMessage) error) error {
+	b, ok := tx.buckets[bucket]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrBucketNotFound, bucket)
+	}
+
+	keys := make([]string, 0, len(b))
+	for key := range b {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := fn(key, b[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenRunsMigrations(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(dir)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	if db.Version() != SchemaVersion() {
+		t.Errorf("期望版本为 %d，实际为 %d", SchemaVersion(), db.Version())
+	}
+
+	// 新版本程序写入的数据不能被旧版本打开
+	data, _ := json.Marshal(document{Version: SchemaVersion() + 1})
+	os.WriteFile(filepath.Join(dir, fileName), data, 0o600)
+	if _, err := Open(dir); err == nil {
+		t.Error("数据版本高于程序支持的版本时应返回错误")
+	}
+}
+
+func TestUpdatePersistsAndRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	db, _ := Open(dir)
+	links := NewLinkRepository(db)
+
+	if err := links.Save(LinkRecord{TelegramUserID: 1, Username: "alice"}); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+
+	// 事务返回错误时修改不生效
+	failure := errors.New("failure")
+	err := db.Update(func(tx *Tx) error {
+		tx.Put(bucketLinks, "1", LinkRecord{TelegramUserID: 1, Username: "bob"})
+		return failure
+	})
+	if err != failure {
+		t.Errorf("期望返回事务的错误，实际为 %v", err)
+	}
+
+	reopened, _ := Open(dir)
+	record, ok, _ := NewLinkRepository(reopened).Get(1)
+	if !ok || record.Username != "alice" {
+		t.Errorf("重新打开后的数据不正确: %+v", record)
+	}
+
+	if err := db.View(func(tx *Tx) error { return tx.Put(bucketLinks, "2", "x") }); err != ErrReadOnly {
+		t.Errorf("只读事务中写入期望返回 ErrReadOnly，实际为 %v", err)
+	}
+	if err := db.Update(func(tx *Tx) error { return tx.Put("missing", "1", "x") }); !errors.Is(err, ErrBucketNotFound) {
+		t.Errorf("写入不存在的桶期望返回 ErrBucketNotFound，实际为 %v", err)
+	}
+}
+
+func TestConversationRepository(t *testing.T) {
+	db, _ := Open(t.TempDir())
+	conversations := NewConversationRepository(db)
+	now := time.Now()
+
+	conversations.Save(1, ConversationRecord{State: 1, ExpiresAt: now.Add(time.Minute)})
+	conversations.Save(2, ConversationRecord{State: 2, Data: "alice", ExpiresAt: now.Add(-time.Minute)})
+
+	if err := conversations.DeleteExpired(now); err != nil {
+		t.Fatalf("清理超时对话失败: %v", err)
+	}
+	records, err := conversations.All()
+	if err != nil {
+		t.Fatalf("读取对话失败: %v", err)
+	}
+	if len(records) != 1 || records[1].State != 1 {
+		t.Errorf("期望只保留未超时的对话，实际为 %+v", records)
+	}
+
+	conversations.Delete(1)
+	if records, _ := conversations.All(); len(records) != 0 {
+		t.Errorf("删除后不应有对话，实际为 %+v", records)
+	}
+}
+
+func TestSubscriptionRepository(t *testing.T) {
+	db, _ := Open(t.TempDir())
+	subscriptions := NewSubscriptionRepository(db)
+
+	if added, err := subscriptions.Subscribe(1, "lib_1"); err != nil || !added {
+		t.Fatalf("订阅失败: %v, %v", added, err)
+	}
+	if added, _ := subscriptions.Subscribe(1, "lib_1"); added {
+		t.Error("重复订阅应返回 false")
+	}
+	subscriptions.Subscribe(1, "lib_2")
+	subscriptions.Subscribe(2, "lib_1")
+
+	if ids, _ := subscriptions.ForChat(1); len(ids) != 2 {
+		t.Errorf("期望聊天 1 订阅了 2 个媒体库，实际为 %v", ids)
+	}
+
+	if removed, _ := subscriptions.Unsubscribe(1, "lib_1"); !removed {
+		t.Error("取消订阅应返回 true")
+	}
+	if removed, _ := subscriptions.Unsubscribe(3, "lib_1"); removed {
+		t.Error("取消不存在的订阅应返回 false")
+	}
+	subscriptions.Unsubscribe(1, "lib_2")
+
+	records, err := subscriptions.All()
+	if err != nil || len(records) != 1 || records[0].ChatID != 2 {
+		t.Errorf("取消全部订阅的聊天应被删除: %+v, %v", records, err)
+	}
+}
+
+func TestSubscribeResetsWatermark(t *testing.T) {
+	db, _ := Open(t.TempDir())
+	subscriptions := NewSubscriptionRepository(db)
+	watermarks := NewWatermarkRepository(db)
+
+	watermarks.Set("lib_1", 100)
+	subscriptions.Subscribe(1, "lib_1")
+	if _, found, _ := watermarks.Get("lib_1"); found {
+		t.Error("媒体库第一次被订阅时应清除旧的检查进度")
+	}
+
+	watermarks.Set("lib_1", 200)
+	subscriptions.Subscribe(2, "lib_1")
+	if addedAt, found, _ := watermarks.Get("lib_1"); !found || addedAt != 200 {
+		t.Errorf("已有订阅的媒体库应保留检查进度，实际为 %d, %v", addedAt, found)
+	}
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"encoding/json"
+)
+
+// SubscriptionRecord 一个聊天订阅新书通知的媒体库
+type SubscriptionRecord struct {
+	ChatID     int64    `json:"chatId"`
+	LibraryIDs []string `json:"libraryIds"`
+}
+
+// SubscriptionRepository 按聊天保存新书通知的订阅
+type SubscriptionRepository struct {
+	db *DB
+}
+
+// NewSubscriptionRepository 创建订阅仓库
+func NewSubscriptionRepository(db *DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db}
+}
+
+// ForChat 返回聊天订阅的媒体库 ID
+func (r *SubscriptionRepository) ForChat(chatID int64) ([]string, error) {
+	var record SubscriptionRecord
+	err := r.db.View(func(tx *Tx) error {
+		_, err := tx.Get(bucketSubscriptions, int64Key(chatID), &record)
+		return err
+	})
+	return record.LibraryIDs, err
+}
+
+// All 返回全部订阅
+func (r *SubscriptionRepository) All() ([]SubscriptionRecord, error) {
+	var records []SubscriptionRecord
+	err := r.db.View(func(tx *Tx) error {
+		return tx.ForEach(bucketSubscriptions, func(key string, raw json.RawMessage) error {
+			var record SubscriptionRecord
+			if err := json.Unmarshal(raw, &record); err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	return records, err
+}
+
+// Subscribe 为聊天订阅媒体库，返回之前是否未订阅
+// 媒体库第一次被订阅时在同一事务中删除它的检查进度，避免把订阅之前加入的条目当作新条目
+func (r *SubscriptionRepository) Subscribe(chatID int64, libraryID string) (bool, error) {
+	var added bool
+	err := r.db.Update(func(tx *Tx) error {
+		record := SubscriptionRecord{ChatID: chatID}
+		if _, err := tx.Get(bucketSubscriptions, int64Key(chatID), &record); err != nil {
+			return err
+		}
+		for _, id := range record.LibraryIDs {
+			if id == libraryID {
+				return nil
+			}
+		}
+
+		subscribed, err := hasSubscribers(tx, libraryID)
+		if err != nil {
+			return err
+		}
+		if !subscribed {
+			if _, err := tx.Delete(bucketWatermarks, libraryID); err != nil {
+				return err
+			}
+		}
+
+		added = true
+		record.LibraryIDs = append(record.LibraryIDs, libraryID)
+		return tx.Put(bucketSubscriptions, int64Key(chatID), record)
+	})
+	return added, err
+}
+
+// hasSubscribers 返回是否有聊天订阅了媒体库
+func hasSubscribers(tx *Tx, libraryID string) (bool, error) {
+	found := false
+	err := tx.ForEach(bucketSubscriptions, func(key string, raw json.RawMessage) error {
+		var record SubscriptionRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return err
+		}
+		for _, id := range record.LibraryIDs {
+			if id == libraryID {
+				found = true
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// Unsubscribe 取消聊天对媒体库的订阅，返回之前是否已订阅
+func (r *SubscriptionRepository) Unsubscribe(chatID int64, libraryID string) (bool, error) {
+	var removed bool
+	err := r.db.Update(func(tx *Tx) error {
+		var record SubscriptionRecord
+		found, err := tx.Get(bucketSubscriptions, int64Key(chatID), &record)
+		if err != nil || !found {
+			return err
+		}
+
+		remaining := make([]string, 0, len(record.LibraryIDs))
+		for _, id := range record.LibraryIDs {
+			if id == libraryID {
+				removed = true
+				continue
+			}
+			remaining = append(remaining, id)
+		}
+		if !removed {
+			return nil
+		}
+
+		if len(remaining) == 0 {
+			_, err = tx.Delete(bucketSubscriptions, int64Key(chatID))
+			return err
+		}
+		record.LibraryIDs = remaining
+		return tx.Put(bucketSubscriptions, int64Key(chatID), record)
+	})
+	return removed, err
+}
+
+// WatermarkRepository 按媒体库保存已通知过的最新条目的添加时间
+type WatermarkRepository struct {
+	db *DB
+}
+
+// NewWatermarkRepository 创建媒体库检查进度仓库
+func NewWatermarkRepository(db *DB) *WatermarkRepository {
+	return &WatermarkRepository{db: db}
+}
+
+// Get 返回媒体库已通知过的最新添加时间（毫秒时间戳）
+func (r *WatermarkRepository) Get(libraryID string) (int64, bool, error) {
+	var addedAt int64
+	var found bool
+	err := r.db.View(func(tx *Tx) error {
+		var err error
+		found, err = tx.Get(bucketWatermarks, libraryID, &addedAt)
+		return err
+	})
+	return addedAt, found, err
+}
+
+// Set 保存媒体库已通知过的最新添加时间
+func (r *WatermarkRepository) Set(libraryID string, addedAt int64) error {
+	return r.db.Update(func(tx *Tx) error {
+		return tx.Put(bucketWatermarks, libraryID, addedAt)
+	})
+}